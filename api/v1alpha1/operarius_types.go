@@ -50,6 +50,28 @@ type DeduplicationConfig struct {
 	TTL int32 `json:"ttl,omitempty"`
 }
 
+// CronTrigger runs an Operarius on a schedule
+type CronTrigger struct {
+	// Schedule in Cron format, see https://en.wikipedia.org/wiki/Cron
+	// +kubebuilder:validation:MinLength=1
+	Schedule string `json:"schedule"`
+
+	// TimeZone is the IANA name of the time zone the schedule is interpreted in, UTC by default
+	// +optional
+	TimeZone *string `json:"timeZone,omitempty"`
+
+	// StartingDeadlineSeconds is the deadline in seconds for starting a run that
+	// was missed, e.g. while OpenFero was down. Missed runs later than this are
+	// skipped. Without a deadline the latest missed run is started.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	StartingDeadlineSeconds *int64 `json:"startingDeadlineSeconds,omitempty"`
+
+	// Labels are added to the labels of the synthesized alert
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
 // OperariusSpec defines the desired state of Operarius
 type OperariusSpec struct {
 	// AlertSelector defines which alerts trigger this Operarius
@@ -72,6 +94,32 @@ type OperariusSpec struct {
 	// Deduplication defines deduplication settings for this Operarius
 	// +optional
 	Deduplication *DeduplicationConfig `json:"deduplication,omitempty"`
+
+	// CronTrigger additionally runs this Operarius on a schedule
+	// +optional
+	CronTrigger *CronTrigger `json:"cronTrigger,omitempty"`
+}
+
+// ExecutionFailure describes why a remediation Job's pod failed
+type ExecutionFailure struct {
+	// PodName is the name of the pod the failure was observed on
+	// +optional
+	PodName string `json:"podName,omitempty"`
+
+	// ContainerName is the name of the failing container, if the failure is container-specific
+	// +optional
+	ContainerName string `json:"containerName,omitempty"`
+
+	// Reason is a brief CamelCase reason for the failure (e.g. ImagePullBackOff, OOMKilled, Error)
+	Reason string `json:"reason"`
+
+	// ExitCode is the exit code of the terminated container, if it terminated
+	// +optional
+	ExitCode *int32 `json:"exitCode,omitempty"`
+
+	// Message is the container termination message, or the kubelet's message if the container never ran
+	// +optional
+	Message string `json:"message,omitempty"`
 }
 
 // OperariusStatus defines the observed state of Operarius
@@ -91,6 +139,14 @@ type OperariusStatus struct {
 	// LastExecutionStatus represents the status of the last execution
 	// +optional
 	LastExecutionStatus string `json:"lastExecutionStatus,omitempty"`
+
+	// LastFailure describes why the pod of the last executed job failed, if it did
+	// +optional
+	LastFailure *ExecutionFailure `json:"lastFailure,omitempty"`
+
+	// LastScheduleTime is the last time the cron trigger was due
+	// +optional
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -46,6 +46,38 @@ func (in *AlertSelector) DeepCopy() *AlertSelector {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CronTrigger) DeepCopyInto(out *CronTrigger) {
+	*out = *in
+	if in.TimeZone != nil {
+		in, out := &in.TimeZone, &out.TimeZone
+		*out = new(string)
+		**out = **in
+	}
+	if in.StartingDeadlineSeconds != nil {
+		in, out := &in.StartingDeadlineSeconds, &out.StartingDeadlineSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CronTrigger.
+func (in *CronTrigger) DeepCopy() *CronTrigger {
+	if in == nil {
+		return nil
+	}
+	out := new(CronTrigger)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DeduplicationConfig) DeepCopyInto(out *DeduplicationConfig) {
 	*out = *in
@@ -61,6 +93,26 @@ func (in *DeduplicationConfig) DeepCopy() *DeduplicationConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExecutionFailure) DeepCopyInto(out *ExecutionFailure) {
+	*out = *in
+	if in.ExitCode != nil {
+		in, out := &in.ExitCode, &out.ExitCode
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExecutionFailure.
+func (in *ExecutionFailure) DeepCopy() *ExecutionFailure {
+	if in == nil {
+		return nil
+	}
+	out := new(ExecutionFailure)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Operarius) DeepCopyInto(out *Operarius) {
 	*out = *in
@@ -135,6 +187,11 @@ func (in *OperariusSpec) DeepCopyInto(out *OperariusSpec) {
 		*out = new(DeduplicationConfig)
 		**out = **in
 	}
+	if in.CronTrigger != nil {
+		in, out := &in.CronTrigger, &out.CronTrigger
+		*out = new(CronTrigger)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperariusSpec.
@@ -154,6 +211,15 @@ func (in *OperariusStatus) DeepCopyInto(out *OperariusStatus) {
 		in, out := &in.LastExecutionTime, &out.LastExecutionTime
 		*out = (*in).DeepCopy()
 	}
+	if in.LastFailure != nil {
+		in, out := &in.LastFailure, &out.LastFailure
+		*out = new(ExecutionFailure)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LastScheduleTime != nil {
+		in, out := &in.LastScheduleTime, &out.LastScheduleTime
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperariusStatus.
@@ -1,11 +1,27 @@
 package main
 
 import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
 	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
 
 	"github.com/OpenFero/openfero/pkg/alertstore/memory"
 	"github.com/OpenFero/openfero/pkg/handlers"
@@ -211,6 +227,17 @@ func TestAuthMiddleware_InvalidMethod(t *testing.T) {
 }
 
 func TestValidateAuthConfig(t *testing.T) {
+	basicCredentials, err := handlers.LoadCredentials(writeFile(t, t.TempDir(), "credentials.yaml",
+		"credentials:\n  - {label: alertmanager, username: user, password: pass}\n"))
+	if err != nil {
+		t.Fatalf("Failed to load credentials: %v", err)
+	}
+	hmacCredentials, err := handlers.LoadCredentials(writeFile(t, t.TempDir(), "credentials.yaml",
+		"credentials:\n  - {label: grafana, hmacSecret: secret}\n"))
+	if err != nil {
+		t.Fatalf("Failed to load credentials: %v", err)
+	}
+
 	tests := []struct {
 		name      string
 		config    handlers.AuthConfig
@@ -263,6 +290,57 @@ func TestValidateAuthConfig(t *testing.T) {
 			},
 			expectErr: true,
 		},
+		{
+			name: "hmac auth valid",
+			config: handlers.AuthConfig{
+				Method:        handlers.AuthMethodHMAC,
+				HMACSecret:    "secret",
+				HMACTolerance: time.Minute,
+			},
+			expectErr: false,
+		},
+		{
+			name: "hmac auth missing secret",
+			config: handlers.AuthConfig{
+				Method:        handlers.AuthMethodHMAC,
+				HMACTolerance: time.Minute,
+			},
+			expectErr: true,
+		},
+		{
+			name: "basic auth from credentials file",
+			config: handlers.AuthConfig{
+				Method:      handlers.AuthMethodBasic,
+				Credentials: basicCredentials,
+			},
+			expectErr: false,
+		},
+		{
+			name: "bearer auth without tokens in credentials file",
+			config: handlers.AuthConfig{
+				Method:      handlers.AuthMethodBearer,
+				Credentials: basicCredentials,
+			},
+			expectErr: true,
+		},
+		{
+			name: "hmac auth from credentials file",
+			config: handlers.AuthConfig{
+				Method:        handlers.AuthMethodHMAC,
+				HMACTolerance: time.Minute,
+				Credentials:   hmacCredentials,
+			},
+			expectErr: false,
+		},
+		{
+			name: "hmac auth without secrets in credentials file",
+			config: handlers.AuthConfig{
+				Method:        handlers.AuthMethodHMAC,
+				HMACTolerance: time.Minute,
+				Credentials:   basicCredentials,
+			},
+			expectErr: true,
+		},
 		{
 			name: "invalid method",
 			config: handlers.AuthConfig{
@@ -363,3 +441,558 @@ func TestIntegration_AlertsPostHandler_WithAuth(t *testing.T) {
 		t.Errorf("Expected status %d with valid auth, got %d", http.StatusOK, rec.Code)
 	}
 }
+
+func TestAuthMiddleware_Principal(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    handlers.AuthConfig
+		setup     func(r *http.Request)
+		principal string
+	}{
+		{
+			name:      "no authentication",
+			config:    handlers.AuthConfig{Method: handlers.AuthMethodNone},
+			setup:     func(r *http.Request) {},
+			principal: handlers.AnonymousPrincipal,
+		},
+		{
+			name:      "basic auth names the user",
+			config:    handlers.AuthConfig{Method: handlers.AuthMethodBasic, BasicUser: "testuser", BasicPass: "testpass"},
+			setup:     func(r *http.Request) { r.SetBasicAuth("testuser", "testpass") },
+			principal: "basic:testuser",
+		},
+		{
+			name:      "bearer token does not leak the token",
+			config:    handlers.AuthConfig{Method: handlers.AuthMethodBearer, BearerToken: "secret-token"},
+			setup:     func(r *http.Request) { r.Header.Set("Authorization", "Bearer secret-token") },
+			principal: "bearer",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var principal string
+			handler := handlers.AuthMiddleware(tt.config)(func(w http.ResponseWriter, r *http.Request) {
+				principal = handlers.PrincipalFromContext(r.Context())
+			})
+
+			req := httptest.NewRequest("POST", "/api/jobs/openfero/job/cancel", nil)
+			tt.setup(req)
+			handler(httptest.NewRecorder(), req)
+
+			if principal != tt.principal {
+				t.Errorf("Expected principal %q, got %q", tt.principal, principal)
+			}
+		})
+	}
+}
+
+// signedRequest builds a request signed for HMAC authentication
+func signedRequest(secret, body string, timestamp time.Time, nonce string) *http.Request {
+	ts := strconv.FormatInt(timestamp.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts + "." + nonce + "." + body))
+
+	req := httptest.NewRequest("POST", "/alerts", strings.NewReader(body))
+	req.Header.Set("X-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	req.Header.Set(handlers.DefaultHMACTimestampHeader, ts)
+	req.Header.Set(handlers.DefaultHMACNonceHeader, nonce)
+	return req
+}
+
+func TestAuthMiddleware_HMAC(t *testing.T) {
+	authConfig := handlers.AuthConfig{
+		Method:              handlers.AuthMethodHMAC,
+		HMACSecret:          "shared-secret",
+		HMACSignatureHeader: "X-Signature",
+		HMACTolerance:       time.Minute,
+	}
+
+	var received string
+	handler := handlers.AuthMiddleware(authConfig)(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = string(body)
+		w.WriteHeader(http.StatusOK)
+	})
+	serve := func(req *http.Request) int {
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		return rec.Code
+	}
+
+	body := `{"status":"firing"}`
+	if code := serve(signedRequest("shared-secret", body, time.Now(), "nonce-1")); code != http.StatusOK {
+		t.Fatalf("Expected status %d for a signed request, got %d", http.StatusOK, code)
+	}
+	if received != body {
+		t.Errorf("Expected the handler to read the body %q, got %q", body, received)
+	}
+
+	tampered := signedRequest("shared-secret", body, time.Now(), "nonce-5")
+	tampered.Body = io.NopCloser(strings.NewReader(`{"status":"resolved"}`))
+	renonced := signedRequest("shared-secret", body, time.Now(), "nonce-6")
+	renonced.Header.Set(handlers.DefaultHMACNonceHeader, "nonce-7")
+
+	tests := []struct {
+		name string
+		req  *http.Request
+	}{
+		{"replayed nonce", signedRequest("shared-secret", body, time.Now(), "nonce-1")},
+		{"wrong secret", signedRequest("other-secret", body, time.Now(), "nonce-2")},
+		{"stale timestamp", signedRequest("shared-secret", body, time.Now().Add(-2*time.Minute), "nonce-3")},
+		{"future timestamp", signedRequest("shared-secret", body, time.Now().Add(2*time.Minute), "nonce-4")},
+		{"tampered body", tampered},
+		{"replaced nonce", renonced},
+		{"unsigned request", httptest.NewRequest("POST", "/alerts", strings.NewReader(body))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := serve(tt.req); code != http.StatusUnauthorized {
+				t.Errorf("Expected status %d, got %d", http.StatusUnauthorized, code)
+			}
+		})
+	}
+}
+
+// writeFile writes a file in dir and returns its path
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+func TestAuthMiddleware_Credentials(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "eu-password", "eu-pass\n")
+	writeFile(t, dir, "us-token", "us-token-1\n")
+	path := writeFile(t, dir, "credentials.yaml", `credentials:
+  - label: alertmanager-eu
+    username: alertmanager
+    passwordFile: `+filepath.Join(dir, "eu-password")+`
+  - label: alertmanager-us
+    tokenFile: `+filepath.Join(dir, "us-token")+`
+`)
+
+	credentials, err := handlers.LoadCredentials(path)
+	if err != nil {
+		t.Fatalf("Failed to load credentials: %v", err)
+	}
+
+	// serve returns the status and the credential label seen by the handler
+	serve := func(config handlers.AuthConfig, setup func(r *http.Request)) (int, string, string) {
+		var credential, principal string
+		handler := handlers.AuthMiddleware(config)(func(w http.ResponseWriter, r *http.Request) {
+			credential = handlers.CredentialFromContext(r.Context())
+			principal = handlers.PrincipalFromContext(r.Context())
+		})
+		req := httptest.NewRequest("POST", "/alerts", strings.NewReader("{}"))
+		setup(req)
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		return rec.Code, credential, principal
+	}
+	basic := handlers.AuthConfig{Method: handlers.AuthMethodBasic, BasicUser: "flaguser", BasicPass: "flagpass", Credentials: credentials}
+	bearer := handlers.AuthConfig{Method: handlers.AuthMethodBearer, Credentials: credentials}
+
+	code, credential, _ := serve(basic, func(r *http.Request) { r.SetBasicAuth("alertmanager", "eu-pass") })
+	if code != http.StatusOK || credential != "alertmanager-eu" {
+		t.Errorf("Expected 200 with credential alertmanager-eu, got %d with %q", code, credential)
+	}
+	code, credential, _ = serve(basic, func(r *http.Request) { r.SetBasicAuth("flaguser", "flagpass") })
+	if code != http.StatusOK || credential != handlers.DefaultCredentialLabel {
+		t.Errorf("Expected 200 with the default credential, got %d with %q", code, credential)
+	}
+	code, credential, principal := serve(bearer, func(r *http.Request) { r.Header.Set("Authorization", "Bearer us-token-1") })
+	if code != http.StatusOK || credential != "alertmanager-us" || principal != "bearer:alertmanager-us" {
+		t.Errorf("Expected 200 with credential alertmanager-us, got %d with %q as %q", code, credential, principal)
+	}
+	// A token isn't accepted as basic auth password and the other way around
+	if code, _, _ := serve(bearer, func(r *http.Request) { r.Header.Set("Authorization", "Bearer eu-pass") }); code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for a password used as token, got %d", code)
+	}
+
+	// Rotate the token, the old one stops working once reloaded
+	writeFile(t, dir, "us-token", "us-token-2")
+	if changed, err := credentials.Reload(); err != nil || !changed {
+		t.Fatalf("Expected the rotated token to be reloaded, got changed=%v, err=%v", changed, err)
+	}
+	if code, _, _ := serve(bearer, func(r *http.Request) { r.Header.Set("Authorization", "Bearer us-token-1") }); code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for the rotated token, got %d", code)
+	}
+	if code, _, _ := serve(bearer, func(r *http.Request) { r.Header.Set("Authorization", "Bearer us-token-2") }); code != http.StatusOK {
+		t.Errorf("Expected 200 for the new token, got %d", code)
+	}
+
+	// An invalid file keeps the previous credentials
+	writeFile(t, dir, "credentials.yaml", "credentials:\n  - label: broken\n")
+	if _, err := credentials.Reload(); err == nil {
+		t.Error("Expected an error for a credential without a username or token")
+	}
+	if code, _, _ := serve(bearer, func(r *http.Request) { r.Header.Set("Authorization", "Bearer us-token-2") }); code != http.StatusOK {
+		t.Errorf("Expected the previous credentials to be kept, got %d", code)
+	}
+}
+
+func TestAuthMiddleware_HMACCredentials(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "grafana-secret", "grafana-secret\n")
+	credentials, err := handlers.LoadCredentials(writeFile(t, dir, "credentials.yaml", `credentials:
+  - label: grafana
+    hmacSecretFile: `+filepath.Join(dir, "grafana-secret")+`
+  - label: alertmanager
+    token: not-a-secret
+`))
+	if err != nil {
+		t.Fatalf("Failed to load credentials: %v", err)
+	}
+
+	authConfig := handlers.AuthConfig{
+		Method:              handlers.AuthMethodHMAC,
+		HMACSecret:          "shared-secret",
+		HMACSignatureHeader: "X-Signature",
+		HMACTolerance:       time.Minute,
+		Credentials:         credentials,
+	}
+	var credential, principal string
+	handler := handlers.AuthMiddleware(authConfig)(func(w http.ResponseWriter, r *http.Request) {
+		credential = handlers.CredentialFromContext(r.Context())
+		principal = handlers.PrincipalFromContext(r.Context())
+	})
+	serve := func(req *http.Request) int {
+		credential, principal = "", ""
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		return rec.Code
+	}
+
+	body := `{"status":"firing"}`
+	if code := serve(signedRequest("grafana-secret", body, time.Now(), "nonce-1")); code != http.StatusOK || credential != "grafana" || principal != "hmac:grafana" {
+		t.Errorf("Expected 200 with credential grafana, got %d with %q as %q", code, credential, principal)
+	}
+	if code := serve(signedRequest("shared-secret", body, time.Now(), "nonce-2")); code != http.StatusOK || credential != handlers.DefaultCredentialLabel {
+		t.Errorf("Expected 200 with the default credential, got %d with %q", code, credential)
+	}
+	// A bearer token isn't accepted as HMAC secret
+	if code := serve(signedRequest("not-a-secret", body, time.Now(), "nonce-3")); code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for a request signed with a token, got %d", code)
+	}
+}
+
+func TestLoadCredentials_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"duplicate label":    "credentials:\n  - {label: a, token: x}\n  - {label: a, token: y}\n",
+		"missing label":      "credentials:\n  - {token: x}\n",
+		"username and token": "credentials:\n  - {label: a, username: u, password: p, token: x}\n",
+		"token and secret":   "credentials:\n  - {label: a, token: x, hmacSecret: y}\n",
+		"missing password":   "credentials:\n  - {label: a, username: u}\n",
+		"unknown field":      "credentials:\n  - {label: a, token: x, secret: y}\n",
+		"missing file":       "credentials:\n  - {label: a, tokenFile: /does/not/exist}\n",
+	} {
+		if _, err := handlers.LoadCredentials(writeFile(t, dir, "credentials.yaml", content)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+// oidcIssuer signs test tokens with a key published in a local key set
+type oidcIssuer struct {
+	t        *testing.T
+	signer   jose.Signer
+	jwksFile string
+}
+
+func newOIDCIssuer(t *testing.T) *oidcIssuer {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	jwk := jose.JSONWebKey{Key: key, KeyID: "test", Algorithm: string(jose.RS256), Use: "sig"}
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.RS256, Key: jwk}, nil)
+	if err != nil {
+		t.Fatalf("Failed to create signer: %v", err)
+	}
+	jwks, err := json.Marshal(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{jwk.Public()}})
+	if err != nil {
+		t.Fatalf("Failed to encode key set: %v", err)
+	}
+	return &oidcIssuer{t: t, signer: signer, jwksFile: writeFile(t, t.TempDir(), "jwks.json", string(jwks))}
+}
+
+// token signs claims, completed by a valid issuer, audience and expiry unless given
+func (i *oidcIssuer) token(claims map[string]any) string {
+	i.t.Helper()
+	defaults := map[string]any{
+		"iss": "https://idp.example.com",
+		"aud": "openfero",
+		"sub": "user-1",
+		"exp": time.Now().Add(time.Hour).Unix(),
+		"iat": time.Now().Unix(),
+	}
+	for name, value := range defaults {
+		if _, ok := claims[name]; !ok {
+			claims[name] = value
+		}
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		i.t.Fatalf("Failed to encode claims: %v", err)
+	}
+	signed, err := i.signer.Sign(payload)
+	if err != nil {
+		i.t.Fatalf("Failed to sign token: %v", err)
+	}
+	token, err := signed.CompactSerialize()
+	if err != nil {
+		i.t.Fatalf("Failed to serialize token: %v", err)
+	}
+	return token
+}
+
+func (i *oidcIssuer) authenticator(rolesClaim string) *handlers.OIDCAuthenticator {
+	i.t.Helper()
+	authenticator, err := handlers.NewOIDCAuthenticator(context.Background(), handlers.OIDCConfig{
+		IssuerURL:     "https://idp.example.com",
+		JWKSFile:      i.jwksFile,
+		Audience:      "openfero",
+		RolesClaim:    rolesClaim,
+		ViewerRoles:   []string{"openfero-viewers"},
+		OperatorRoles: []string{"openfero-operators"},
+		AdminRoles:    []string{"openfero-admins"},
+	})
+	if err != nil {
+		i.t.Fatalf("Failed to create authenticator: %v", err)
+	}
+	return authenticator
+}
+
+func TestOIDCAuthenticator_Authenticate(t *testing.T) {
+	issuer := newOIDCIssuer(t)
+	authenticator := issuer.authenticator("realm_access.roles")
+	other := newOIDCIssuer(t)
+
+	tests := []struct {
+		name     string
+		token    string
+		username string
+		role     handlers.Role
+		valid    bool
+	}{
+		{
+			name:     "nested roles claim",
+			token:    issuer.token(map[string]any{"preferred_username": "jane", "realm_access": map[string]any{"roles": []string{"openfero-viewers", "openfero-operators"}}}),
+			username: "jane",
+			role:     handlers.RoleOperator,
+			valid:    true,
+		},
+		{
+			name:     "no matching role",
+			token:    issuer.token(map[string]any{"realm_access": map[string]any{"roles": []string{"developers"}}}),
+			username: "user-1",
+			role:     handlers.RoleNone,
+			valid:    true,
+		},
+		{name: "wrong issuer", token: issuer.token(map[string]any{"iss": "https://evil.example.com"})},
+		{name: "wrong audience", token: issuer.token(map[string]any{"aud": "other-client"})},
+		{name: "expired", token: issuer.token(map[string]any{"exp": time.Now().Add(-time.Minute).Unix()})},
+		{name: "unknown key", token: other.token(map[string]any{})},
+		{name: "not a token", token: "not-a-token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/alerts", nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			identity, err := authenticator.Authenticate(req)
+			if !tt.valid {
+				if err == nil {
+					t.Error("Expected the token to be rejected")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected a valid token, got: %v", err)
+			}
+			if identity.Username != tt.username || identity.Role != tt.role {
+				t.Errorf("Expected %s with role %q, got %s with role %q", tt.username, tt.role, identity.Username, identity.Role)
+			}
+		})
+	}
+
+	// A WebSocket upgrade may pass the token as query parameter, other requests may not
+	token := issuer.token(map[string]any{"realm_access": map[string]any{"roles": []string{"openfero-admins"}}})
+	req := httptest.NewRequest("GET", "/api/ws?access_token="+token, nil)
+	if _, err := authenticator.Authenticate(req); err == nil {
+		t.Error("Expected the query parameter to be ignored without a WebSocket upgrade")
+	}
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	if identity, err := authenticator.Authenticate(req); err != nil || identity.Role != handlers.RoleAdmin {
+		t.Errorf("Expected the WebSocket upgrade to authenticate as admin, got %v, %v", identity, err)
+	}
+}
+
+func TestRegisterRoutes_OIDC(t *testing.T) {
+	issuer := newOIDCIssuer(t)
+	server := &handlers.Server{AlertStore: memory.NewMemoryStore(10)}
+	frontend := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("<html></html>"))
+	})
+	mux := http.NewServeMux()
+	registerRoutes(mux, server, frontend, issuer.authenticator("groups"))
+
+	viewerToken := issuer.token(map[string]any{"groups": []string{"openfero-viewers"}})
+	noRoleToken := issuer.token(map[string]any{"groups": []string{"developers"}})
+	serve := func(method, path, token string) int {
+		req := httptest.NewRequest(method, path, strings.NewReader("{}"))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	for _, route := range apiRoutes(server) {
+		method, path, _ := strings.Cut(route.pattern, " ")
+		path = strings.NewReplacer("{namespace}", "openfero", "{name}", "job", "{id}", "1").Replace(path)
+
+		if code := serve(method, path, ""); code != http.StatusUnauthorized {
+			t.Errorf("%s: expected %d without a token, got %d", route.pattern, http.StatusUnauthorized, code)
+		}
+		if code := serve(method, path, noRoleToken); code != http.StatusForbidden {
+			t.Errorf("%s: expected %d without a role, got %d", route.pattern, http.StatusForbidden, code)
+		}
+		if route.role == handlers.RoleOperator {
+			if code := serve(method, path, viewerToken); code != http.StatusForbidden {
+				t.Errorf("%s: expected %d for a viewer, got %d", route.pattern, http.StatusForbidden, code)
+			}
+		}
+	}
+
+	for _, path := range []string{"/", "/jobs", "/swagger/index.html", "/alertStore"} {
+		if code := serve("GET", path, ""); code != http.StatusUnauthorized {
+			t.Errorf("%s: expected %d without a token, got %d", path, http.StatusUnauthorized, code)
+		}
+	}
+	if code := serve("GET", "/", viewerToken); code != http.StatusOK {
+		t.Errorf("Expected a viewer to load the UI, got %d", code)
+	}
+	if code := serve("GET", "/api/about", viewerToken); code != http.StatusOK {
+		t.Errorf("Expected a viewer to read the API, got %d", code)
+	}
+	if code := serve("GET", "/healthz", ""); code != http.StatusOK {
+		t.Errorf("Expected health checks to stay open, got %d", code)
+	}
+}
+
+func TestRegisterRoutes_WithoutOIDC(t *testing.T) {
+	server := &handlers.Server{
+		AlertStore: memory.NewMemoryStore(10),
+		AuthConfig: handlers.AuthConfig{Method: handlers.AuthMethodBearer, BearerToken: "secret-token"},
+	}
+	mux := http.NewServeMux()
+	registerRoutes(mux, server, http.NotFoundHandler(), nil)
+
+	// Reading stays open, actions are protected by the webhook authentication
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/api/about", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected the API to be readable, got %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("POST", "/api/operarii/restart/trigger", strings.NewReader("{}")))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected actions to require the webhook token, got %d", rec.Code)
+	}
+}
+
+func TestClientCertMiddleware(t *testing.T) {
+	certificate := &x509.Certificate{Subject: pkix.Name{CommonName: "alertmanager-0"}, DNSNames: []string{"alertmanager.monitoring.svc"}}
+	serve := func(names []string, state *tls.ConnectionState) (int, string) {
+		var credential string
+		handler := handlers.ClientCertMiddleware(names)(func(w http.ResponseWriter, r *http.Request) {
+			credential = handlers.CredentialFromContext(r.Context())
+		})
+		req := httptest.NewRequest("POST", "/alerts", strings.NewReader("{}"))
+		req.TLS = state
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		return rec.Code, credential
+	}
+	verified := &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{certificate}}}
+
+	if code, credential := serve(nil, verified); code != http.StatusOK || credential != "alertmanager-0" {
+		t.Errorf("Expected 200 with credential alertmanager-0, got %d with %q", code, credential)
+	}
+	if code, _ := serve([]string{"alertmanager.monitoring.svc"}, verified); code != http.StatusOK {
+		t.Errorf("Expected a matching DNS name to be accepted, got %d", code)
+	}
+	if code, _ := serve([]string{"prometheus"}, verified); code != http.StatusForbidden {
+		t.Errorf("Expected %d for a certificate of another name, got %d", http.StatusForbidden, code)
+	}
+	if code, _ := serve(nil, &tls.ConnectionState{}); code != http.StatusUnauthorized {
+		t.Errorf("Expected %d without a client certificate, got %d", http.StatusUnauthorized, code)
+	}
+	if code, _ := serve(nil, nil); code != http.StatusUnauthorized {
+		t.Errorf("Expected %d without TLS, got %d", http.StatusUnauthorized, code)
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	if _, err := handlers.NewRateLimiter(handlers.RateLimitConfig{Rate: 0}); err == nil {
+		t.Error("Expected an error for a rate of 0")
+	}
+	if _, err := handlers.NewRateLimiter(handlers.RateLimitConfig{Rate: 1, By: "header"}); err == nil {
+		t.Error("Expected an error for an unknown rate limit key")
+	}
+
+	newHandler := func(by string) http.HandlerFunc {
+		limiter, err := handlers.NewRateLimiter(handlers.RateLimitConfig{Rate: 0.01, Burst: 2, By: by})
+		if err != nil {
+			t.Fatalf("Failed to create rate limiter: %v", err)
+		}
+		return limiter.Middleware(func(w http.ResponseWriter, r *http.Request) {})
+	}
+	post := func(handler http.HandlerFunc, remoteAddr string, credential string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/alerts", strings.NewReader("{}"))
+		req.RemoteAddr = remoteAddr
+		if credential != "" {
+			req.Header.Set("Authorization", "Bearer "+credential)
+		}
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		return rec
+	}
+
+	handler := newHandler(handlers.RateLimitByRemote)
+	for i := range 2 {
+		if code := post(handler, "10.0.0.1:1234", "").Code; code != http.StatusOK {
+			t.Fatalf("Expected request %d within the burst to pass, got %d", i+1, code)
+		}
+	}
+	rec := post(handler, "10.0.0.1:5678", "")
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") == "" {
+		t.Errorf("Expected 429 with Retry-After above the burst, got %d", rec.Code)
+	}
+	if code := post(handler, "10.0.0.2:1234", "").Code; code != http.StatusOK {
+		t.Errorf("Expected another remote address to have its own limit, got %d", code)
+	}
+
+	// By credential, the limiter runs after the authentication
+	auth := handlers.AuthMiddleware(handlers.AuthConfig{Method: handlers.AuthMethodBearer, BearerToken: "token"})
+	handler = auth(newHandler(handlers.RateLimitByCredential))
+	for _, remoteAddr := range []string{"10.0.0.1:1", "10.0.0.2:1"} {
+		if code := post(handler, remoteAddr, "token").Code; code != http.StatusOK {
+			t.Fatalf("Expected request from %s within the burst to pass, got %d", remoteAddr, code)
+		}
+	}
+	if code := post(handler, "10.0.0.3:1", "token").Code; code != http.StatusTooManyRequests {
+		t.Errorf("Expected the credential to be limited across remote addresses, got %d", code)
+	}
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/yaml"
+	sigsyaml "sigs.k8s.io/yaml"
+
+	operariusv1alpha1 "github.com/OpenFero/openfero/api/v1alpha1"
+	"github.com/OpenFero/openfero/pkg/alertstore"
+	"github.com/OpenFero/openfero/pkg/kubernetes"
+	"github.com/OpenFero/openfero/pkg/services"
+)
+
+// backtestConfig holds the command line options of the backtest mode
+type backtestConfig struct {
+	// HistoryFile is an NDJSON export of the alert store
+	HistoryFile string
+	// OperariiPath is a YAML file or directory of Operarius manifests. If
+	// empty, the Operarii are read from the cluster.
+	OperariiPath       string
+	OperariusNamespace string
+	Kubeconfig         *string
+	IncludeDisabled    bool
+}
+
+// runBacktest replays an exported alert history against the Operarii and
+// writes the result as JSON to out
+func runBacktest(ctx context.Context, cfg backtestConfig, out io.Writer) error {
+	file, err := os.Open(cfg.HistoryFile)
+	if err != nil {
+		return fmt.Errorf("failed to open alert history: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	entries, err := alertstore.ReadNDJSON(file)
+	if err != nil {
+		return err
+	}
+
+	var operarii []operariusv1alpha1.Operarius
+	if cfg.OperariiPath != "" {
+		operarii, err = loadOperarii(cfg.OperariiPath)
+	} else {
+		operarii, err = listOperarii(ctx, cfg)
+	}
+	if err != nil {
+		return err
+	}
+
+	result := services.NewOperariusService(nil).Backtest(operarii, entries, services.BacktestOptions{
+		IncludeDisabled: cfg.IncludeDisabled,
+	})
+
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(result)
+}
+
+// listOperarii reads the Operarii from the cluster
+func listOperarii(ctx context.Context, cfg backtestConfig) ([]operariusv1alpha1.Operarius, error) {
+	namespace := cfg.OperariusNamespace
+	if namespace == "" {
+		current, err := kubernetes.GetCurrentNamespace()
+		if err != nil {
+			return nil, fmt.Errorf("current kubernetes namespace could not be found: %w", err)
+		}
+		namespace = current
+	}
+
+	client, err := kubernetes.NewOperariusClient(cfg.Kubeconfig, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Operarius client: %w", err)
+	}
+	return client.ListFromAPI(ctx)
+}
+
+// loadOperarii reads Operarius manifests from a YAML file or from all YAML
+// files in a directory. Documents of other kinds are ignored.
+func loadOperarii(path string) ([]operariusv1alpha1.Operarius, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Operarii: %w", err)
+	}
+
+	files := []string{path}
+	if info.IsDir() {
+		files = nil
+		for _, pattern := range []string{"*.yaml", "*.yml"} {
+			matches, err := filepath.Glob(filepath.Join(path, pattern))
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, matches...)
+		}
+	}
+
+	var operarii []operariusv1alpha1.Operarius
+	for _, name := range files {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read Operarii: %w", err)
+		}
+		parsed, err := parseOperarii(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		operarii = append(operarii, parsed...)
+	}
+	return operarii, nil
+}
+
+// parseOperarii decodes the Operarius documents of a multi-document YAML stream
+func parseOperarii(data []byte) ([]operariusv1alpha1.Operarius, error) {
+	var operarii []operariusv1alpha1.Operarius
+
+	reader := yaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(data)))
+	for {
+		document, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return operarii, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid YAML: %w", err)
+		}
+		if len(bytes.TrimSpace(document)) == 0 {
+			continue
+		}
+
+		var operarius operariusv1alpha1.Operarius
+		if err := sigsyaml.Unmarshal(document, &operarius); err != nil {
+			return nil, fmt.Errorf("invalid Operarius: %w", err)
+		}
+		if !strings.EqualFold(operarius.Kind, "Operarius") {
+			continue
+		}
+		operarii = append(operarii, operarius)
+	}
+}
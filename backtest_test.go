@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/OpenFero/openfero/pkg/alertstore"
+	"github.com/OpenFero/openfero/pkg/services"
+)
+
+const backtestOperariiYAML = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: unrelated
+---
+apiVersion: openfero.io/v1alpha1
+kind: Operarius
+metadata:
+  name: restart-pod
+  namespace: openfero
+spec:
+  alertSelector:
+    alertname: HighMemory
+    status: firing
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          restartPolicy: Never
+          containers:
+          - name: restart
+            image: busybox
+`
+
+func TestRunBacktest(t *testing.T) {
+	dir := t.TempDir()
+
+	operariiPath := filepath.Join(dir, "operarii.yaml")
+	if err := os.WriteFile(operariiPath, []byte(backtestOperariiYAML), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var history bytes.Buffer
+	now := time.Now()
+	err := alertstore.WriteNDJSON(&history, []alertstore.AlertEntry{
+		{Alert: alertstore.Alert{Labels: map[string]string{"alertname": "HighMemory"}}, Status: "firing", Timestamp: now},
+		{Alert: alertstore.Alert{Labels: map[string]string{"alertname": "DiskFull"}}, Status: "firing", Timestamp: now},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	historyPath := filepath.Join(dir, "alerts.ndjson")
+	if err := os.WriteFile(historyPath, history.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	err = runBacktest(context.Background(), backtestConfig{HistoryFile: historyPath, OperariiPath: dir}, &out)
+	if err != nil {
+		t.Fatalf("runBacktest() error = %v", err)
+	}
+
+	var result services.BacktestResult
+	if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+		t.Fatalf("invalid backtest output: %v", err)
+	}
+	if result.Alerts != 2 || result.Unmatched != 1 {
+		t.Errorf("got %d alerts and %d unmatched, want 2 and 1", result.Alerts, result.Unmatched)
+	}
+	if len(result.Operarii) != 1 || result.Operarii[0].Operarius != "restart-pod" || result.Operarii[0].WouldRun != 1 {
+		t.Errorf("unexpected Operarii result: %+v", result.Operarii)
+	}
+}
+
+func TestRunBacktest_MissingHistory(t *testing.T) {
+	err := runBacktest(context.Background(), backtestConfig{HistoryFile: filepath.Join(t.TempDir(), "missing.ndjson")}, &bytes.Buffer{})
+	if err == nil {
+		t.Error("expected an error for a missing history file")
+	}
+}
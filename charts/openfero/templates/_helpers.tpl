@@ -64,6 +64,24 @@ Create the name of the service account to use
 {{- end }}
 {{- end }}
 
+{{/*
+Render a probe, switching HTTP probes to HTTPS when TLS is enabled
+*/}}
+{{- define "openfero.probe" -}}
+{{- $probe := deepCopy .probe -}}
+{{- if and .tls $probe.httpGet -}}
+{{- $_ := set $probe.httpGet "scheme" "HTTPS" -}}
+{{- end -}}
+{{- toYaml $probe -}}
+{{- end }}
+
+{{/*
+Name of the Secret holding the serving certificate
+*/}}
+{{- define "openfero.tlsSecretName" -}}
+{{- default (printf "%s-tls" (include "openfero.fullname" .)) .Values.tls.existingSecret -}}
+{{- end }}
+
 {{/*
 Determine if alertStoreType should be set
 */}}
@@ -2,9 +2,22 @@ import { defineStore } from 'pinia'
 import { ref } from 'vue'
 import type { AlertStoreEntry, JobInfo } from '@/types'
 
+export interface JobRef {
+  namespace: string
+  name: string
+}
+
+export interface JobLogLine extends JobRef {
+  line: string
+}
+
+export interface JobLogError extends JobRef {
+  error: string
+}
+
 export interface WSMessage {
-  type: 'alert' | 'operarius_update' | 'connected'
-  data: AlertStoreEntry | JobInfo | { message: string }
+  type: 'alert' | 'operarius_update' | 'connected' | 'job_log' | 'job_log_end' | 'job_log_error'
+  data: AlertStoreEntry | JobInfo | JobRef | JobLogLine | JobLogError | { message: string }
 }
 
 export const useSocketStore = defineStore('socket', () => {
@@ -47,13 +60,16 @@ export const useSocketStore = defineStore('socket', () => {
     }
 
     socket.onmessage = (event) => {
-      try {
-        const message = JSON.parse(event.data) as WSMessage
-        listeners.forEach((listener) => {
-          listener(message)
-        })
-      } catch {
-        // Ignore unparsable messages
+      // The server batches queued messages into one frame, separated by newlines
+      for (const raw of String(event.data).split('\n')) {
+        try {
+          const message = JSON.parse(raw) as WSMessage
+          listeners.forEach((listener) => {
+            listener(message)
+          })
+        } catch {
+          // Ignore unparsable messages
+        }
       }
     }
   }
@@ -91,6 +107,14 @@ export const useSocketStore = defineStore('socket', () => {
     }, 3000)
   }
 
+  function subscribeLogs(job: JobRef) {
+    socket?.send(JSON.stringify({ type: 'subscribe_logs', data: job }))
+  }
+
+  function unsubscribeLogs(job: JobRef) {
+    socket?.send(JSON.stringify({ type: 'unsubscribe_logs', data: job }))
+  }
+
   function addListener(callback: (message: WSMessage) => void) {
     listeners.add(callback)
     return () => listeners.delete(callback)
@@ -104,5 +128,7 @@ export const useSocketStore = defineStore('socket', () => {
     disconnect,
     toggleConnection,
     addListener,
+    subscribeLogs,
+    unsubscribeLogs,
   }
 })
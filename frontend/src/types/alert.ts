@@ -10,6 +10,12 @@ export interface Alert {
   startsAt?: string
   /** Time when the alert ended */
   endsAt?: string
+  /** Status of this alert, which can differ from the status of its group */
+  status?: string
+  /** Link to the source of the alert */
+  generatorURL?: string
+  /** Alertmanager fingerprint identifying the alert by its labels */
+  fingerprint?: string
 }
 
 /**
@@ -28,6 +34,38 @@ export interface JobCondition {
   message?: string
 }
 
+/**
+ * Pod-level diagnostics of a failed remediation job
+ */
+export interface JobFailure {
+  /** Name of the pod the failure was observed on */
+  podName?: string
+  /** Name of the failing container */
+  containerName?: string
+  /** Failure reason, e.g. ImagePullBackOff or OOMKilled */
+  reason: string
+  /** Exit code of the terminated container */
+  exitCode?: number
+  /** Container termination message */
+  message?: string
+}
+
+/**
+ * Output captured from a finished remediation job
+ */
+export interface JobOutput {
+  /** Name of the pod the output was captured from */
+  podName?: string
+  /** Container termination message */
+  terminationMessage?: string
+  /** Tail of the pod logs */
+  logs?: string
+  /** Whether earlier log output was dropped */
+  logsTruncated?: boolean
+  /** Time when the output was captured */
+  capturedAt: string
+}
+
 /**
  * Information about a triggered remediation job
  */
@@ -54,12 +92,18 @@ export interface JobInfo {
   startedAt?: string
   /** Time when the job completed */
   completedAt?: string
+  /** Why the job's pod failed, if it did */
+  failure?: JobFailure
+  /** What the job reported once it finished */
+  output?: JobOutput
 }
 
 /**
  * Stored alert entry with status and metadata
  */
 export interface AlertStoreEntry {
+  /** ID assigned by the alert store */
+  id?: string
   /** The alert data */
   alert: Alert
   /** Alert status: firing or resolved */
@@ -3,26 +3,38 @@ package main
 import (
 	"context"
 	"embed"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
 	"time"
 
 	operariusv1alpha1 "github.com/OpenFero/openfero/api/v1alpha1"
+	"github.com/OpenFero/openfero/pkg/cloudevents"
 	_ "github.com/OpenFero/openfero/pkg/docs"
 	"github.com/OpenFero/openfero/pkg/handlers"
+	"github.com/OpenFero/openfero/pkg/ingest"
 	"github.com/OpenFero/openfero/pkg/kubernetes"
 	log "github.com/OpenFero/openfero/pkg/logging"
 	"github.com/OpenFero/openfero/pkg/metadata"
 	"github.com/OpenFero/openfero/pkg/models"
 	"github.com/OpenFero/openfero/pkg/services"
+	"github.com/OpenFero/openfero/pkg/tlsconfig"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	httpSwagger "github.com/swaggo/http-swagger/v2"
 
 	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/OpenFero/openfero/pkg/alertstore"
+	"github.com/OpenFero/openfero/pkg/alertstore/bolt"
 	"github.com/OpenFero/openfero/pkg/alertstore/memberlist"
 	"github.com/OpenFero/openfero/pkg/alertstore/memory"
 )
@@ -48,13 +60,21 @@ func validateAuthConfig(config handlers.AuthConfig) error {
 		// No validation needed for "none"
 		return nil
 	case handlers.AuthMethodBasic:
-		if config.BasicUser == "" || config.BasicPass == "" {
-			return fmt.Errorf("basic authentication requires both username and password")
+		if (config.BasicUser == "" || config.BasicPass == "") && !slices.ContainsFunc(config.Credentials.List(), func(c handlers.Credential) bool { return c.Username != "" }) {
+			return fmt.Errorf("basic authentication requires both username and password, or a credentials file with basic credentials")
 		}
 		return nil
 	case handlers.AuthMethodBearer:
-		if config.BearerToken == "" {
-			return fmt.Errorf("bearer authentication requires a token")
+		if config.BearerToken == "" && !slices.ContainsFunc(config.Credentials.List(), func(c handlers.Credential) bool { return c.Token != "" }) {
+			return fmt.Errorf("bearer authentication requires a token, or a credentials file with tokens")
+		}
+		return nil
+	case handlers.AuthMethodHMAC:
+		if config.HMACSecret == "" && !slices.ContainsFunc(config.Credentials.List(), func(c handlers.Credential) bool { return c.HMACSecret != "" }) {
+			return fmt.Errorf("hmac authentication requires a secret, or a credentials file with HMAC secrets")
+		}
+		if config.HMACTolerance <= 0 {
+			return fmt.Errorf("hmac authentication requires a positive timestamp tolerance")
 		}
 		return nil
 	default:
@@ -62,6 +82,107 @@ func validateAuthConfig(config handlers.AuthConfig) error {
 	}
 }
 
+// apiRoute is an API route and the role needed to use it with OIDC
+type apiRoute struct {
+	pattern string
+	role    handlers.Role
+	handler http.HandlerFunc
+}
+
+// apiRoutes lists the API routes. Reading needs the viewer role, changing
+// anything at least the operator role.
+func apiRoutes(server *handlers.Server) []apiRoute {
+	return []apiRoute{
+		{"GET /api/jobs", handlers.RoleViewer, server.JobsAPIHandler},
+		{"GET /api/jobs/{namespace}/{name}/logs", handlers.RoleViewer, server.JobLogsAPIHandler},
+		{"POST /api/jobs/{namespace}/{name}/cancel", handlers.RoleOperator, server.JobCancelAPIHandler},
+		{"POST /api/jobs/{namespace}/{name}/retry", handlers.RoleOperator, server.JobRetryAPIHandler},
+		{"POST /api/alerts/{id}/rerun", handlers.RoleOperator, server.AlertRerunAPIHandler},
+		{"POST /api/operarii/{name}/trigger", handlers.RoleOperator, server.OperariusTriggerAPIHandler},
+		// Dry runs, nothing is created
+		{"POST /api/simulate", handlers.RoleViewer, server.SimulateAPIHandler},
+		{"POST /api/backtest", handlers.RoleViewer, server.BacktestAPIHandler},
+		{"GET /api/alerts", handlers.RoleViewer, server.AlertStoreGetHandler},
+		{"GET /api/lifecycles", handlers.RoleViewer, server.LifecyclesAPIHandler},
+		{"GET /api/about", handlers.RoleViewer, handlers.AboutAPIHandler},
+		{"GET /api/ws", handlers.RoleViewer, handlers.WebSocketHandler}, // WebSocket for real-time updates
+	}
+}
+
+// registerRoutes registers the HTTP routes. With OIDC, the API, the Swagger
+// UI and the SPA require a token with the role of the route. Without it,
+// reading is open and actions are protected by the webhook authentication.
+func registerRoutes(mux *http.ServeMux, server *handlers.Server, frontend http.Handler, apiAuth *handlers.OIDCAuthenticator) {
+	authMiddleware := handlers.AuthMiddleware(server.AuthConfig)
+	protect := func(role handlers.Role) func(http.HandlerFunc) http.HandlerFunc {
+		switch {
+		case apiAuth != nil:
+			return apiAuth.RequireRole(role)
+		case role.Allows(handlers.RoleOperator):
+			return authMiddleware
+		default:
+			return func(next http.HandlerFunc) http.HandlerFunc { return next }
+		}
+	}
+	viewer := protect(handlers.RoleViewer)
+
+	mux.HandleFunc("GET /healthz", server.HealthzGetHandler)
+	mux.HandleFunc("GET /readiness", server.ReadinessGetHandler)
+	mux.HandleFunc("GET /startupz", server.StartupzGetHandler)
+	mux.HandleFunc("GET /alertStore", viewer(func(w http.ResponseWriter, r *http.Request) {
+		log.Warn("Deprecated: /alertStore endpoint is deprecated, use /api/alerts instead",
+			"remoteAddr", r.RemoteAddr)
+		server.AlertStoreGetHandler(w, r)
+	}))
+	mux.HandleFunc("GET /alerts", server.AlertsGetHandler)
+
+	// Apply authentication middleware to the webhook endpoint
+	webhookAuth := authMiddleware
+	if server.AuthConfig.ClientCertRequired {
+		clientCert := handlers.ClientCertMiddleware(server.AuthConfig.ClientCertNames)
+		webhookAuth = func(next http.HandlerFunc) http.HandlerFunc { return clientCert(authMiddleware(next)) }
+	}
+	// Rate limit by remote address before authenticating, so failed attempts
+	// count too, and by credential after it
+	if limiter := server.WebhookRateLimiter; limiter != nil {
+		authenticate := webhookAuth
+		if limiter.ByCredential() {
+			webhookAuth = func(next http.HandlerFunc) http.HandlerFunc { return authenticate(limiter.Middleware(next)) }
+		} else {
+			webhookAuth = func(next http.HandlerFunc) http.HandlerFunc { return limiter.Middleware(authenticate(next)) }
+		}
+	}
+	mux.HandleFunc("POST /alerts", server.LimitBody(webhookAuth(server.AlertsPostHandler)))
+	mux.HandleFunc("POST /alerts/{adapter}", server.LimitBody(webhookAuth(server.AlertsAdapterPostHandler)))
+
+	// API routes (JSON)
+	for _, route := range apiRoutes(server) {
+		mux.HandleFunc(route.pattern, protect(route.role)(route.handler))
+	}
+
+	// Swagger documentation
+	mux.HandleFunc("GET /swagger/", viewer(httpSwagger.Handler(
+		httpSwagger.DeepLinking(true),
+		httpSwagger.DocExpansion("none"),
+		httpSwagger.DomID("swagger-ui"),
+	)))
+
+	// Vue.js SPA - serve frontend for all unmatched routes
+	// This must be registered last as a catch-all
+	mux.HandleFunc("GET /", viewer(frontend.ServeHTTP))
+}
+
+// splitList splits a comma separated flag value, ignoring empty items
+func splitList(value string) []string {
+	var items []string
+	for item := range strings.SplitSeq(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 // @title OpenFero API
 // @version 1.0
 // @description OpenFero is intended as an event-triggered job scheduler for code agnostic recovery jobs.
@@ -81,18 +202,91 @@ func main() {
 	kubeconfig := flag.String("kubeconfig", "", "absolute path to the kubeconfig file")
 	readTimeout := flag.Int("readTimeout", 5, "read timeout in seconds")
 	writeTimeout := flag.Int("writeTimeout", 10, "write timeout in seconds")
+	tlsCertFile := flag.String("tlsCertFile", "", "PEM certificate chain to serve TLS with, reloaded when it changes; plain HTTP if empty")
+	tlsKeyFile := flag.String("tlsKeyFile", "", "PEM private key of the TLS certificate, reloaded when it changes")
+	tlsClientCAFile := flag.String("tlsClientCAFile", "", "PEM CA bundle client certificates are verified against")
+	tlsMinVersion := flag.String("tlsMinVersion", "1.2", "minimum TLS version (1.2, 1.3)")
+	tlsCipherSuites := flag.String("tlsCipherSuites", "", "comma separated TLS 1.2 cipher suites, e.g. TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256; Go's secure defaults if empty")
+	tlsReloadInterval := flag.Int("tlsReloadInterval", int(tlsconfig.DefaultReloadInterval.Seconds()), "seconds between checks of the TLS files for changes")
+	webhookClientCert := flag.Bool("webhookClientCert", false, "require webhooks to present a client certificate signed by tlsClientCAFile (mTLS)")
+	webhookClientCertNames := flag.String("webhookClientCertNames", "", "comma separated common or DNS names of the accepted webhook client certificates; any if empty")
 	alertStoreSize := flag.Int("alertStoreSize", 10, "size of the alert store")
-	alertStoreType := flag.String("alertStoreType", "memory", "type of alert store (memory, memberlist)")
+	alertStoreType := flag.String("alertStoreType", "memory", "type of alert store (memory, memberlist, bolt)")
+	alertStorePath := flag.String("alertStorePath", "/var/lib/openfero/alerts.db", "database file of the bolt alert store, e.g. on a persistent volume")
+	alertStoreRetention := flag.Int("alertStoreRetention", 0, "hours the bolt alert store keeps alerts, in addition to alertStoreSize (0 keeps them until the size is reached)")
 	alertStoreClusterName := flag.String("alertStoreClusterName", "openfero", "Cluster name for memberlist alert store")
 
 	// Authentication flags
-	authMethod := flag.String("authMethod", "none", "authentication method for webhook endpoint (none, basic, bearer)")
+	authMethod := flag.String("authMethod", "none", "authentication method for webhook endpoint (none, basic, bearer, hmac)")
 	authBasicUser := flag.String("authBasicUser", "", "username for basic authentication")
 	authBasicPass := flag.String("authBasicPass", "", "password for basic authentication")
 	authBearerToken := flag.String("authBearerToken", "", "bearer token for token-based authentication")
+	authCredentialsFile := flag.String("authCredentialsFile", "", "YAML file of named basic auth, bearer or HMAC credentials, e.g. from a mounted Secret; reloaded when it changes")
+	authCredentialsReloadInterval := flag.Int("authCredentialsReloadInterval", int(handlers.DefaultCredentialsReloadInterval.Seconds()), "seconds between checks of the credentials file for changes")
+	oidcIssuerURL := flag.String("oidcIssuerURL", "", "OIDC issuer whose bearer tokens are required for the API and the UI; disabled if empty")
+	oidcJWKSURL := flag.String("oidcJWKSURL", "", "URL of the OIDC signing keys, instead of discovering them from the issuer")
+	oidcJWKSFile := flag.String("oidcJWKSFile", "", "file with a local JSON Web Key Set of the OIDC signing keys, instead of discovering them from the issuer")
+	oidcAudience := flag.String("oidcAudience", "", "audience OIDC tokens must be issued for; not checked if empty")
+	oidcRolesClaim := flag.String("oidcRolesClaim", handlers.DefaultOIDCRolesClaim, "OIDC claim holding the roles or groups of a user, nested claims separated by dots")
+	oidcUsernameClaim := flag.String("oidcUsernameClaim", handlers.DefaultOIDCUsernameClaim, "OIDC claim naming the user in audit logs, falling back to sub")
+	oidcViewerRoles := flag.String("oidcViewerRoles", "viewer", "comma separated values of the roles claim granting read access")
+	oidcOperatorRoles := flag.String("oidcOperatorRoles", "operator", "comma separated values of the roles claim granting job actions")
+	oidcAdminRoles := flag.String("oidcAdminRoles", "admin", "comma separated values of the roles claim granting full access")
+	authHMACSecret := flag.String("authHMACSecret", "", "shared secret for HMAC-SHA256 signature authentication")
+	authHMACSecretFile := flag.String("authHMACSecretFile", "", "file with the shared secret for HMAC-SHA256 signature authentication, e.g. from a mounted Secret, instead of authHMACSecret")
+	authHMACSignatureHeader := flag.String("authHMACSignatureHeader", handlers.DefaultHMACSignatureHeader, "header carrying the HMAC signature (hex or base64, optionally prefixed with sha256=)")
+	authHMACTimestampHeader := flag.String("authHMACTimestampHeader", handlers.DefaultHMACTimestampHeader, "header carrying the Unix time the request was signed at")
+	authHMACNonceHeader := flag.String("authHMACNonceHeader", handlers.DefaultHMACNonceHeader, "header carrying a unique nonce, used nonces are rejected")
+	authHMACTolerance := flag.Int("authHMACTolerance", int(handlers.DefaultHMACTolerance.Seconds()), "seconds the signed timestamp may differ from the current time")
+
+	// Job output flags
+	jobOutputLogBytes := flag.Int64("jobOutputLogBytes", 0, "number of trailing bytes of pod logs to attach to alerts once a job finishes (0 captures only the termination message)")
 
 	// Operarius CRD flags
 	operariusNamespace := flag.String("operariusNamespace", "", "Kubernetes namespace to watch for Operarius CRDs")
+	cronTriggers := flag.Bool("cronTriggers", true, "run Operarii with a cronTrigger on their schedule")
+
+	// Kubernetes event trigger flags
+	kubeEvents := flag.Bool("kubeEvents", false, "raise alerts for Kubernetes Warning events and pod and node conditions, e.g. CrashLoopBackOff or NodeNotReady")
+	kubeEventsNamespace := flag.String("kubeEventsNamespace", "", "namespace to watch for Kubernetes events, all namespaces (including nodes) if empty")
+	kubeEventsReasons := flag.String("kubeEventsReasons", strings.Join(kubernetes.DefaultEventReasons, ","), "comma separated reasons to raise alerts for")
+	kubeEventsCooldown := flag.Int("kubeEventsCooldown", 300, "minimum seconds between two alerts for the same object and reason")
+	kubeEventsRate := flag.Float64("kubeEventsRate", 30, "maximum number of alerts raised for Kubernetes events per minute (0 disables the limit)")
+	kubeEventsBurst := flag.Int("kubeEventsBurst", 10, "number of alerts for Kubernetes events that can be raised at once above the rate")
+
+	// Pull-mode ingestion flags
+	pollURL := flag.String("pollURL", "", "base URL of an Alertmanager or Prometheus to poll for active alerts, for when it can't reach the webhook")
+	pollAPI := flag.String("pollAPI", ingest.PollAlertmanager, "API to poll: alertmanager (/api/v2/alerts) or prometheus (/api/v1/alerts)")
+	pollInterval := flag.Int("pollInterval", 30, "seconds between two polls")
+	pollFilter := flag.String("pollFilter", "", "label matchers polled alerts must match, e.g. 'severity=~\"warning|critical\",team=\"ops\"'")
+	pollIncludeSuppressed := flag.Bool("pollIncludeSuppressed", false, "also process alerts Alertmanager silenced or inhibited")
+	pollBearerTokenFile := flag.String("pollBearerTokenFile", "", "file containing the bearer token to poll with, read on every poll")
+	pollUsername := flag.String("pollUsername", "", "basic auth username to poll with")
+	pollPasswordFile := flag.String("pollPasswordFile", "", "file containing the basic auth password to poll with")
+	pollCAFile := flag.String("pollCAFile", "", "CA certificate file to verify the polled server")
+	pollInsecureSkipVerify := flag.Bool("pollInsecureSkipVerify", false, "don't verify the certificate of the polled server")
+
+	// Webhook ingestion flags
+	webhookWorkers := flag.Int("webhookWorkers", 4, "number of workers processing webhook messages asynchronously (0 processes them on the request)")
+	webhookQueueSize := flag.Int("webhookQueueSize", 256, "number of webhook messages that can wait for a worker before requests are rejected with 429")
+	webhookEmptyResponse := flag.Bool("webhookEmptyResponse", false, "answer webhooks with an empty body instead of a JSON description of what was done (callers can override it with ?response=json|empty)")
+	webhookMaxBodyBytes := flag.Int64("webhookMaxBodyBytes", 10<<20, "maximum size of a webhook request body in bytes, larger ones are rejected with 413 (0 disables the limit)")
+	webhookMaxAlerts := flag.Int("webhookMaxAlerts", 1000, "maximum number of alerts in a webhook message, larger ones are rejected with 400 (0 disables the limit)")
+	webhookStrictDecoding := flag.Bool("webhookStrictDecoding", false, "reject Alertmanager webhooks with unknown fields or data after the message")
+	webhookIdempotencyTTL := flag.Int("webhookIdempotencyTTL", int(ingest.DefaultIdempotencyTTL.Seconds()), "seconds re-deliveries of a webhook message are acknowledged without processing it again, keep it below Alertmanager's repeat_interval (0 disables it)")
+	webhookRateLimit := flag.Float64("webhookRateLimit", 0, "webhook requests per second each source may make, more are rejected with 429 (0 disables the limit)")
+	webhookRateBurst := flag.Int("webhookRateBurst", 20, "number of webhook requests a source may make at once above the rate")
+	webhookRateLimitBy := flag.String("webhookRateLimitBy", handlers.RateLimitByRemote, "what webhook requests are rate limited by (remote: the remote address, credential: the credential, falling back to the remote address)")
+	genericWebhookMapping := flag.String("genericWebhookMapping", "", "YAML file mapping generic JSON webhooks to alerts, enables POST /alerts/generic")
+	cloudEventsSink := flag.String("cloudEventsSink", "", "URL to send lifecycle CloudEvents to (alert received, job created, succeeded, failed or deduplicated); empty disables them")
+	cloudEventsSource := flag.String("cloudEventsSource", "/openfero", "source attribute of emitted CloudEvents")
+	webhookWALPath := flag.String("webhookWALPath", "", "file to log accepted webhook messages to, so messages not processed before a restart are replayed (empty disables the log)")
+	shutdownTimeout := flag.Int("shutdownTimeout", 30, "seconds to wait for queued webhook messages on shutdown")
+
+	// Backtest flags
+	backtestFile := flag.String("backtest", "", "replay an NDJSON alert history export against the Operarii, print the result and exit")
+	backtestOperarii := flag.String("backtestOperarii", "", "YAML file or directory with Operarii to backtest instead of the Operarii in the cluster")
+	backtestIncludeDisabled := flag.Bool("backtestIncludeDisabled", false, "treat disabled Operarii as enabled when backtesting")
 
 	flag.Parse()
 
@@ -101,6 +295,20 @@ func main() {
 		log.Fatal("Could not set log configuration")
 	}
 
+	if *backtestFile != "" {
+		err := runBacktest(context.Background(), backtestConfig{
+			HistoryFile:        *backtestFile,
+			OperariiPath:       *backtestOperarii,
+			OperariusNamespace: *operariusNamespace,
+			Kubeconfig:         kubeconfig,
+			IncludeDisabled:    *backtestIncludeDisabled,
+		}, os.Stdout)
+		if err != nil {
+			log.Fatal("Backtest failed", "error", err)
+		}
+		return
+	}
+
 	log.Info("Starting OpenFero", "version", version, "commit", commit, "date", date)
 
 	// Initialize the appropriate alert store based on configuration
@@ -108,10 +316,27 @@ func main() {
 	switch *alertStoreType {
 	case "memberlist":
 		store = memberlist.NewMemberlistStore(*alertStoreClusterName, *alertStoreSize)
+	case "bolt":
+		store = bolt.NewBoltStore(*alertStorePath, *alertStoreSize, time.Duration(*alertStoreRetention)*time.Hour)
 	default:
 		store = memory.NewMemoryStore(*alertStoreSize)
 	}
 
+	// Remember accepted webhook deliveries, shared between the replicas of a memberlist cluster
+	var deliveries *ingest.DeliveryLog
+	if *webhookIdempotencyTTL > 0 {
+		deliveries = ingest.NewDeliveryLog(time.Duration(*webhookIdempotencyTTL) * time.Second)
+		if shared, ok := store.(*memberlist.MemberlistStore); ok {
+			shared.ShareDeliveries(deliveries)
+		}
+	}
+
+	// Remember the nonces of signed webhooks, shared like the deliveries
+	nonces := ingest.NewNonceLog()
+	if shared, ok := store.(*memberlist.MemberlistStore); ok && handlers.AuthMethod(*authMethod) == handlers.AuthMethodHMAC {
+		shared.ShareNonces(nonces)
+	}
+
 	// Initialize the alert store
 	if err := store.Initialize(); err != nil {
 		log.Fatal("Failed to initialize alert store", "error", err)
@@ -122,6 +347,12 @@ func main() {
 		}
 	}()
 
+	// Alerts that fired before a restart complete their lifecycles when they resolve
+	lifecycles := services.NewLifecycleTracker()
+	if err := lifecycles.Restore(store); err != nil {
+		log.Warn("Failed to restore alert lifecycles from the alert store", "error", err)
+	}
+
 	// Use the in-cluster config to create a kubernetes client
 	clientset := kubernetes.InitKubeClient(kubeconfig)
 
@@ -141,32 +372,136 @@ func main() {
 		Clientset: *clientset,
 	}
 
+	// Keep the HMAC secret out of the command line if it is read from a file
+	hmacSecret := *authHMACSecret
+	if *authHMACSecretFile != "" {
+		secret, err := os.ReadFile(*authHMACSecretFile) // #nosec G304 -- path is configured by the operator
+		if err != nil {
+			log.Fatal("Failed to read HMAC secret", "error", err)
+		}
+		hmacSecret = strings.TrimSpace(string(secret))
+	}
+
 	// Validate and create authentication configuration
 	authConfig := handlers.AuthConfig{
 		Method:      handlers.AuthMethod(*authMethod),
 		BasicUser:   *authBasicUser,
 		BasicPass:   *authBasicPass,
 		BearerToken: *authBearerToken,
+
+		HMACSecret:          hmacSecret,
+		HMACSignatureHeader: *authHMACSignatureHeader,
+		HMACTimestampHeader: *authHMACTimestampHeader,
+		HMACNonceHeader:     *authHMACNonceHeader,
+		HMACTolerance:       time.Duration(*authHMACTolerance) * time.Second,
+		HMACNonces:          nonces,
+
+		ClientCertRequired: *webhookClientCert,
+		ClientCertNames:    splitList(*webhookClientCertNames),
+	}
+
+	if *authCredentialsFile != "" {
+		credentials, err := handlers.LoadCredentials(*authCredentialsFile)
+		if err != nil {
+			log.Fatal("Failed to load credentials", "error", err)
+		}
+		credentials.Watch(context.Background(), time.Duration(*authCredentialsReloadInterval)*time.Second)
+		authConfig.Credentials = credentials
 	}
 
 	// Validate authentication configuration
 	if authErr := validateAuthConfig(authConfig); authErr != nil {
 		log.Fatal("Invalid authentication configuration", "error", authErr)
 	}
+	if authConfig.ClientCertRequired && *tlsClientCAFile == "" {
+		log.Fatal("Invalid authentication configuration", "error", "webhook client certificates require tlsClientCAFile")
+	}
 
 	// Log authentication configuration (without sensitive data)
 	if authConfig.Method != handlers.AuthMethodNone {
 		log.Info("Authentication enabled for webhook endpoint",
-			"method", string(authConfig.Method))
+			"method", string(authConfig.Method),
+			"credentials", len(authConfig.Credentials.List()))
 	} else {
 		log.Info("No authentication configured for webhook endpoint")
 	}
 
+	// OIDC protects the API and the UI
+	var apiAuth *handlers.OIDCAuthenticator
+	if *oidcIssuerURL != "" {
+		var err error
+		apiAuth, err = handlers.NewOIDCAuthenticator(context.Background(), handlers.OIDCConfig{
+			IssuerURL:     *oidcIssuerURL,
+			JWKSURL:       *oidcJWKSURL,
+			JWKSFile:      *oidcJWKSFile,
+			Audience:      *oidcAudience,
+			RolesClaim:    *oidcRolesClaim,
+			UsernameClaim: *oidcUsernameClaim,
+			ViewerRoles:   splitList(*oidcViewerRoles),
+			OperatorRoles: splitList(*oidcOperatorRoles),
+			AdminRoles:    splitList(*oidcAdminRoles),
+		})
+		if err != nil {
+			log.Fatal("Failed to set up OIDC authentication", "error", err)
+		}
+		if *oidcAudience == "" {
+			log.Warn("OIDC audience not configured, tokens issued for any client are accepted")
+		}
+		log.Info("OIDC authentication enabled for API and UI", "issuer", *oidcIssuerURL)
+	} else {
+		log.Info("No OIDC configured, API and UI are readable without authentication")
+	}
+
 	// Initialize HTTP server
 	server := &handlers.Server{
 		KubeClient: kubeClient,
 		AlertStore: store,
 		AuthConfig: authConfig,
+		Lifecycles: lifecycles,
+
+		EmptyWebhookResponse:  *webhookEmptyResponse,
+		WebhookMaxBodyBytes:   *webhookMaxBodyBytes,
+		WebhookMaxAlerts:      *webhookMaxAlerts,
+		StrictWebhookDecoding: *webhookStrictDecoding,
+		Deliveries:            deliveries,
+	}
+	if *webhookRateLimit > 0 {
+		limiter, err := handlers.NewRateLimiter(handlers.RateLimitConfig{
+			Rate:  *webhookRateLimit,
+			Burst: *webhookRateBurst,
+			By:    *webhookRateLimitBy,
+		})
+		if err != nil {
+			log.Fatal("Invalid webhook rate limit", "error", err)
+		}
+		server.WebhookRateLimiter = limiter
+		log.Info("Webhook rate limit enabled",
+			"rate", *webhookRateLimit,
+			"burst", *webhookRateBurst,
+			"by", *webhookRateLimitBy)
+	}
+
+	// Register the ingestion adapters for alert sources other than Alertmanager
+	server.Adapters = ingest.Adapters{}
+	server.Adapters.Register(ingest.GrafanaAdapter{})
+	server.Adapters.Register(ingest.CloudEventsAdapter{})
+	if *genericWebhookMapping != "" {
+		mapping, err := ingest.LoadGenericMapping(*genericWebhookMapping)
+		if err != nil {
+			log.Fatal("Failed to load generic webhook mapping", "error", err)
+		}
+		adapter, err := ingest.NewGenericAdapter(mapping)
+		if err != nil {
+			log.Fatal("Invalid generic webhook mapping", "path", *genericWebhookMapping, "error", err)
+		}
+		server.Adapters.Register(adapter)
+	}
+	log.Info("Ingestion adapters registered", "adapters", server.Adapters.Names())
+
+	// Emit lifecycle events if a sink is configured
+	if *cloudEventsSink != "" {
+		server.Events = cloudevents.NewEmitter(*cloudEventsSink, *cloudEventsSource, 1024)
+		log.Info("Emitting CloudEvents", "sink", *cloudEventsSink, "source", *cloudEventsSource)
 	}
 
 	// Initialize Operarius CRD support
@@ -193,6 +528,24 @@ func main() {
 
 	log.Info("Operarius CRD support initialized successfully")
 
+	// Process webhook messages in a worker pool unless disabled
+	if *webhookWorkers > 0 {
+		server.WebhookQueue = ingest.NewQueue(*webhookQueueSize, *webhookWorkers, server.ProcessHookMessage)
+		if *webhookWALPath != "" {
+			wal, err := ingest.OpenWAL(*webhookWALPath)
+			if err != nil {
+				log.Fatal("Failed to open webhook WAL", "path", *webhookWALPath, "error", err)
+			}
+			defer func() {
+				if err := wal.Close(); err != nil {
+					log.Error("Failed to close webhook WAL", "error", err)
+				}
+			}()
+			server.WebhookQueue.SetWAL(wal)
+		}
+		server.WebhookQueue.Start(context.Background())
+	}
+
 	// Determine job label selector
 	// In Operarius mode, we watch for jobs managed by OpenFero (labeled with openfero.io/managed-by=openfero)
 	jobSelector := &metav1.LabelSelector{
@@ -226,9 +579,112 @@ func main() {
 						"error", err)
 				}
 			}
+
+			// Capture the output once the job has finished. Jobs that were
+			// already finished when the informer started are skipped.
+			if oldJob != nil && !kubernetes.IsJobFinished(oldJob) && kubernetes.IsJobFinished(newJob) {
+				services.RecordJobCompletion(store, server.Lifecycles, newJob)
+
+				eventType := cloudevents.TypeJobSucceeded
+				if kubernetes.IsJobFailed(newJob) {
+					eventType = cloudevents.TypeJobFailed
+				}
+				server.Events.Emit(eventType, newJob.Namespace+"/"+newJob.Name, cloudevents.JobData{
+					Operarius:  newJob.Labels["openfero.io/operarius"],
+					Namespace:  newJob.Namespace,
+					JobName:    newJob.Name,
+					DeliveryID: newJob.Labels[services.DeliveryIDLabel],
+				})
+
+				go func(job *batchv1.Job) {
+					output, err := operariusService.CaptureJobOutput(context.Background(), job, *jobOutputLogBytes)
+					if err != nil {
+						log.Warn("Failed to capture job output",
+							"job", job.Name,
+							"error", err)
+						return
+					}
+					services.UpdateAlertJobOutput(store, job.Namespace, job.Name, output)
+				}(newJob)
+			}
+		}
+	})
+
+	// Initialize Pod informer to capture why remediation jobs fail
+	// (e.g. ImagePullBackOff, OOMKilled or a non-zero exit code)
+	kubernetes.InitPodInformer(clientset, *operariusNamespace, jobSelector, func(pod *corev1.Pod) {
+		operariusName, ok := pod.Labels["openfero.io/operarius"]
+		if !ok {
+			return
+		}
+		jobName := kubernetes.JobNameForPod(pod)
+		failure := services.DiagnosePodFailure(pod)
+		if jobName == "" || failure == nil {
+			return
+		}
+
+		ctx := context.Background()
+		operarius, err := operariusService.GetOperarius(ctx, operariusName, pod.Namespace)
+		if err != nil {
+			log.Error("Failed to get Operarius for pod failure",
+				"operarius", operariusName,
+				"pod", pod.Name,
+				"error", err)
+			return
 		}
+
+		if err := operariusService.RecordJobFailure(ctx, operarius, jobName, failure); err != nil {
+			log.Error("Failed to record job failure in Operarius status",
+				"operarius", operariusName,
+				"job", jobName,
+				"error", err)
+		}
+		services.UpdateAlertJobFailure(store, pod.Namespace, jobName, services.ToAlertStoreJobFailure(failure))
 	})
 
+	// Turn Kubernetes events into alerts if enabled
+	if *kubeEvents {
+		watcher := kubernetes.NewEventWatcher(clientset, kubernetes.EventWatcherConfig{
+			Namespace:     *kubeEventsNamespace,
+			Reasons:       splitList(*kubeEventsReasons),
+			Cooldown:      time.Duration(*kubeEventsCooldown) * time.Second,
+			RatePerMinute: *kubeEventsRate,
+			Burst:         *kubeEventsBurst,
+		}, server.SubmitHookMessage)
+		if err := watcher.Start(context.Background()); err != nil {
+			log.Fatal("Failed to start Kubernetes event watcher", "error", err)
+		}
+	}
+
+	// Poll for active alerts if configured
+	if *pollURL != "" {
+		matchers, err := alertstore.ParseMatchers(*pollFilter)
+		if err != nil {
+			log.Fatal("Invalid poll filter", "error", err)
+		}
+		poller, err := ingest.NewPoller(ingest.PollerConfig{
+			URL:                *pollURL,
+			API:                *pollAPI,
+			Interval:           time.Duration(*pollInterval) * time.Second,
+			Matchers:           matchers,
+			IncludeSuppressed:  *pollIncludeSuppressed,
+			BearerTokenFile:    *pollBearerTokenFile,
+			Username:           *pollUsername,
+			PasswordFile:       *pollPasswordFile,
+			CAFile:             *pollCAFile,
+			InsecureSkipVerify: *pollInsecureSkipVerify,
+		}, server.SubmitHookMessage)
+		if err != nil {
+			log.Fatal("Invalid alert poller configuration", "error", err)
+		}
+		poller.Start(context.Background())
+	}
+
+	// Run Operarii on their cron schedules
+	if *cronTriggers {
+		services.NewCronScheduler(operariusService, server.RunScheduledOperarius).Start(context.Background())
+	}
+
 	// Mark startup as complete after all informer caches are synced
 	server.StartupComplete.Store(true)
 	log.Info("Startup complete, all caches synced")
@@ -248,6 +704,11 @@ func main() {
 		wsHub.Broadcast("operarius_update", jobInfo)
 	})
 
+	// Let WebSocket clients subscribe to the live logs of remediation jobs
+	wsHub.SetLogStreamer(func(ctx context.Context, namespace, name string) (io.ReadCloser, error) {
+		return operariusService.StreamJobLogs(ctx, namespace, name, "", true)
+	})
+
 	// Register metrics and set prometheus handler
 	metadata.AddMetricsToPrometheusRegistry()
 	http.HandleFunc("GET "+metadata.MetricsPath, func(w http.ResponseWriter, r *http.Request) {
@@ -256,37 +717,7 @@ func main() {
 
 	// Register HTTP routes
 	log.Info("Starting webhook receiver")
-	http.HandleFunc("GET /healthz", server.HealthzGetHandler)
-	http.HandleFunc("GET /readiness", server.ReadinessGetHandler)
-	http.HandleFunc("GET /startupz", server.StartupzGetHandler)
-	http.HandleFunc("GET /alertStore", func(w http.ResponseWriter, r *http.Request) {
-		log.Warn("Deprecated: /alertStore endpoint is deprecated, use /api/alerts instead",
-			"remoteAddr", r.RemoteAddr)
-		server.AlertStoreGetHandler(w, r)
-	})
-	http.HandleFunc("GET /alerts", server.AlertsGetHandler)
-
-	// Apply authentication middleware to the webhook endpoint
-	authMiddleware := handlers.AuthMiddleware(authConfig)
-	http.HandleFunc("POST /alerts", authMiddleware(server.AlertsPostHandler))
-
-	// API routes (JSON)
-	http.HandleFunc("GET /api/jobs", server.JobsAPIHandler)
-	http.HandleFunc("GET /api/alerts", server.AlertStoreGetHandler)
-	http.HandleFunc("GET /api/about", handlers.AboutAPIHandler)
-	http.HandleFunc("GET /api/ws", handlers.WebSocketHandler) // WebSocket for real-time updates
-
-	// Swagger documentation
-	http.Handle("GET /swagger/", httpSwagger.Handler(
-		httpSwagger.DeepLinking(true),
-		httpSwagger.DocExpansion("none"),
-		httpSwagger.DomID("swagger-ui"),
-	))
-
-	// Vue.js SPA - serve frontend for all unmatched routes
-	// This must be registered last as a catch-all
-	frontendHandler := handlers.NewFrontendHandler(frontendFS)
-	http.Handle("GET /", frontendHandler)
+	registerRoutes(http.DefaultServeMux, server, handlers.NewFrontendHandler(frontendFS), apiAuth)
 
 	// Create and start HTTP server
 	srv := &http.Server{
@@ -294,9 +725,65 @@ func main() {
 		ReadTimeout:  time.Duration(*readTimeout) * time.Second,
 		WriteTimeout: time.Duration(*writeTimeout) * time.Second,
 	}
+	if *tlsCertFile != "" || *tlsKeyFile != "" {
+		reloader, err := tlsconfig.NewReloader(tlsconfig.Config{
+			CertFile:     *tlsCertFile,
+			KeyFile:      *tlsKeyFile,
+			ClientCAFile: *tlsClientCAFile,
+			MinVersion:   *tlsMinVersion,
+			CipherSuites: splitList(*tlsCipherSuites),
+		})
+		if err != nil {
+			log.Fatal("Invalid TLS configuration", "error", err)
+		}
+		reloader.Watch(context.Background(), time.Duration(*tlsReloadInterval)*time.Second)
+		srv.TLSConfig = reloader.ServerConfig()
+	} else if *tlsClientCAFile != "" {
+		log.Fatal("Invalid TLS configuration", "error", "tlsClientCAFile requires tlsCertFile and tlsKeyFile")
+	}
+
+	// Stop accepting requests on SIGTERM and finish the queued webhook messages
+	stopCtx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-stopCtx.Done()
+		log.Info("Shutting down server")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Duration(*shutdownTimeout)*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			log.Error("Failed to shut down server", "error", err)
+		}
+	}()
 
-	log.Info("Starting server on " + *addr)
-	if err := srv.ListenAndServe(); err != nil {
+	// Replay webhook messages accepted but not processed before the last shutdown
+	if server.WebhookQueue != nil {
+		go func() {
+			if err := server.WebhookQueue.Replay(stopCtx); err != nil {
+				log.Error("Failed to replay webhook messages", "error", err)
+			}
+		}()
+	}
+
+	if srv.TLSConfig != nil {
+		log.Info("Starting server with TLS on "+*addr, "clientCA", *tlsClientCAFile != "", "minVersion", *tlsMinVersion)
+		// The certificates come from the TLS config, so they can be reloaded
+		err = srv.ListenAndServeTLS("", "")
+	} else {
+		log.Info("Starting server on " + *addr)
+		err = srv.ListenAndServe()
+	}
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
 		log.Fatal("error starting server", "error", err)
 	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Duration(*shutdownTimeout)*time.Second)
+	defer cancel()
+	if server.WebhookQueue != nil {
+		if err := server.WebhookQueue.Shutdown(shutdownCtx); err != nil {
+			log.Error("Failed to process queued webhook messages", "error", err)
+		}
+	}
+	if err := server.Events.Close(shutdownCtx); err != nil {
+		log.Error("Failed to send pending CloudEvents", "error", err)
+	}
 }
@@ -1,11 +1,21 @@
 package alertstore
 
 import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strings"
 	"time"
+
+	"github.com/prometheus/common/model"
 )
 
+// ErrAlertNotFound is returned by GetAlert when no entry with the given ID is stored
+var ErrAlertNotFound = errors.New("alert not found")
+
 // AlertEntry represents a single alert in the store
 type AlertEntry struct {
+	ID        string    `json:"id,omitempty"` // Assigned by the store when the alert is saved
 	Alert     Alert     `json:"alert"`
 	Status    string    `json:"status"`
 	Timestamp time.Time `json:"timestamp"`
@@ -14,22 +24,58 @@ type AlertEntry struct {
 
 // Alert contains the alert information from Alertmanager
 type Alert struct {
-	Labels      map[string]string `json:"labels"`
-	Annotations map[string]string `json:"annotations"`
-	StartsAt    string            `json:"startsAt,omitempty"`
-	EndsAt      string            `json:"endsAt,omitempty"`
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations"`
+	StartsAt     string            `json:"startsAt,omitempty"`
+	EndsAt       string            `json:"endsAt,omitempty"`
+	Status       string            `json:"status,omitempty"`       // Status of this alert, may differ from the group status
+	GeneratorURL string            `json:"generatorURL,omitempty"` // Link to the rule that generated the alert
+	Fingerprint  string            `json:"fingerprint,omitempty"`  // Stable identity of the alert across firing and resolved
+	Credential   string            `json:"credential,omitempty"`   // Label of the webhook credential the alert was received with
+}
+
+// Fingerprint computes an alert fingerprint from its labels the way
+// Alertmanager does, for alerts received without one
+func Fingerprint(labels map[string]string) string {
+	set := make(model.LabelSet, len(labels))
+	for name, value := range labels {
+		set[model.LabelName(name)] = model.LabelValue(value)
+	}
+	return set.Fingerprint().String()
 }
 
 // JobInfo contains information about a triggered job
 type JobInfo struct {
-	OperariusName       string     `json:"operariusName,omitempty"`
-	JobName             string     `json:"jobName,omitempty"`
-	Namespace           string     `json:"namespace,omitempty"`
-	Image               string     `json:"image,omitempty"`
-	ExecutionCount      int32      `json:"executionCount,omitempty"`
-	LastExecutionTime   *time.Time `json:"lastExecutionTime,omitempty"`
-	LastExecutedJobName string     `json:"lastExecutedJobName,omitempty"`
-	LastExecutionStatus string     `json:"status,omitempty"`
+	OperariusName       string      `json:"operariusName,omitempty"`
+	JobName             string      `json:"jobName,omitempty"`
+	Namespace           string      `json:"namespace,omitempty"`
+	Image               string      `json:"image,omitempty"`
+	ExecutionCount      int32       `json:"executionCount,omitempty"`
+	LastExecutionTime   *time.Time  `json:"lastExecutionTime,omitempty"`
+	LastExecutedJobName string      `json:"lastExecutedJobName,omitempty"`
+	LastExecutionStatus string      `json:"status,omitempty"`
+	Failure             *JobFailure `json:"failure,omitempty"`    // Why the job's pod failed (if it did)
+	Output              *JobOutput  `json:"output,omitempty"`     // What the job reported once it finished
+	StartedAt           *time.Time  `json:"startedAt,omitempty"`  // When the job started running
+	FinishedAt          *time.Time  `json:"finishedAt,omitempty"` // When the job completed or failed for good
+}
+
+// JobOutput contains the output captured from a finished job
+type JobOutput struct {
+	PodName            string    `json:"podName,omitempty"`
+	TerminationMessage string    `json:"terminationMessage,omitempty"`
+	Logs               string    `json:"logs,omitempty"`          // Tail of the pod logs, bounded in size
+	LogsTruncated      bool      `json:"logsTruncated,omitempty"` // Whether earlier log output was dropped
+	CapturedAt         time.Time `json:"capturedAt"`
+}
+
+// JobFailure contains pod-level diagnostics for a failed job
+type JobFailure struct {
+	PodName       string `json:"podName,omitempty"`
+	ContainerName string `json:"containerName,omitempty"`
+	Reason        string `json:"reason"`
+	ExitCode      *int32 `json:"exitCode,omitempty"`
+	Message       string `json:"message,omitempty"`
 }
 
 // Store defines the interface for alert storage implementations
@@ -43,9 +89,84 @@ type Store interface {
 	// GetAlerts retrieves alerts, optionally filtered by query
 	GetAlerts(query string, limit int) ([]AlertEntry, error)
 
+	// QueryAlerts selects alerts by structured filters, one page at a time.
+	// It returns ErrInvalidCursor for a cursor it didn't issue.
+	QueryAlerts(query Query) (QueryResult, error)
+
+	// GetAlert retrieves a single alert by its ID, returning ErrAlertNotFound
+	// if it is not (or no longer) stored
+	GetAlert(id string) (*AlertEntry, error)
+
+	// UpdateJobInfo applies update to the job information of every stored
+	// alert that triggered the given job. update reports whether it changed
+	// anything, so unchanged entries are not rewritten or re-broadcast.
+	UpdateJobInfo(namespace, jobName string, update func(jobInfo *JobInfo) bool) error
+
 	// Initialize prepares the store for use
 	Initialize() error
 
 	// Close cleans up any resources
 	Close() error
 }
+
+// NewEntryID returns a random ID for a new alert entry
+func NewEntryID() string {
+	b := make([]byte, 8)
+	// crypto/rand.Read never returns an error
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// MatchesQuery reports whether the alertname, status, a label or annotation
+// value, the fingerprint, the credential or the job of an entry contains the
+// free text query, ignoring case
+func MatchesQuery(entry AlertEntry, query string) bool {
+	query = strings.ToLower(query)
+
+	// Check alertname
+	if alertname, ok := entry.Alert.Labels["alertname"]; ok {
+		if strings.Contains(strings.ToLower(alertname), query) {
+			return true
+		}
+	}
+
+	// Check status
+	if strings.Contains(strings.ToLower(entry.Status), query) {
+		return true
+	}
+
+	// Check labels
+	for _, value := range entry.Alert.Labels {
+		if strings.Contains(strings.ToLower(value), query) {
+			return true
+		}
+	}
+
+	// Check annotations
+	for _, value := range entry.Alert.Annotations {
+		if strings.Contains(strings.ToLower(value), query) {
+			return true
+		}
+	}
+
+	// Check fingerprint
+	if entry.Alert.Fingerprint != "" && strings.Contains(entry.Alert.Fingerprint, query) {
+		return true
+	}
+
+	// Check the label of the credential the alert was received with
+	if entry.Alert.Credential != "" && strings.Contains(strings.ToLower(entry.Alert.Credential), query) {
+		return true
+	}
+
+	// Check job info if present
+	if entry.JobInfo != nil {
+		if strings.Contains(strings.ToLower(entry.JobInfo.OperariusName), query) ||
+			strings.Contains(strings.ToLower(entry.JobInfo.JobName), query) ||
+			strings.Contains(strings.ToLower(entry.JobInfo.Image), query) {
+			return true
+		}
+	}
+
+	return false
+}
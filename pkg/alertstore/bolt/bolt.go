@@ -0,0 +1,585 @@
+// Package bolt implements a file-backed alert store on an embedded bbolt
+// database, so the alert history survives restarts when it is kept on a
+// persistent volume.
+package bolt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.etcd.io/bbolt"
+
+	"github.com/OpenFero/openfero/pkg/alertstore"
+	log "github.com/OpenFero/openfero/pkg/logging"
+)
+
+// Buckets of the database. Entries are keyed by a sequence number, so their
+// keys sort in the order they were saved. The index buckets map a value, the
+// time the entry was stored and its sequence number to nothing, so a prefix
+// scan finds the entries of a value in chronological order.
+var (
+	entriesBucket  = []byte("entries")
+	idsBucket      = []byte("ids")           // entry ID -> sequence
+	alertnameIndex = []byte("idx_alertname") // alertname \x00 timestamp sequence
+	statusIndex    = []byte("idx_status")    // status \x00 timestamp sequence
+	timestampIndex = []byte("idx_timestamp") // timestamp sequence
+	jobIndex       = []byte("idx_job")       // namespace/job \x00 sequence
+	metaBucket     = []byte("meta")
+	countKey       = []byte("count")
+	schemaKey      = []byte("schema")
+	indexBuckets   = [][]byte{idsBucket, alertnameIndex, statusIndex, timestampIndex, jobIndex}
+	allBuckets     = append([][]byte{entriesBucket, metaBucket}, indexBuckets...)
+)
+
+// schemaVersion is the version of the layout of the index keys, stored in
+// the meta bucket. Databases written with an older layout have their
+// indexes rebuilt when they are opened. Version 1 keyed the alertname and
+// status indexes by the value and the sequence only, and wasn't stored.
+const schemaVersion = 2
+
+const (
+	// openTimeout is how long Initialize waits for the lock of the database file
+	openTimeout = 10 * time.Second
+	// pruneInterval is how often alerts older than the retention are deleted
+	pruneInterval = time.Minute
+)
+
+// BoltStore implements alertstore.Store on a bbolt database file. Every
+// write is a transaction synced to disk before it returns, so the file stays
+// consistent when OpenFero crashes.
+type BoltStore struct {
+	path       string
+	maxEntries int
+	retention  time.Duration
+
+	db   *bbolt.DB
+	stop chan struct{}
+	done sync.WaitGroup
+}
+
+// NewBoltStore creates a store keeping at most maxEntries alerts in the file
+// at path. With a retention, older alerts are deleted as well.
+func NewBoltStore(path string, maxEntries int, retention time.Duration) *BoltStore {
+	return &BoltStore{path: path, maxEntries: maxEntries, retention: retention}
+}
+
+// Initialize opens the database, creating it if needed, and starts deleting
+// alerts older than the retention
+func (s *BoltStore) Initialize() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o750); err != nil {
+		return fmt.Errorf("failed to create alert store directory: %w", err)
+	}
+	// The file is locked; fail instead of waiting forever if another
+	// process, e.g. the previous pod still terminating, holds it
+	db, err := bbolt.Open(s.path, 0o600, &bbolt.Options{Timeout: openTimeout})
+	if err != nil {
+		return fmt.Errorf("failed to open alert store %s: %w", s.path, err)
+	}
+	err = db.Update(func(tx *bbolt.Tx) error {
+		for _, name := range allBuckets {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return migrate(tx)
+	})
+	if err != nil {
+		_ = db.Close()
+		return fmt.Errorf("failed to initialize alert store %s: %w", s.path, err)
+	}
+	s.db = db
+
+	// Apply a lowered limit or retention right away
+	if err := s.prune(time.Now()); err != nil {
+		log.Error("Failed to apply alert store retention", "error", err)
+	}
+
+	s.stop = make(chan struct{})
+	if s.retention > 0 {
+		s.done.Add(1)
+		go s.pruneLoop()
+	}
+
+	log.Info("Bolt alert store initialized",
+		"path", s.path,
+		"maxEntries", s.maxEntries,
+		"retention", s.retention.String(),
+		"entries", s.count())
+	return nil
+}
+
+// migrate rebuilds the indexes of a database written with an older schema
+// version and records the current one
+func migrate(tx *bbolt.Tx) error {
+	version := readSchemaVersion(tx)
+	switch {
+	case version == schemaVersion:
+		return nil
+	case version > schemaVersion:
+		return fmt.Errorf("alert store has schema version %d, this version of OpenFero supports up to %d", version, schemaVersion)
+	}
+
+	entries := tx.Bucket(entriesBucket)
+	if readCount(tx) > 0 {
+		log.Info("Rebuilding alert store indexes",
+			"fromVersion", version,
+			"toVersion", schemaVersion)
+	}
+	for _, name := range indexBuckets {
+		if err := tx.DeleteBucket(name); err != nil {
+			return err
+		}
+		if _, err := tx.CreateBucket(name); err != nil {
+			return err
+		}
+	}
+	err := entries.ForEach(func(key, data []byte) error {
+		var entry alertstore.AlertEntry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			return fmt.Errorf("failed to unmarshal alert: %w", err)
+		}
+		return index(tx, entry, bytes.Clone(key), true)
+	})
+	if err != nil {
+		return err
+	}
+
+	value := make([]byte, 8)
+	binary.BigEndian.PutUint64(value, schemaVersion)
+	return tx.Bucket(metaBucket).Put(schemaKey, value)
+}
+
+// readSchemaVersion returns the schema version of the database, 1 if it
+// predates storing it
+func readSchemaVersion(tx *bbolt.Tx) uint64 {
+	value := tx.Bucket(metaBucket).Get(schemaKey)
+	if len(value) != 8 {
+		return 1
+	}
+	return binary.BigEndian.Uint64(value)
+}
+
+// Close stops the retention and closes the database. Closing it again does nothing.
+func (s *BoltStore) Close() error {
+	if s.db == nil {
+		return nil
+	}
+	close(s.stop)
+	s.done.Wait()
+	db := s.db
+	s.db = nil
+	return db.Close()
+}
+
+// SaveAlert saves an alert to the store
+func (s *BoltStore) SaveAlert(alert alertstore.Alert, status string) error {
+	return s.SaveAlertWithJobInfo(alert, status, nil)
+}
+
+// SaveAlertWithJobInfo saves an alert with job information to the store,
+// deleting the oldest alerts beyond the limit in the same transaction
+func (s *BoltStore) SaveAlertWithJobInfo(alert alertstore.Alert, status string, jobInfo *alertstore.JobInfo) error {
+	entry := alertstore.AlertEntry{
+		ID:        alertstore.NewEntryID(),
+		Alert:     alert,
+		Status:    status,
+		Timestamp: time.Now(),
+		JobInfo:   jobInfo,
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal alert: %w", err)
+	}
+
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		entries := tx.Bucket(entriesBucket)
+		seq, err := entries.NextSequence()
+		if err != nil {
+			return err
+		}
+		key := sequenceKey(seq)
+		if err := entries.Put(key, data); err != nil {
+			return err
+		}
+		if err := index(tx, entry, key, true); err != nil {
+			return err
+		}
+		count := addCount(tx, 1)
+
+		for ; s.maxEntries > 0 && count > s.maxEntries; count-- {
+			oldest, data := entries.Cursor().First()
+			if oldest == nil {
+				break
+			}
+			if err := deleteEntry(tx, bytes.Clone(oldest), data); err != nil {
+				return err
+			}
+			addCount(tx, -1)
+		}
+		return nil
+	})
+}
+
+// GetAlerts retrieves alerts, newest first, optionally filtered by a free
+// text query
+func (s *BoltStore) GetAlerts(query string, limit int) ([]alertstore.AlertEntry, error) {
+	results := []alertstore.AlertEntry{}
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		cursor := tx.Bucket(entriesBucket).Cursor()
+		for key, data := cursor.Last(); key != nil; key, data = cursor.Prev() {
+			var entry alertstore.AlertEntry
+			if err := json.Unmarshal(data, &entry); err != nil {
+				return fmt.Errorf("failed to unmarshal alert: %w", err)
+			}
+			if query != "" && !alertstore.MatchesQuery(entry, query) {
+				continue
+			}
+			results = append(results, entry)
+			if limit > 0 && len(results) >= limit {
+				break
+			}
+		}
+		return nil
+	})
+	return results, err
+}
+
+// GetAlert retrieves a single alert by its ID
+func (s *BoltStore) GetAlert(id string) (*alertstore.AlertEntry, error) {
+	var entry *alertstore.AlertEntry
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		key := tx.Bucket(idsBucket).Get([]byte(id))
+		if key == nil {
+			return alertstore.ErrAlertNotFound
+		}
+		data := tx.Bucket(entriesBucket).Get(key)
+		if data == nil {
+			return alertstore.ErrAlertNotFound
+		}
+		entry = &alertstore.AlertEntry{}
+		return json.Unmarshal(data, entry)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// UpdateJobInfo applies update to the job information of all alerts that
+// triggered the given job, found by the job index
+func (s *BoltStore) UpdateJobInfo(namespace, jobName string, update func(jobInfo *alertstore.JobInfo) bool) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		entries := tx.Bucket(entriesBucket)
+		prefix := indexPrefix(namespace + "/" + jobName)
+		cursor := tx.Bucket(jobIndex).Cursor()
+		for indexKey, _ := cursor.Seek(prefix); indexKey != nil && bytes.HasPrefix(indexKey, prefix); indexKey, _ = cursor.Next() {
+			key := bytes.Clone(indexKey[len(prefix):])
+			data := entries.Get(key)
+			if data == nil {
+				continue
+			}
+			var entry alertstore.AlertEntry
+			if err := json.Unmarshal(data, &entry); err != nil {
+				return fmt.Errorf("failed to unmarshal alert: %w", err)
+			}
+			if entry.JobInfo == nil || !update(entry.JobInfo) {
+				continue
+			}
+			updated, err := json.Marshal(entry)
+			if err != nil {
+				return fmt.Errorf("failed to marshal alert: %w", err)
+			}
+			if err := entries.Put(key, updated); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// QueryAlerts selects alerts by structured filters. An equality matcher on
+// alertname or the status narrow the scan down to their index, the time
+// range and the cursor are seeks in it.
+func (s *BoltStore) QueryAlerts(query alertstore.Query) (alertstore.QueryResult, error) {
+	if err := query.Validate(); err != nil {
+		return alertstore.QueryResult{}, err
+	}
+	var position []byte
+	if query.Cursor != "" {
+		cursor, err := alertstore.DecodeCursor(query.Cursor)
+		if err != nil {
+			return alertstore.QueryResult{}, err
+		}
+		seq, err := strconv.ParseUint(cursor.Key, 10, 64)
+		if err != nil {
+			return alertstore.QueryResult{}, alertstore.ErrInvalidCursor
+		}
+		position = timestampKey(cursor.Timestamp, sequenceKey(seq))
+	}
+
+	bucket, prefix := timestampIndex, []byte{}
+	if alertname, ok := equalityMatcher(query.Matchers, "alertname"); ok {
+		bucket, prefix = alertnameIndex, indexPrefix(alertname)
+	} else if query.Status != "" {
+		bucket, prefix = statusIndex, indexPrefix(strings.ToLower(query.Status))
+	}
+	// The bounds of the time range within the prefix, nil if unbounded
+	var lower, upper []byte
+	if !query.From.IsZero() {
+		lower = append(bytes.Clone(prefix), timestampKey(query.From, nil)...)
+	}
+	if !query.To.IsZero() {
+		upper = append(bytes.Clone(prefix), timestampKey(query.To, nil)...)
+	}
+	if position != nil {
+		position = append(bytes.Clone(prefix), position...)
+	}
+
+	result := alertstore.QueryResult{Entries: []alertstore.AlertEntry{}}
+	var lastSeq uint64
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		entries := tx.Bucket(entriesBucket)
+		cursor := tx.Bucket(bucket).Cursor()
+
+		var indexKey []byte
+		var next func() ([]byte, []byte)
+		inRange := func(key []byte) bool {
+			return key != nil && bytes.HasPrefix(key, prefix) &&
+				(lower == nil || bytes.Compare(key, lower) >= 0) &&
+				(upper == nil || bytes.Compare(key, upper) < 0)
+		}
+		if query.Descending() {
+			// Start before the exclusive upper bound: the end of the range,
+			// the end of the prefix or the cursor, whichever comes first
+			bound := upper
+			if bound == nil && len(prefix) > 0 {
+				bound = prefixEnd(prefix)
+			}
+			if position != nil && (bound == nil || bytes.Compare(position, bound) < 0) {
+				bound = position
+			}
+			if bound == nil {
+				indexKey, _ = cursor.Last()
+			} else if key, _ := cursor.Seek(bound); key == nil {
+				indexKey, _ = cursor.Last()
+			} else {
+				indexKey, _ = cursor.Prev()
+			}
+			next = cursor.Prev
+		} else {
+			start := prefix
+			if lower != nil {
+				start = lower
+			}
+			if position != nil && bytes.Compare(position, start) >= 0 {
+				start = position
+			}
+			indexKey, _ = cursor.Seek(start)
+			if position != nil && bytes.Equal(indexKey, position) {
+				indexKey, _ = cursor.Next()
+			}
+			next = cursor.Next
+		}
+
+		for ; inRange(indexKey); indexKey, _ = next() {
+			key := indexKey[len(indexKey)-8:]
+			data := entries.Get(key)
+			if data == nil {
+				continue
+			}
+			var entry alertstore.AlertEntry
+			if err := json.Unmarshal(data, &entry); err != nil {
+				return fmt.Errorf("failed to unmarshal alert: %w", err)
+			}
+			if !query.Matches(entry) {
+				continue
+			}
+			if query.Limit > 0 && len(result.Entries) == query.Limit {
+				last := result.Entries[len(result.Entries)-1]
+				result.NextCursor = alertstore.EncodeCursor(alertstore.CursorPosition{
+					Timestamp: last.Timestamp,
+					Key:       strconv.FormatUint(lastSeq, 10),
+				})
+				break
+			}
+			result.Entries = append(result.Entries, entry)
+			lastSeq = binary.BigEndian.Uint64(key)
+		}
+		return nil
+	})
+	return result, err
+}
+
+// equalityMatcher returns the value of an equality matcher on a label
+func equalityMatcher(matchers []*alertstore.Matcher, name string) (string, bool) {
+	for _, m := range matchers {
+		if m.Name == name && m.Type == alertstore.MatchEqual {
+			return m.Value, true
+		}
+	}
+	return "", false
+}
+
+// pruneLoop deletes alerts older than the retention until the store is closed
+func (s *BoltStore) pruneLoop() {
+	defer s.done.Done()
+	ticker := time.NewTicker(pruneInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case now := <-ticker.C:
+			if err := s.prune(now); err != nil {
+				log.Error("Failed to apply alert store retention", "error", err)
+			}
+		}
+	}
+}
+
+// prune deletes the alerts beyond the limit and those older than the retention
+func (s *BoltStore) prune(now time.Time) error {
+	deleted := 0
+	err := s.db.Update(func(tx *bbolt.Tx) error {
+		entries := tx.Bucket(entriesBucket)
+		var keys [][]byte
+
+		count := addCount(tx, 0)
+		cursor := entries.Cursor()
+		for key, _ := cursor.First(); key != nil && s.maxEntries > 0 && count-len(keys) > s.maxEntries; key, _ = cursor.Next() {
+			keys = append(keys, bytes.Clone(key))
+		}
+		if s.retention > 0 {
+			cutoff := timestampKey(now.Add(-s.retention), nil)
+			cursor := tx.Bucket(timestampIndex).Cursor()
+			for indexKey, _ := cursor.First(); indexKey != nil && bytes.Compare(indexKey, cutoff) < 0; indexKey, _ = cursor.Next() {
+				keys = append(keys, bytes.Clone(indexKey[8:]))
+			}
+		}
+
+		for _, key := range keys {
+			data := entries.Get(key)
+			if data == nil {
+				// Both over the limit and too old
+				continue
+			}
+			if err := deleteEntry(tx, key, data); err != nil {
+				return err
+			}
+			addCount(tx, -1)
+			deleted++
+		}
+		return nil
+	})
+	if deleted > 0 {
+		log.Debug("Deleted alerts beyond the alert store retention", "deleted", deleted)
+	}
+	return err
+}
+
+// count returns the number of stored alerts
+func (s *BoltStore) count() int {
+	count := 0
+	_ = s.db.View(func(tx *bbolt.Tx) error {
+		count = readCount(tx)
+		return nil
+	})
+	return count
+}
+
+// deleteEntry deletes an entry and its index keys
+func deleteEntry(tx *bbolt.Tx, key, data []byte) error {
+	var entry alertstore.AlertEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return fmt.Errorf("failed to unmarshal alert: %w", err)
+	}
+	if err := index(tx, entry, key, false); err != nil {
+		return err
+	}
+	return tx.Bucket(entriesBucket).Delete(key)
+}
+
+// index adds or deletes the index keys of an entry
+func index(tx *bbolt.Tx, entry alertstore.AlertEntry, key []byte, add bool) error {
+	keys := map[string][]byte{
+		string(idsBucket):      []byte(entry.ID),
+		string(alertnameIndex): append(indexPrefix(entry.Alert.Labels["alertname"]), timestampKey(entry.Timestamp, key)...),
+		string(statusIndex):    append(indexPrefix(strings.ToLower(entry.Status)), timestampKey(entry.Timestamp, key)...),
+		string(timestampIndex): timestampKey(entry.Timestamp, key),
+	}
+	if entry.JobInfo != nil && entry.JobInfo.JobName != "" {
+		keys[string(jobIndex)] = append(indexPrefix(entry.JobInfo.Namespace+"/"+entry.JobInfo.JobName), key...)
+	}
+
+	for bucket, indexKey := range keys {
+		b := tx.Bucket([]byte(bucket))
+		var err error
+		switch {
+		case !add:
+			err = b.Delete(indexKey)
+		case bucket == string(idsBucket):
+			err = b.Put(indexKey, key)
+		default:
+			err = b.Put(indexKey, []byte{})
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addCount adds delta to the number of stored alerts and returns it
+func addCount(tx *bbolt.Tx, delta int) int {
+	count := readCount(tx) + delta
+	if delta != 0 {
+		value := make([]byte, 8)
+		binary.BigEndian.PutUint64(value, uint64(max(count, 0)))
+		// Put only fails for invalid keys or read-only transactions
+		_ = tx.Bucket(metaBucket).Put(countKey, value)
+	}
+	return count
+}
+
+// readCount returns the number of stored alerts
+func readCount(tx *bbolt.Tx) int {
+	value := tx.Bucket(metaBucket).Get(countKey)
+	if len(value) != 8 {
+		return 0
+	}
+	return int(binary.BigEndian.Uint64(value))
+}
+
+// sequenceKey encodes a sequence number so keys sort numerically
+func sequenceKey(seq uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, seq)
+	return key
+}
+
+// timestampKey encodes a time so keys sort chronologically, followed by the entry key
+func timestampKey(t time.Time, key []byte) []byte {
+	indexKey := make([]byte, 8, 8+len(key))
+	binary.BigEndian.PutUint64(indexKey, uint64(t.UnixNano()))
+	return append(indexKey, key...)
+}
+
+// indexPrefix returns the prefix of the index keys of a value
+func indexPrefix(value string) []byte {
+	return append([]byte(value), 0)
+}
+
+// prefixEnd returns the first key after all keys with an index prefix
+func prefixEnd(prefix []byte) []byte {
+	end := bytes.Clone(prefix)
+	end[len(end)-1]++
+	return end
+}
@@ -0,0 +1,262 @@
+package bolt
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.etcd.io/bbolt"
+
+	"github.com/OpenFero/openfero/pkg/alertstore"
+	"github.com/OpenFero/openfero/pkg/alertstore/storetest"
+)
+
+func newTestStore(t *testing.T, path string, maxEntries int, retention time.Duration) *BoltStore {
+	t.Helper()
+	store := NewBoltStore(path, maxEntries, retention)
+	if err := store.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize store: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+	return store
+}
+
+func testAlert(alertname string) alertstore.Alert {
+	return alertstore.Alert{Labels: map[string]string{"alertname": alertname, "team": "platform"}}
+}
+
+func alertnames(t *testing.T, store *BoltStore, query string) []string {
+	t.Helper()
+	entries, err := store.GetAlerts(query, 0)
+	if err != nil {
+		t.Fatalf("GetAlerts returned error: %v", err)
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Alert.Labels["alertname"])
+	}
+	return names
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestBoltStore_PersistsAlerts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", "alerts.db")
+	store := newTestStore(t, path, 3, 0)
+
+	for _, alertname := range []string{"A", "B", "C", "D"} {
+		if err := store.SaveAlert(testAlert(alertname), "firing"); err != nil {
+			t.Fatalf("Failed to save alert: %v", err)
+		}
+	}
+	if names := alertnames(t, store, ""); !equal(names, []string{"D", "C", "B"}) {
+		t.Errorf("Expected the newest 3 alerts, newest first, got %v", names)
+	}
+	if names := alertnames(t, store, "c"); !equal(names, []string{"C"}) {
+		t.Errorf("Expected the query to match alertname C, got %v", names)
+	}
+	limited, _ := store.GetAlerts("platform", 2)
+	if len(limited) != 2 {
+		t.Errorf("Expected 2 alerts with limit 2, got %d", len(limited))
+	}
+
+	newest, _ := store.GetAlerts("", 1)
+	id := newest[0].ID
+	if err := store.Close(); err != nil {
+		t.Fatalf("Failed to close store: %v", err)
+	}
+
+	// Reopening with a lower limit keeps the history up to it
+	store = newTestStore(t, path, 2, 0)
+	if names := alertnames(t, store, ""); !equal(names, []string{"D", "C"}) {
+		t.Errorf("Expected alerts to survive a restart within the new limit, got %v", names)
+	}
+	entry, err := store.GetAlert(id)
+	if err != nil || entry.Alert.Labels["alertname"] != "D" {
+		t.Errorf("Expected GetAlert to find D after a restart, got %v, %v", entry, err)
+	}
+	if _, err := store.GetAlert("missing"); !errors.Is(err, alertstore.ErrAlertNotFound) {
+		t.Errorf("Expected ErrAlertNotFound, got %v", err)
+	}
+	if _, err := store.GetAlert(newest[0].ID + "x"); !errors.Is(err, alertstore.ErrAlertNotFound) {
+		t.Errorf("Expected ErrAlertNotFound, got %v", err)
+	}
+}
+
+func TestBoltStore_UpdateJobInfo(t *testing.T) {
+	store := newTestStore(t, filepath.Join(t.TempDir(), "alerts.db"), 10, 0)
+	for _, jobName := range []string{"job-a", "job-b", "job-a"} {
+		jobInfo := &alertstore.JobInfo{JobName: jobName, Namespace: "openfero"}
+		if err := store.SaveAlertWithJobInfo(testAlert("TestAlert"), "firing", jobInfo); err != nil {
+			t.Fatalf("Failed to save alert: %v", err)
+		}
+	}
+
+	err := store.UpdateJobInfo("openfero", "job-a", func(jobInfo *alertstore.JobInfo) bool {
+		jobInfo.LastExecutionStatus = "failed"
+		return true
+	})
+	if err != nil {
+		t.Fatalf("UpdateJobInfo returned error: %v", err)
+	}
+
+	entries, _ := store.GetAlerts("", 0)
+	for _, entry := range entries {
+		expected := ""
+		if entry.JobInfo.JobName == "job-a" {
+			expected = "failed"
+		}
+		if entry.JobInfo.LastExecutionStatus != expected {
+			t.Errorf("Expected status %q for %s, got %q", expected, entry.JobInfo.JobName, entry.JobInfo.LastExecutionStatus)
+		}
+	}
+}
+
+func TestBoltStore_Retention(t *testing.T) {
+	store := newTestStore(t, filepath.Join(t.TempDir(), "alerts.db"), 10, time.Hour)
+	for _, alertname := range []string{"A", "B"} {
+		if err := store.SaveAlertWithJobInfo(testAlert(alertname), "firing", &alertstore.JobInfo{JobName: "job", Namespace: "openfero"}); err != nil {
+			t.Fatalf("Failed to save alert: %v", err)
+		}
+	}
+
+	if err := store.prune(time.Now().Add(30 * time.Minute)); err != nil {
+		t.Fatalf("prune returned error: %v", err)
+	}
+	if names := alertnames(t, store, ""); len(names) != 2 {
+		t.Errorf("Expected alerts within the retention to be kept, got %v", names)
+	}
+
+	if err := store.prune(time.Now().Add(2 * time.Hour)); err != nil {
+		t.Fatalf("prune returned error: %v", err)
+	}
+	if names := alertnames(t, store, ""); len(names) != 0 {
+		t.Errorf("Expected alerts older than the retention to be deleted, got %v", names)
+	}
+	if count := store.count(); count != 0 {
+		t.Errorf("Expected a count of 0, got %d", count)
+	}
+
+	// The indexes of deleted alerts are gone too
+	updated := false
+	_ = store.UpdateJobInfo("openfero", "job", func(*alertstore.JobInfo) bool {
+		updated = true
+		return true
+	})
+	if updated {
+		t.Error("Expected no job info of deleted alerts to be updated")
+	}
+}
+
+func TestBoltStore_QueryAlerts(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) alertstore.Store {
+		return newTestStore(t, filepath.Join(t.TempDir(), "alerts.db"), 10, 0)
+	})
+}
+
+// writeSchemaV1 writes alerts with the index layout of schema version 1,
+// which keyed the alertname and status indexes without the timestamp
+func writeSchemaV1(t *testing.T, path string, alertnames ...string) {
+	t.Helper()
+	db, err := bbolt.Open(path, 0o600, nil)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer func() { _ = db.Close() }()
+	err = db.Update(func(tx *bbolt.Tx) error {
+		for _, name := range allBuckets {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		for i, alertname := range alertnames {
+			entry := alertstore.AlertEntry{
+				ID:        alertstore.NewEntryID(),
+				Alert:     testAlert(alertname),
+				Status:    "firing",
+				Timestamp: time.Now().Add(time.Duration(i-len(alertnames)) * time.Minute),
+			}
+			data, err := json.Marshal(entry)
+			if err != nil {
+				return err
+			}
+			seq, _ := tx.Bucket(entriesBucket).NextSequence()
+			key := sequenceKey(seq)
+			keys := map[string][]byte{
+				string(entriesBucket):  key,
+				string(idsBucket):      []byte(entry.ID),
+				string(alertnameIndex): append(indexPrefix(alertname), key...),
+				string(statusIndex):    append(indexPrefix(entry.Status), key...),
+				string(timestampIndex): timestampKey(entry.Timestamp, key),
+			}
+			values := map[string][]byte{string(entriesBucket): data, string(idsBucket): key}
+			for bucket, indexKey := range keys {
+				value, ok := values[bucket]
+				if !ok {
+					value = []byte{}
+				}
+				if err := tx.Bucket([]byte(bucket)).Put(indexKey, value); err != nil {
+					return err
+				}
+			}
+			addCount(tx, 1)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to write alerts: %v", err)
+	}
+}
+
+func TestBoltStore_MigratesSchema(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "alerts.db")
+	writeSchemaV1(t, path, "A", "B", "A")
+	store := newTestStore(t, path, 3, 0)
+
+	result, err := store.QueryAlerts(alertstore.Query{Matchers: []*alertstore.Matcher{{Name: "alertname", Type: alertstore.MatchEqual, Value: "A"}}})
+	if err != nil {
+		t.Fatalf("QueryAlerts returned error: %v", err)
+	}
+	if len(result.Entries) != 2 {
+		t.Fatalf("Expected the alerts of the old layout to be found by alertname, got %d", len(result.Entries))
+	}
+	result, err = store.QueryAlerts(alertstore.Query{Status: "firing"})
+	if err != nil {
+		t.Fatalf("QueryAlerts returned error: %v", err)
+	}
+	if len(result.Entries) != 3 {
+		t.Errorf("Expected the alerts of the old layout to be found by status, got %d", len(result.Entries))
+	}
+
+	// Saving beyond the limit deletes the oldest alert and all its index keys
+	if err := store.SaveAlert(testAlert("C"), "firing"); err != nil {
+		t.Fatalf("Failed to save alert: %v", err)
+	}
+	_ = store.db.View(func(tx *bbolt.Tx) error {
+		if version := readSchemaVersion(tx); version != schemaVersion {
+			t.Errorf("Expected schema version %d, got %d", schemaVersion, version)
+		}
+		// The alerts have no job info, every other index has a key per alert
+		for _, name := range [][]byte{idsBucket, alertnameIndex, statusIndex, timestampIndex} {
+			if keys := tx.Bucket(name).Stats().KeyN; keys != 3 {
+				t.Errorf("Expected 3 keys in %s, got %d", name, keys)
+			}
+		}
+		return nil
+	})
+	if names := alertnames(t, store, ""); !equal(names, []string{"C", "A", "B"}) {
+		t.Errorf("Expected [C A B], got %v", names)
+	}
+}
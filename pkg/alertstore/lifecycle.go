@@ -0,0 +1,241 @@
+package alertstore
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Lifecycle is an alert from the time it started firing until it resolved,
+// correlated from its stored entries by fingerprint
+type Lifecycle struct {
+	Fingerprint string            `json:"fingerprint"`
+	Labels      map[string]string `json:"labels"`
+	// FiringAt is the startsAt of the alert, or when its first entry was stored
+	FiringAt time.Time `json:"firingAt"`
+	// ResolvedAt is the endsAt of the resolved alert, or when it was stored
+	ResolvedAt *time.Time `json:"resolvedAt,omitempty"`
+	EntryIDs   []string   `json:"entryIds,omitempty"`
+
+	// RemediationStartedAt is when the first Job for the alert was created.
+	// The other Job fields describe the last one.
+	RemediationStartedAt *time.Time `json:"remediationStartedAt,omitempty"`
+	Operarius            string     `json:"operarius,omitempty"`
+	Namespace            string     `json:"namespace,omitempty"`
+	JobName              string     `json:"jobName,omitempty"`
+	JobStatus            string     `json:"jobStatus,omitempty"`
+	JobStartedAt         *time.Time `json:"jobStartedAt,omitempty"`
+	JobFinishedAt        *time.Time `json:"jobFinishedAt,omitempty"`
+
+	// Durations are set once both of their ends are known
+	TimeToRemediationStartSeconds *float64 `json:"timeToRemediationStartSeconds,omitempty"`
+	JobDurationSeconds            *float64 `json:"jobDurationSeconds,omitempty"`
+	TimeToResolveSeconds          *float64 `json:"timeToResolveSeconds,omitempty"`
+	// Remediated reports whether the alert resolved after its Job succeeded
+	Remediated bool `json:"remediated"`
+}
+
+// LifecycleChange describes what an entry changed about its lifecycle
+type LifecycleChange int
+
+// Changes of a lifecycle reported by Correlator.Add
+const (
+	LifecycleStarted LifecycleChange = 1 << iota
+	LifecycleRemediationStarted
+	LifecycleResolved
+)
+
+// LifecycleKey returns the key alerts are correlated by: the fingerprint
+// sent by Alertmanager, or one computed from the labels
+func LifecycleKey(alert Alert) string {
+	if alert.Fingerprint != "" {
+		return alert.Fingerprint
+	}
+	return Fingerprint(alert.Labels)
+}
+
+// Correlator builds lifecycles from entries added in the order they were
+// stored. It is not safe for concurrent use.
+type Correlator struct {
+	open    map[string]*Lifecycle
+	maxOpen int
+}
+
+// NewCorrelator creates a correlator keeping at most maxOpen lifecycles
+// open, unlimited if 0. Alerts whose resolution is never received would
+// otherwise accumulate; the one firing the longest is dropped first.
+func NewCorrelator(maxOpen int) *Correlator {
+	return &Correlator{open: make(map[string]*Lifecycle), maxOpen: maxOpen}
+}
+
+// Add adds an entry to the lifecycle of its alert and returns the
+// lifecycle and what changed. A resolved entry without an open lifecycle
+// and without startsAt has no lifecycle and changes nothing.
+func (c *Correlator) Add(entry AlertEntry) (Lifecycle, LifecycleChange) {
+	key := LifecycleKey(entry.Alert)
+	resolved := strings.EqualFold(entry.Status, "resolved")
+
+	var change LifecycleChange
+	lifecycle, ok := c.open[key]
+	if !ok {
+		firingAt, known := alertTime(entry.Alert.StartsAt, entry.Timestamp)
+		if resolved && !known {
+			return Lifecycle{}, 0
+		}
+		if c.maxOpen > 0 && len(c.open) >= c.maxOpen {
+			c.dropOldest()
+		}
+		lifecycle = &Lifecycle{Fingerprint: key, Labels: entry.Alert.Labels, FiringAt: firingAt}
+		c.open[key] = lifecycle
+		change |= LifecycleStarted
+	}
+
+	if entry.ID != "" {
+		lifecycle.EntryIDs = append(lifecycle.EntryIDs, entry.ID)
+	}
+	// Jobs run for the resolution of an alert don't remediate it
+	if !resolved && jobCreated(entry) {
+		jobInfo := entry.JobInfo
+		if lifecycle.RemediationStartedAt == nil {
+			createdAt := entry.Timestamp
+			lifecycle.RemediationStartedAt = &createdAt
+			change |= LifecycleRemediationStarted
+		}
+		if lifecycle.Namespace != jobInfo.Namespace || lifecycle.JobName != jobInfo.JobName {
+			lifecycle.Operarius = jobInfo.OperariusName
+			lifecycle.Namespace = jobInfo.Namespace
+			lifecycle.JobName = jobInfo.JobName
+			lifecycle.JobStatus, lifecycle.JobStartedAt, lifecycle.JobFinishedAt = "", nil, nil
+		}
+		lifecycle.updateJob(jobInfo.LastExecutionStatus, jobInfo.StartedAt, jobInfo.FinishedAt)
+	}
+	if resolved {
+		resolvedAt, _ := alertTime(entry.Alert.EndsAt, entry.Timestamp)
+		if resolvedAt.Before(lifecycle.FiringAt) {
+			resolvedAt = lifecycle.FiringAt
+		}
+		lifecycle.ResolvedAt = &resolvedAt
+		delete(c.open, key)
+		change |= LifecycleResolved
+	}
+
+	lifecycle.settle()
+	return lifecycle.clone(), change
+}
+
+// JobFinished records when a Job ran on the open lifecycles it remediates
+// and reports whether there were any
+func (c *Correlator) JobFinished(namespace, jobName, status string, startedAt, finishedAt time.Time) bool {
+	found := false
+	for _, lifecycle := range c.open {
+		if lifecycle.Namespace == namespace && lifecycle.JobName == jobName {
+			lifecycle.updateJob(status, &startedAt, &finishedAt)
+			lifecycle.settle()
+			found = true
+		}
+	}
+	return found
+}
+
+// dropOldest drops the open lifecycle that has been firing the longest
+func (c *Correlator) dropOldest() {
+	var oldestKey string
+	var oldest *Lifecycle
+	for key, lifecycle := range c.open {
+		if oldest == nil || lifecycle.FiringAt.Before(oldest.FiringAt) {
+			oldestKey, oldest = key, lifecycle
+		}
+	}
+	delete(c.open, oldestKey)
+}
+
+// Correlate builds the lifecycles of stored entries, most recently firing
+// first. Lifecycles whose first entries are no longer stored start at the
+// startsAt of the alert.
+func Correlate(entries []AlertEntry) []Lifecycle {
+	entries = slices.Clone(entries)
+	slices.SortFunc(entries, func(a, b AlertEntry) int {
+		return cmp.Or(a.Timestamp.Compare(b.Timestamp), strings.Compare(a.ID, b.ID))
+	})
+
+	correlator := NewCorrelator(0)
+	var lifecycles []Lifecycle
+	current := make(map[string]int)
+	for _, entry := range entries {
+		lifecycle, change := correlator.Add(entry)
+		switch {
+		case change&LifecycleStarted != 0:
+			current[lifecycle.Fingerprint] = len(lifecycles)
+			lifecycles = append(lifecycles, lifecycle)
+		case lifecycle.Fingerprint != "":
+			lifecycles[current[lifecycle.Fingerprint]] = lifecycle
+		}
+	}
+
+	slices.SortStableFunc(lifecycles, func(a, b Lifecycle) int {
+		return b.FiringAt.Compare(a.FiringAt)
+	})
+	return lifecycles
+}
+
+// updateJob records the status and timing of the lifecycle's Job, keeping
+// what is already known
+func (l *Lifecycle) updateJob(status string, startedAt, finishedAt *time.Time) {
+	if status != "" {
+		l.JobStatus = status
+	}
+	if startedAt != nil {
+		l.JobStartedAt = startedAt
+	}
+	if finishedAt != nil {
+		l.JobFinishedAt = finishedAt
+	}
+}
+
+// settle computes the durations of the lifecycle
+func (l *Lifecycle) settle() {
+	seconds := func(from time.Time, to *time.Time) *float64 {
+		if to == nil {
+			return nil
+		}
+		s := max(to.Sub(from).Seconds(), 0)
+		return &s
+	}
+	l.TimeToRemediationStartSeconds = seconds(l.FiringAt, l.RemediationStartedAt)
+	l.TimeToResolveSeconds = seconds(l.FiringAt, l.ResolvedAt)
+	l.JobDurationSeconds = nil
+	if l.JobStartedAt != nil {
+		l.JobDurationSeconds = seconds(*l.JobStartedAt, l.JobFinishedAt)
+	}
+	l.Remediated = l.ResolvedAt != nil && strings.EqualFold(l.JobStatus, "Successful") &&
+		(l.JobFinishedAt == nil || !l.JobFinishedAt.After(*l.ResolvedAt))
+}
+
+// clone returns a copy of the lifecycle that doesn't share its entry IDs
+func (l *Lifecycle) clone() Lifecycle {
+	c := *l
+	c.EntryIDs = slices.Clone(l.EntryIDs)
+	return c
+}
+
+// jobCreated reports whether a Job was created for an entry, rather than
+// skipped by deduplication
+func jobCreated(entry AlertEntry) bool {
+	switch entry.JobOutcome() {
+	case JobOutcomeNone, JobOutcomeSkipped:
+		return false
+	}
+	return entry.JobInfo.JobName != ""
+}
+
+// alertTime parses startsAt or endsAt of an alert. Times that are missing,
+// invalid or after the alert was stored fall back to when it was stored, and
+// known is false.
+func alertTime(value string, stored time.Time) (t time.Time, known bool) {
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil || parsed.Year() <= 1 || parsed.After(stored) {
+		return stored, false
+	}
+	return parsed, true
+}
@@ -0,0 +1,90 @@
+package alertstore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCorrelate(t *testing.T) {
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time { return start.Add(time.Duration(minutes) * time.Minute) }
+	disk := Alert{Labels: map[string]string{"alertname": "Disk"}, StartsAt: start.Format(time.RFC3339)}
+	cpu := Alert{Labels: map[string]string{"alertname": "CPU"}, Fingerprint: "cpu"}
+	job := func(status string, startedAt, finishedAt *time.Time) *JobInfo {
+		return &JobInfo{OperariusName: "disk-operarius", Namespace: "openfero", JobName: "disk-1", LastExecutionStatus: status, StartedAt: startedAt, FinishedAt: finishedAt}
+	}
+	jobStarted, jobFinished := at(3), at(5)
+	resolvedDisk := disk
+	resolvedDisk.EndsAt = at(9).Format(time.RFC3339)
+
+	lifecycles := Correlate([]AlertEntry{
+		// Entries are correlated in the order they were stored, not listed
+		{ID: "4", Alert: resolvedDisk, Status: "resolved", Timestamp: at(10)},
+		{ID: "1", Alert: disk, Status: "firing", Timestamp: at(1)},
+		{ID: "2", Alert: disk, Status: "firing", Timestamp: at(2), JobInfo: job("Successful", &jobStarted, &jobFinished)},
+		{ID: "3", Alert: cpu, Status: "firing", Timestamp: at(4), JobInfo: &JobInfo{OperariusName: "cpu-operarius", JobName: "N/A (Deduplicated)", LastExecutionStatus: "Skipped: Deduplication"}},
+		// Firing again after it resolved starts a new lifecycle
+		{ID: "5", Alert: Alert{Labels: disk.Labels}, Status: "firing", Timestamp: at(20)},
+		// Resolved without a known start, nothing to correlate
+		{ID: "6", Alert: Alert{Labels: map[string]string{"alertname": "Network"}}, Status: "resolved", Timestamp: at(21)},
+	})
+	require.Len(t, lifecycles, 3)
+
+	again, cpuLifecycle, first := lifecycles[0], lifecycles[1], lifecycles[2]
+	assert.Equal(t, LifecycleKey(disk), first.Fingerprint)
+	assert.Equal(t, []string{"1", "2", "4"}, first.EntryIDs)
+	assert.Equal(t, start, first.FiringAt, "startsAt is when the alert started firing")
+	require.NotNil(t, first.ResolvedAt)
+	assert.Equal(t, at(9), *first.ResolvedAt, "endsAt is when the alert resolved")
+	assert.Equal(t, "disk-operarius", first.Operarius)
+	assert.Equal(t, "Successful", first.JobStatus)
+	assert.InDelta(t, 120, *first.TimeToRemediationStartSeconds, 0)
+	assert.InDelta(t, 120, *first.JobDurationSeconds, 0)
+	assert.InDelta(t, 540, *first.TimeToResolveSeconds, 0)
+	assert.True(t, first.Remediated)
+
+	assert.Equal(t, "cpu", cpuLifecycle.Fingerprint)
+	assert.Empty(t, cpuLifecycle.Operarius, "deduplicated alerts have no Job")
+	assert.Nil(t, cpuLifecycle.RemediationStartedAt)
+	assert.Nil(t, cpuLifecycle.ResolvedAt)
+	assert.False(t, cpuLifecycle.Remediated)
+
+	assert.Equal(t, first.Fingerprint, again.Fingerprint)
+	assert.Equal(t, at(20), again.FiringAt, "without startsAt the alert fired when it was stored")
+	assert.Equal(t, []string{"5"}, again.EntryIDs)
+	assert.Nil(t, again.TimeToResolveSeconds)
+}
+
+func TestCorrelator(t *testing.T) {
+	now := time.Now()
+	alert := Alert{Labels: map[string]string{"alertname": "Disk"}}
+	jobInfo := &JobInfo{OperariusName: "disk-operarius", Namespace: "openfero", JobName: "disk-1"}
+
+	correlator := NewCorrelator(2)
+	_, change := correlator.Add(AlertEntry{Alert: alert, Status: "firing", Timestamp: now})
+	assert.Equal(t, LifecycleStarted, change)
+
+	lifecycle, change := correlator.Add(AlertEntry{Alert: alert, Status: "firing", Timestamp: now.Add(time.Minute), JobInfo: jobInfo})
+	assert.Equal(t, LifecycleRemediationStarted, change)
+	assert.InDelta(t, 60, *lifecycle.TimeToRemediationStartSeconds, 0.001)
+
+	// A resolution before the Job finished is not a remediation
+	assert.True(t, correlator.JobFinished("openfero", "disk-1", "Successful", now.Add(2*time.Minute), now.Add(4*time.Minute)))
+	assert.False(t, correlator.JobFinished("openfero", "other", "Successful", now, now), "the Job of another replica")
+	lifecycle, change = correlator.Add(AlertEntry{Alert: alert, Status: "resolved", Timestamp: now.Add(3 * time.Minute)})
+	assert.Equal(t, LifecycleResolved, change)
+	assert.InDelta(t, 120, *lifecycle.JobDurationSeconds, 0.001)
+	assert.False(t, lifecycle.Remediated)
+
+	// The lifecycle firing the longest is dropped when too many are open
+	for i, name := range []string{"A", "B", "C"} {
+		correlator.Add(AlertEntry{Alert: Alert{Labels: map[string]string{"alertname": name}}, Status: "firing", Timestamp: now.Add(time.Duration(i) * time.Minute)})
+	}
+	_, change = correlator.Add(AlertEntry{Alert: Alert{Labels: map[string]string{"alertname": "A"}}, Status: "resolved", Timestamp: now.Add(5 * time.Minute)})
+	assert.Zero(t, change, "the lifecycle of A was dropped")
+	_, change = correlator.Add(AlertEntry{Alert: Alert{Labels: map[string]string{"alertname": "C"}}, Status: "resolved", Timestamp: now.Add(5 * time.Minute)})
+	assert.Equal(t, LifecycleResolved, change)
+}
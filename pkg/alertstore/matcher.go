@@ -0,0 +1,167 @@
+package alertstore
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// MatchType is the operator of a label matcher
+type MatchType string
+
+// Match types, as in Prometheus and Alertmanager
+const (
+	MatchEqual     MatchType = "="
+	MatchNotEqual  MatchType = "!="
+	MatchRegexp    MatchType = "=~"
+	MatchNotRegexp MatchType = "!~"
+)
+
+// Matcher matches the value of a label. A missing label matches like an empty value.
+type Matcher struct {
+	Name  string
+	Type  MatchType
+	Value string
+	re    *regexp.Regexp
+}
+
+// NewMatcher creates a matcher, compiling the value of regular expression matchers
+func NewMatcher(name string, matchType MatchType, value string) (*Matcher, error) {
+	m := &Matcher{Name: name, Type: matchType, Value: value}
+	switch matchType {
+	case MatchEqual, MatchNotEqual:
+	case MatchRegexp, MatchNotRegexp:
+		// Regular expressions are anchored like in Prometheus
+		re, err := regexp.Compile("^(?:" + value + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression in matcher %s: %w", name, err)
+		}
+		m.re = re
+	default:
+		return nil, fmt.Errorf("invalid match type %q", matchType)
+	}
+	return m, nil
+}
+
+// Matches reports whether a label value matches
+func (m *Matcher) Matches(value string) bool {
+	switch m.Type {
+	case MatchEqual:
+		return value == m.Value
+	case MatchNotEqual:
+		return value != m.Value
+	case MatchRegexp:
+		return m.re.MatchString(value)
+	case MatchNotRegexp:
+		return !m.re.MatchString(value)
+	}
+	return false
+}
+
+// String renders the matcher the way it is parsed
+func (m *Matcher) String() string {
+	return m.Name + string(m.Type) + strconv.Quote(m.Value)
+}
+
+// MatchLabels reports whether labels match all matchers
+func MatchLabels(matchers []*Matcher, labels map[string]string) bool {
+	for _, m := range matchers {
+		if !m.Matches(labels[m.Name]) {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseMatchers parses a comma separated list of matchers like
+// `alertname="Disk", severity=~"warning|critical"`, optionally in braces.
+// Values may be unquoted if they contain no commas or spaces.
+func ParseMatchers(input string) ([]*Matcher, error) {
+	input = strings.TrimSpace(input)
+	if strings.HasPrefix(input, "{") && strings.HasSuffix(input, "}") {
+		input = input[1 : len(input)-1]
+	}
+
+	var matchers []*Matcher
+	for _, part := range splitMatchers(input) {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		m, err := ParseMatcher(part)
+		if err != nil {
+			return nil, err
+		}
+		matchers = append(matchers, m)
+	}
+	return matchers, nil
+}
+
+// ParseMatcher parses a single matcher like `severity!="info"`
+func ParseMatcher(input string) (*Matcher, error) {
+	input = strings.TrimSpace(input)
+	end := strings.IndexAny(input, "=!")
+	if end <= 0 {
+		return nil, fmt.Errorf("invalid matcher %q: expected a label name followed by =, !=, =~ or !~", input)
+	}
+	name := strings.TrimSpace(input[:end])
+	if !validLabelName(name) {
+		return nil, fmt.Errorf("invalid matcher %q: invalid label name %q", input, name)
+	}
+
+	rest := input[end:]
+	var matchType MatchType
+	for _, t := range []MatchType{MatchRegexp, MatchNotRegexp, MatchNotEqual, MatchEqual} {
+		if strings.HasPrefix(rest, string(t)) {
+			matchType = t
+			break
+		}
+	}
+	if matchType == "" {
+		return nil, fmt.Errorf("invalid matcher %q: expected =, !=, =~ or !~", input)
+	}
+
+	value := strings.TrimSpace(rest[len(matchType):])
+	if strings.HasPrefix(value, `"`) {
+		unquoted, err := strconv.Unquote(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid matcher %q: invalid quoted value", input)
+		}
+		value = unquoted
+	}
+	return NewMatcher(name, matchType, value)
+}
+
+// splitMatchers splits on commas outside of quoted values
+func splitMatchers(input string) []string {
+	var parts []string
+	start, quoted, escaped := 0, false, false
+	for i, c := range input {
+		switch {
+		case escaped:
+			escaped = false
+		case c == '\\' && quoted:
+			escaped = true
+		case c == '"':
+			quoted = !quoted
+		case c == ',' && !quoted:
+			parts = append(parts, input[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, input[start:])
+}
+
+// validLabelName reports whether name is a valid Prometheus label name
+func validLabelName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, c := range name {
+		if c != '_' && (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') && (i == 0 || c < '0' || c > '9') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,46 @@
+package alertstore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMatchers(t *testing.T) {
+	matchers, err := ParseMatchers(`{alertname="Disk, full", severity=~"warning|critical", team!=ops,instance!~"test-.*"}`)
+	require.NoError(t, err)
+	require.Len(t, matchers, 4)
+	assert.Equal(t, `alertname="Disk, full"`, matchers[0].String())
+	assert.Equal(t, `severity=~"warning|critical"`, matchers[1].String())
+	assert.Equal(t, `team!="ops"`, matchers[2].String())
+	assert.Equal(t, `instance!~"test-.*"`, matchers[3].String())
+
+	labels := map[string]string{"alertname": "Disk, full", "severity": "critical", "instance": "prod-1"}
+	assert.True(t, MatchLabels(matchers, labels))
+
+	labels["severity"] = "criticality"
+	assert.False(t, MatchLabels(matchers, labels), "regular expressions are anchored")
+
+	labels["severity"] = "warning"
+	labels["team"] = "ops"
+	assert.False(t, MatchLabels(matchers, labels))
+
+	empty, err := ParseMatchers("")
+	require.NoError(t, err)
+	assert.True(t, MatchLabels(empty, labels))
+}
+
+func TestParseMatcher_Invalid(t *testing.T) {
+	for _, input := range []string{
+		`alertname`,
+		`="x"`,
+		`1name="x"`,
+		`name~"x"`,
+		`name="unterminated`,
+		`name=~"("`,
+	} {
+		_, err := ParseMatcher(input)
+		assert.Error(t, err, input)
+	}
+}
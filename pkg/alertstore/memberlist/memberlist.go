@@ -11,6 +11,7 @@ import (
 	"sort"
 
 	"github.com/OpenFero/openfero/pkg/alertstore"
+	"github.com/OpenFero/openfero/pkg/ingest"
 	log "github.com/OpenFero/openfero/pkg/logging"
 	"github.com/hashicorp/memberlist"
 )
@@ -27,6 +28,7 @@ type MemberlistStore struct {
 
 // alertEntry represents a single alert in the store
 type alertEntry struct {
+	ID        string              `json:"id,omitempty"`
 	Alert     alertstore.Alert    `json:"alert"`
 	Status    string              `json:"status"`
 	Timestamp time.Time           `json:"timestamp"`
@@ -37,8 +39,18 @@ type alertEntry struct {
 type delegate struct {
 	broadcasts *memberlist.TransmitLimitedQueue
 	store      *MemberlistStore
+	deliveries *ingest.DeliveryLog
+	nonces     *ingest.NonceLog
 }
 
+// deliveryMessage prefixes broadcasts of accepted webhook deliveries. Alert
+// entries are JSON objects, so they never start with it, and peers that don't
+// know it fail to decode the message instead of storing an empty alert.
+const deliveryMessage byte = 'd'
+
+// nonceMessage prefixes broadcasts of the nonces of signed webhook requests
+const nonceMessage byte = 'n'
+
 // NewMemberlistStore creates a new memberlist-based alert store
 func NewMemberlistStore(clustername string, limit int) *MemberlistStore {
 	if limit <= 0 {
@@ -143,6 +155,7 @@ func (s *MemberlistStore) SaveAlert(alert alertstore.Alert, status string) error
 // SaveAlertWithJobInfo adds an alert with job info to the store and broadcasts it to the cluster
 func (s *MemberlistStore) SaveAlertWithJobInfo(alert alertstore.Alert, status string, jobInfo *alertstore.JobInfo) error {
 	entry := alertEntry{
+		ID:        alertstore.NewEntryID(),
 		Alert:     alert,
 		Status:    status,
 		Timestamp: time.Now(),
@@ -210,6 +223,7 @@ func (s *MemberlistStore) GetAlerts(query string, limit int) ([]alertstore.Alert
 		result := make([]alertstore.AlertEntry, 0, limit)
 		for i := 0; i < limit && i < len(s.alerts); i++ {
 			result = append(result, alertstore.AlertEntry{
+				ID:        s.alerts[i].ID,
 				Alert:     s.alerts[i].Alert,
 				Status:    s.alerts[i].Status,
 				Timestamp: s.alerts[i].Timestamp,
@@ -227,6 +241,7 @@ func (s *MemberlistStore) GetAlerts(query string, limit int) ([]alertstore.Alert
 	for _, entry := range s.alerts {
 		if s.alertMatchesQuery(entry, query) {
 			result = append(result, alertstore.AlertEntry{
+				ID:        entry.ID,
 				Alert:     entry.Alert,
 				Status:    entry.Status,
 				Timestamp: entry.Timestamp,
@@ -244,6 +259,123 @@ func (s *MemberlistStore) GetAlerts(query string, limit int) ([]alertstore.Alert
 	return result, nil
 }
 
+// QueryAlerts selects alerts by structured filters
+func (s *MemberlistStore) QueryAlerts(query alertstore.Query) (alertstore.QueryResult, error) {
+	s.mutex.RLock()
+	entries := make([]alertstore.AlertEntry, 0, len(s.alerts))
+	for _, entry := range s.alerts {
+		entries = append(entries, alertstore.AlertEntry{
+			ID:        entry.ID,
+			Alert:     entry.Alert,
+			Status:    entry.Status,
+			Timestamp: entry.Timestamp,
+			JobInfo:   entry.JobInfo,
+		})
+	}
+	s.mutex.RUnlock()
+	return query.Apply(entries)
+}
+
+// GetAlert retrieves a single alert by its ID
+func (s *MemberlistStore) GetAlert(id string) (*alertstore.AlertEntry, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	for _, entry := range s.alerts {
+		if entry.ID == id {
+			return &alertstore.AlertEntry{
+				ID:        entry.ID,
+				Alert:     entry.Alert,
+				Status:    entry.Status,
+				Timestamp: entry.Timestamp,
+				JobInfo:   entry.JobInfo,
+			}, nil
+		}
+	}
+	return nil, alertstore.ErrAlertNotFound
+}
+
+// UpdateJobInfo applies update to the job information of all alerts that triggered
+// the given job and broadcasts the updated entries to the cluster
+func (s *MemberlistStore) UpdateJobInfo(namespace, jobName string, update func(jobInfo *alertstore.JobInfo) bool) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for i := range s.alerts {
+		jobInfo := s.alerts[i].JobInfo
+		if jobInfo == nil || jobInfo.JobName != jobName || jobInfo.Namespace != namespace {
+			continue
+		}
+
+		// Update a copy so entries already handed out by GetAlerts stay untouched
+		updated := *jobInfo
+		if !update(&updated) {
+			continue
+		}
+		s.alerts[i].JobInfo = &updated
+
+		data, err := json.Marshal(s.alerts[i])
+		if err != nil {
+			return fmt.Errorf("failed to marshal alert: %w", err)
+		}
+		if s.broadcasts != nil && s.ml != nil {
+			s.broadcasts.QueueBroadcast(&broadcast{
+				msg:    data,
+				notify: nil,
+			})
+			log.Debug("Broadcast job info update to cluster",
+				"job", jobName,
+				"clusterSize", s.ml.NumMembers())
+		}
+	}
+
+	return nil
+}
+
+// ShareDeliveries broadcasts the deliveries accepted by a delivery log to the
+// cluster, and adds the ones other replicas accept to it. Deliveries are only
+// broadcast, a replica joining later doesn't learn the ones accepted before.
+// It must be called before Initialize.
+func (s *MemberlistStore) ShareDeliveries(deliveries *ingest.DeliveryLog) {
+	s.delegate.deliveries = deliveries
+	deliveries.Share(func(seen ingest.SeenDelivery) {
+		if s.broadcasts == nil || s.ml == nil {
+			return
+		}
+		data, err := json.Marshal(seen)
+		if err != nil {
+			log.Error("Failed to marshal delivery for broadcast", "error", err)
+			return
+		}
+		s.broadcasts.QueueBroadcast(&broadcast{
+			msg:    append([]byte{deliveryMessage}, data...),
+			notify: nil,
+		})
+	})
+}
+
+// ShareNonces broadcasts the nonces used by signed webhook requests to the
+// cluster, and adds the ones used on other replicas to the log, so a request
+// can't be replayed against another replica. It must be called before
+// Initialize.
+func (s *MemberlistStore) ShareNonces(nonces *ingest.NonceLog) {
+	s.delegate.nonces = nonces
+	nonces.Share(func(seen ingest.SeenNonce) {
+		if s.broadcasts == nil || s.ml == nil {
+			return
+		}
+		data, err := json.Marshal(seen)
+		if err != nil {
+			log.Error("Failed to marshal nonce for broadcast", "error", err)
+			return
+		}
+		s.broadcasts.QueueBroadcast(&broadcast{
+			msg:    append([]byte{nonceMessage}, data...),
+			notify: nil,
+		})
+	})
+}
+
 // Close leaves the memberlist cluster
 func (s *MemberlistStore) Close() error {
 	if s.ml != nil {
@@ -284,6 +416,11 @@ func (s *MemberlistStore) alertMatchesQuery(entry alertEntry, query string) bool
 		}
 	}
 
+	// Check fingerprint
+	if entry.Alert.Fingerprint != "" && strings.Contains(entry.Alert.Fingerprint, query) {
+		return true
+	}
+
 	// Check job info if present
 	if entry.JobInfo != nil {
 		if strings.Contains(strings.ToLower(entry.JobInfo.OperariusName), query) ||
@@ -296,6 +433,26 @@ func (s *MemberlistStore) alertMatchesQuery(entry alertEntry, query string) bool
 	return false
 }
 
+// sameEntry reports whether two entries describe the same stored alert. Entries
+// carry an ID since it was introduced; older peers only send alertname and timestamp.
+func sameEntry(a, b alertEntry) bool {
+	if a.ID != "" && b.ID != "" {
+		return a.ID == b.ID
+	}
+	if !a.Timestamp.Equal(b.Timestamp) {
+		return false
+	}
+	if a.Alert.Fingerprint != "" && b.Alert.Fingerprint != "" {
+		return a.Alert.Fingerprint == b.Alert.Fingerprint
+	}
+	alertname, ok := a.Alert.Labels["alertname"]
+	if !ok {
+		return false
+	}
+	otherAlertname, ok := b.Alert.Labels["alertname"]
+	return ok && alertname == otherAlertname
+}
+
 // NodeMeta is used to retrieve meta-data about the current node
 func (d *delegate) NodeMeta(limit int) []byte {
 	return []byte{}
@@ -307,6 +464,15 @@ func (d *delegate) NotifyMsg(data []byte) {
 		return
 	}
 
+	switch data[0] {
+	case deliveryMessage:
+		d.notifyDelivery(data[1:])
+		return
+	case nonceMessage:
+		d.notifyNonce(data[1:])
+		return
+	}
+
 	// Deserialize the alert entry
 	var entry alertEntry
 	if err := json.Unmarshal(data, &entry); err != nil {
@@ -331,19 +497,17 @@ func (d *delegate) NotifyMsg(data []byte) {
 	d.store.mutex.Lock()
 	defer d.store.mutex.Unlock()
 
-	// Check if this alert already exists (exact match by alertname, labels, and timestamp)
-	for _, existing := range d.store.alerts {
-		if existing.Timestamp.Equal(entry.Timestamp) {
-			// Compare alertname if it exists
-			if alertname, ok := existing.Alert.Labels["alertname"]; ok {
-				if newAlertname, ok2 := entry.Alert.Labels["alertname"]; ok2 && alertname == newAlertname {
-					// Already have this alert, skip it
-					log.Debug("Skipping duplicate alert",
-							"alertname", alertname,
-							"timestamp", entry.Timestamp)
-					return
-				}
+	// Check if this alert already exists
+	for i, existing := range d.store.alerts {
+		if sameEntry(existing, entry) {
+			// Already have this alert; only take over updated job information
+			if entry.JobInfo != nil {
+				d.store.alerts[i].JobInfo = entry.JobInfo
 			}
+			log.Debug("Skipping duplicate alert",
+				"alertname", alertName,
+				"timestamp", entry.Timestamp)
+			return
 		}
 	}
 
@@ -358,6 +522,37 @@ func (d *delegate) NotifyMsg(data []byte) {
 	}
 }
 
+// notifyDelivery adds a delivery accepted by another replica to the delivery log
+func (d *delegate) notifyDelivery(data []byte) {
+	if d.deliveries == nil {
+		return
+	}
+	var seen ingest.SeenDelivery
+	if err := json.Unmarshal(data, &seen); err != nil {
+		log.Error("Failed to unmarshal delivery in NotifyMsg",
+			"error", err,
+			"dataLength", len(data))
+		return
+	}
+	log.Debug("Received delivery notification from cluster", "deliveryId", seen.DeliveryID)
+	d.deliveries.Add(seen)
+}
+
+// notifyNonce adds a nonce used on another replica to the nonce log
+func (d *delegate) notifyNonce(data []byte) {
+	if d.nonces == nil {
+		return
+	}
+	var seen ingest.SeenNonce
+	if err := json.Unmarshal(data, &seen); err != nil {
+		log.Error("Failed to unmarshal nonce in NotifyMsg",
+			"error", err,
+			"dataLength", len(data))
+		return
+	}
+	d.nonces.Add(seen)
+}
+
 // GetBroadcasts is called when user data broadcasts are needed
 func (d *delegate) GetBroadcasts(overhead, limit int) [][]byte {
 	if d.broadcasts == nil {
@@ -426,14 +621,9 @@ func (d *delegate) MergeRemoteState(buf []byte, join bool) {
 	for _, remoteEntry := range remoteAlerts {
 		found := false
 		for _, localEntry := range d.store.alerts {
-			if localEntry.Timestamp.Equal(remoteEntry.Timestamp) {
-				// Compare alertname if it exists
-				if alertname, ok := localEntry.Alert.Labels["alertname"]; ok {
-					if remoteAlertname, ok2 := remoteEntry.Alert.Labels["alertname"]; ok2 && alertname == remoteAlertname {
-						found = true
-						break
-					}
-				}
+			if sameEntry(localEntry, remoteEntry) {
+				found = true
+				break
 			}
 		}
 
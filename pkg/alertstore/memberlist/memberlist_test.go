@@ -0,0 +1,41 @@
+package memberlist
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/OpenFero/openfero/pkg/alertstore"
+	"github.com/OpenFero/openfero/pkg/alertstore/storetest"
+	"github.com/OpenFero/openfero/pkg/ingest"
+)
+
+func TestQueryAlerts(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) alertstore.Store {
+		return NewMemberlistStore("test", 10)
+	})
+}
+
+func TestShareNonces(t *testing.T) {
+	nonces := ingest.NewNonceLog()
+	store := NewMemberlistStore("test", 10)
+	store.ShareNonces(nonces)
+
+	// Before the cluster was joined nothing is broadcast
+	now := time.Now()
+	if !nonces.Use("local", now.Add(time.Minute), now) {
+		t.Fatal("Expected a new nonce to be accepted")
+	}
+
+	data, err := json.Marshal(ingest.SeenNonce{Nonce: "remote", Expires: now.Add(time.Minute)})
+	if err != nil {
+		t.Fatalf("Failed to marshal nonce: %v", err)
+	}
+	store.delegate.NotifyMsg(append([]byte{nonceMessage}, data...))
+	if nonces.Use("remote", now.Add(time.Minute), now) {
+		t.Error("Expected a nonce used on another replica to be rejected")
+	}
+	if entries, _ := store.GetAlerts("", 0); len(entries) != 0 {
+		t.Errorf("Expected nonce messages not to be stored as alerts, got %d", len(entries))
+	}
+}
@@ -1,7 +1,6 @@
 package memory
 
 import (
-	"strings"
 	"sync"
 	"time"
 
@@ -42,6 +41,7 @@ func (s *MemoryStore) SaveAlert(alert alertstore.Alert, status string) error {
 // SaveAlertWithJobInfo saves an alert to the in-memory store with job information
 func (s *MemoryStore) SaveAlertWithJobInfo(alert alertstore.Alert, status string, jobInfo *alertstore.JobInfo) error {
 	entry := alertstore.AlertEntry{
+		ID:        alertstore.NewEntryID(),
 		Alert:     alert,
 		Status:    status,
 		Timestamp: time.Now(),
@@ -84,7 +84,7 @@ func (s *MemoryStore) GetAlerts(query string, limit int) ([]alertstore.AlertEntr
 	// Filter alerts based on query
 	var results []alertstore.AlertEntry
 	for i := len(s.alerts) - 1; i >= 0; i-- {
-		if alertMatchesQuery(s.alerts[i], query) {
+		if alertstore.MatchesQuery(s.alerts[i], query) {
 			results = append(results, s.alerts[i])
 			if limit > 0 && len(results) >= limit {
 				break
@@ -95,44 +95,44 @@ func (s *MemoryStore) GetAlerts(query string, limit int) ([]alertstore.AlertEntr
 	return results, nil
 }
 
-// alertMatchesQuery checks if an alert matches the search query
-func alertMatchesQuery(entry alertstore.AlertEntry, query string) bool {
-	query = strings.ToLower(query)
-
-	// Check alertname
-	if alertname, ok := entry.Alert.Labels["alertname"]; ok {
-		if strings.Contains(strings.ToLower(alertname), query) {
-			return true
-		}
-	}
+// QueryAlerts selects alerts by structured filters
+func (s *MemoryStore) QueryAlerts(query alertstore.Query) (alertstore.QueryResult, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return query.Apply(s.alerts)
+}
 
-	// Check status
-	if strings.Contains(strings.ToLower(entry.Status), query) {
-		return true
-	}
+// GetAlert retrieves a single alert by its ID
+func (s *MemoryStore) GetAlert(id string) (*alertstore.AlertEntry, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
 
-	// Check labels
-	for _, value := range entry.Alert.Labels {
-		if strings.Contains(strings.ToLower(value), query) {
-			return true
+	for i := range s.alerts {
+		if s.alerts[i].ID == id {
+			entry := s.alerts[i]
+			return &entry, nil
 		}
 	}
+	return nil, alertstore.ErrAlertNotFound
+}
 
-	// Check annotations
-	for _, value := range entry.Alert.Annotations {
-		if strings.Contains(strings.ToLower(value), query) {
-			return true
+// UpdateJobInfo applies update to the job information of all alerts that triggered the given job
+func (s *MemoryStore) UpdateJobInfo(namespace, jobName string, update func(jobInfo *alertstore.JobInfo) bool) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for i := range s.alerts {
+		jobInfo := s.alerts[i].JobInfo
+		if jobInfo == nil || jobInfo.JobName != jobName || jobInfo.Namespace != namespace {
+			continue
 		}
-	}
 
-	// Check job info if present
-	if entry.JobInfo != nil {
-		if strings.Contains(strings.ToLower(entry.JobInfo.OperariusName), query) ||
-			strings.Contains(strings.ToLower(entry.JobInfo.JobName), query) ||
-			strings.Contains(strings.ToLower(entry.JobInfo.Image), query) {
-			return true
+		// Update a copy so entries already handed out by GetAlerts stay untouched
+		updated := *jobInfo
+		if update(&updated) {
+			s.alerts[i].JobInfo = &updated
 		}
 	}
 
-	return false
+	return nil
 }
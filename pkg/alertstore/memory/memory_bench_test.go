@@ -143,7 +143,7 @@ func benchmarkGetAlerts(b *testing.B, storeSize int, query string, limit int) {
 	}
 }
 
-// --- alertMatchesQuery benchmarks ---
+// --- alertstore.MatchesQuery benchmarks ---
 
 func BenchmarkAlertMatchesQuery_ByAlertname(b *testing.B) {
 	entry := alertstore.AlertEntry{
@@ -154,7 +154,7 @@ func BenchmarkAlertMatchesQuery_ByAlertname(b *testing.B) {
 	b.ResetTimer()
 	b.ReportAllocs()
 	for i := 0; i < b.N; i++ {
-		alertMatchesQuery(entry, "kubequota")
+		alertstore.MatchesQuery(entry, "kubequota")
 	}
 }
 
@@ -167,7 +167,7 @@ func BenchmarkAlertMatchesQuery_ByLabel(b *testing.B) {
 	b.ResetTimer()
 	b.ReportAllocs()
 	for i := 0; i < b.N; i++ {
-		alertMatchesQuery(entry, "platform")
+		alertstore.MatchesQuery(entry, "platform")
 	}
 }
 
@@ -180,7 +180,7 @@ func BenchmarkAlertMatchesQuery_NoMatch(b *testing.B) {
 	b.ResetTimer()
 	b.ReportAllocs()
 	for i := 0; i < b.N; i++ {
-		alertMatchesQuery(entry, "nonexistent")
+		alertstore.MatchesQuery(entry, "nonexistent")
 	}
 }
 
@@ -198,7 +198,7 @@ func BenchmarkAlertMatchesQuery_WithJobInfo(b *testing.B) {
 	b.ResetTimer()
 	b.ReportAllocs()
 	for i := 0; i < b.N; i++ {
-		alertMatchesQuery(entry, "worker")
+		alertstore.MatchesQuery(entry, "worker")
 	}
 }
 
@@ -0,0 +1,87 @@
+package memory
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/OpenFero/openfero/pkg/alertstore"
+)
+
+func TestUpdateJobInfo(t *testing.T) {
+	store := NewMemoryStore(10)
+
+	alert := alertstore.Alert{Labels: map[string]string{"alertname": "TestAlert"}}
+	if err := store.SaveAlertWithJobInfo(alert, "firing", &alertstore.JobInfo{JobName: "job-a", Namespace: "openfero"}); err != nil {
+		t.Fatalf("Failed to save alert: %v", err)
+	}
+	if err := store.SaveAlertWithJobInfo(alert, "firing", &alertstore.JobInfo{JobName: "job-b", Namespace: "openfero"}); err != nil {
+		t.Fatalf("Failed to save alert: %v", err)
+	}
+	if err := store.SaveAlert(alert, "resolved"); err != nil {
+		t.Fatalf("Failed to save alert: %v", err)
+	}
+
+	before, _ := store.GetAlerts("", 0)
+
+	failure := &alertstore.JobFailure{Reason: "ImagePullBackOff"}
+	err := store.UpdateJobInfo("openfero", "job-a", func(jobInfo *alertstore.JobInfo) bool {
+		jobInfo.Failure = failure
+		return true
+	})
+	if err != nil {
+		t.Fatalf("UpdateJobInfo returned error: %v", err)
+	}
+
+	after, _ := store.GetAlerts("", 0)
+	for _, entry := range after {
+		if entry.JobInfo == nil {
+			continue
+		}
+		switch entry.JobInfo.JobName {
+		case "job-a":
+			if entry.JobInfo.Failure != failure {
+				t.Errorf("Expected failure to be attached to job-a")
+			}
+		case "job-b":
+			if entry.JobInfo.Failure != nil {
+				t.Errorf("Expected job-b to be left untouched")
+			}
+		}
+	}
+
+	// Entries returned before the update must not observe it
+	for _, entry := range before {
+		if entry.JobInfo != nil && entry.JobInfo.Failure != nil {
+			t.Errorf("Previously returned entry for %s was modified", entry.JobInfo.JobName)
+		}
+	}
+}
+
+func TestGetAlert(t *testing.T) {
+	store := NewMemoryStore(10)
+
+	alert := alertstore.Alert{Labels: map[string]string{"alertname": "TestAlert"}}
+	if err := store.SaveAlert(alert, "firing"); err != nil {
+		t.Fatalf("Failed to save alert: %v", err)
+	}
+	if err := store.SaveAlert(alert, "resolved"); err != nil {
+		t.Fatalf("Failed to save alert: %v", err)
+	}
+
+	alerts, _ := store.GetAlerts("", 0)
+	if alerts[0].ID == "" || alerts[0].ID == alerts[1].ID {
+		t.Fatalf("Expected unique IDs, got %q and %q", alerts[0].ID, alerts[1].ID)
+	}
+
+	entry, err := store.GetAlert(alerts[1].ID)
+	if err != nil {
+		t.Fatalf("GetAlert returned error: %v", err)
+	}
+	if entry.Status != "firing" {
+		t.Errorf("Expected the firing alert, got status %s", entry.Status)
+	}
+
+	if _, err := store.GetAlert("unknown"); !errors.Is(err, alertstore.ErrAlertNotFound) {
+		t.Errorf("Expected ErrAlertNotFound, got %v", err)
+	}
+}
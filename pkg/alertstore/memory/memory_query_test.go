@@ -0,0 +1,14 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/OpenFero/openfero/pkg/alertstore"
+	"github.com/OpenFero/openfero/pkg/alertstore/storetest"
+)
+
+func TestQueryAlerts(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) alertstore.Store {
+		return NewMemoryStore(10)
+	})
+}
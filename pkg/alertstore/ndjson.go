@@ -0,0 +1,50 @@
+package alertstore
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// maxNDJSONLineSize bounds a single exported alert entry
+const maxNDJSONLineSize = 1024 * 1024
+
+// WriteNDJSON writes entries as newline-delimited JSON, one entry per line
+func WriteNDJSON(w io.Writer, entries []AlertEntry) error {
+	encoder := json.NewEncoder(w)
+	for i := range entries {
+		if err := encoder.Encode(entries[i]); err != nil {
+			return fmt.Errorf("failed to encode alert entry: %w", err)
+		}
+	}
+	return nil
+}
+
+// ReadNDJSON reads entries written by WriteNDJSON. Empty lines are ignored.
+func ReadNDJSON(r io.Reader) ([]AlertEntry, error) {
+	var entries []AlertEntry
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxNDJSONLineSize)
+	line := 0
+	for scanner.Scan() {
+		line++
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		var entry AlertEntry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			return nil, fmt.Errorf("invalid alert entry on line %d: %w", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read alert entries: %w", err)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,218 @@
+package alertstore
+
+import (
+	"cmp"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidCursor is returned by QueryAlerts for a cursor it didn't issue
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// SortOrder is the order of the entries a query returns
+type SortOrder string
+
+// Sort orders, by the time alerts were stored
+const (
+	SortNewestFirst SortOrder = "desc"
+	SortOldestFirst SortOrder = "asc"
+)
+
+// Job outcomes of stored alerts a query can filter by
+const (
+	// JobOutcomeNone is the outcome of alerts no Job was created for
+	JobOutcomeNone = "none"
+	// JobOutcomeSkipped is the outcome of alerts whose Job was deduplicated
+	JobOutcomeSkipped = "skipped"
+	JobOutcomePending = "pending"
+	JobOutcomeRunning = "running"
+	// JobOutcomeSuccessful, JobOutcomeFailed and JobOutcomeCancelled are
+	// the outcomes of finished Jobs
+	JobOutcomeSuccessful = "successful"
+	JobOutcomeFailed     = "failed"
+	JobOutcomeCancelled  = "cancelled"
+)
+
+// JobOutcome returns what became of the Job of an alert, one of the
+// JobOutcome constants
+func (e AlertEntry) JobOutcome() string {
+	if e.JobInfo == nil {
+		return JobOutcomeNone
+	}
+	status := strings.ToLower(e.JobInfo.LastExecutionStatus)
+	switch {
+	case status == "":
+		return JobOutcomePending
+	case strings.HasPrefix(status, JobOutcomeSkipped):
+		return JobOutcomeSkipped
+	}
+	return status
+}
+
+// Query selects stored alerts. All set filters must match.
+type Query struct {
+	// Text is a free text search like the query of GetAlerts
+	Text string
+	// Matchers must all match the labels of the alert
+	Matchers []*Matcher
+	// Status is the status the alert was stored with, firing or resolved
+	Status string
+	// Operarius is the name of the Operarius that handled the alert
+	Operarius string
+	// JobOutcome is one of the JobOutcome constants
+	JobOutcome string
+	// From and To limit the time the alert was stored at to [From, To)
+	From time.Time
+	To   time.Time
+
+	// Order defaults to SortNewestFirst
+	Order SortOrder
+	// Limit is the maximum number of entries returned, all if 0
+	Limit int
+	// Cursor continues a previous query after its last entry. The other
+	// fields must be unchanged.
+	Cursor string
+}
+
+// QueryResult is a page of entries selected by a Query
+type QueryResult struct {
+	Entries []AlertEntry `json:"entries"`
+	// NextCursor continues the query on the next page, empty on the last page
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// Matches reports whether an entry matches the filters of the query
+func (q Query) Matches(entry AlertEntry) bool {
+	if q.Status != "" && !strings.EqualFold(entry.Status, q.Status) {
+		return false
+	}
+	if q.Operarius != "" && (entry.JobInfo == nil || entry.JobInfo.OperariusName != q.Operarius) {
+		return false
+	}
+	if q.JobOutcome != "" && entry.JobOutcome() != strings.ToLower(q.JobOutcome) {
+		return false
+	}
+	if !q.InRange(entry.Timestamp) {
+		return false
+	}
+	if !MatchLabels(q.Matchers, entry.Alert.Labels) {
+		return false
+	}
+	return q.Text == "" || MatchesQuery(entry, q.Text)
+}
+
+// InRange reports whether a time is within [From, To)
+func (q Query) InRange(t time.Time) bool {
+	return (q.From.IsZero() || !t.Before(q.From)) && (q.To.IsZero() || t.Before(q.To))
+}
+
+// Descending reports whether the newest entries come first
+func (q Query) Descending() bool {
+	return q.Order != SortOldestFirst
+}
+
+// Validate checks the order and job outcome of the query
+func (q Query) Validate() error {
+	switch q.Order {
+	case "", SortNewestFirst, SortOldestFirst:
+	default:
+		return fmt.Errorf("invalid sort order %q, expected %s or %s", q.Order, SortNewestFirst, SortOldestFirst)
+	}
+	switch strings.ToLower(q.JobOutcome) {
+	case "", JobOutcomeNone, JobOutcomeSkipped, JobOutcomePending, JobOutcomeRunning,
+		JobOutcomeSuccessful, JobOutcomeFailed, JobOutcomeCancelled:
+	default:
+		return fmt.Errorf("invalid job outcome %q", q.JobOutcome)
+	}
+	if !q.From.IsZero() && !q.To.IsZero() && !q.From.Before(q.To) {
+		return errors.New("from must be before to")
+	}
+	return nil
+}
+
+// Apply runs the query on entries held in memory. Entries are ordered by the
+// time they were stored, ties by ID, which the cursor refers to.
+func (q Query) Apply(entries []AlertEntry) (QueryResult, error) {
+	if err := q.Validate(); err != nil {
+		return QueryResult{}, err
+	}
+	var after *CursorPosition
+	if q.Cursor != "" {
+		position, err := DecodeCursor(q.Cursor)
+		if err != nil {
+			return QueryResult{}, err
+		}
+		after = &position
+	}
+
+	selected := make([]AlertEntry, 0)
+	for _, entry := range entries {
+		if q.Matches(entry) {
+			selected = append(selected, entry)
+		}
+	}
+	compare := func(a, b AlertEntry) int {
+		return cmp.Or(a.Timestamp.Compare(b.Timestamp), strings.Compare(a.ID, b.ID))
+	}
+	slices.SortFunc(selected, func(a, b AlertEntry) int {
+		if q.Descending() {
+			return compare(b, a)
+		}
+		return compare(a, b)
+	})
+
+	if after != nil {
+		position := AlertEntry{Timestamp: after.Timestamp, ID: after.Key}
+		start := len(selected)
+		for i, entry := range selected {
+			if c := compare(entry, position); (q.Descending() && c < 0) || (!q.Descending() && c > 0) {
+				start = i
+				break
+			}
+		}
+		selected = selected[start:]
+	}
+
+	result := QueryResult{Entries: selected}
+	if q.Limit > 0 && len(selected) > q.Limit {
+		result.Entries = selected[:q.Limit]
+		last := result.Entries[q.Limit-1]
+		result.NextCursor = EncodeCursor(CursorPosition{Timestamp: last.Timestamp, Key: last.ID})
+	}
+	return result, nil
+}
+
+// CursorPosition is the last entry of a page. Key breaks ties between
+// entries stored at the same time, its meaning is up to the store.
+type CursorPosition struct {
+	Timestamp time.Time
+	Key       string
+}
+
+// EncodeCursor encodes a position as an opaque cursor
+func EncodeCursor(position CursorPosition) string {
+	raw := strconv.FormatInt(position.Timestamp.UnixNano(), 10) + ":" + position.Key
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeCursor decodes a cursor issued by EncodeCursor
+func DecodeCursor(cursor string) (CursorPosition, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return CursorPosition{}, ErrInvalidCursor
+	}
+	nanos, key, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return CursorPosition{}, ErrInvalidCursor
+	}
+	unixNano, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return CursorPosition{}, ErrInvalidCursor
+	}
+	return CursorPosition{Timestamp: time.Unix(0, unixNano), Key: key}, nil
+}
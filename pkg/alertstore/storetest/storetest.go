@@ -0,0 +1,187 @@
+// Package storetest holds conformance tests every alert store must pass
+package storetest
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/OpenFero/openfero/pkg/alertstore"
+)
+
+// stored describes an alert saved by the conformance tests. Entries are
+// told apart by their "n" label, which is their index.
+type stored struct {
+	alertname string
+	severity  string
+	status    string
+	jobInfo   *alertstore.JobInfo
+}
+
+var fixtures = []stored{
+	{"Disk", "warning", "firing", &alertstore.JobInfo{OperariusName: "disk-operarius", LastExecutionStatus: "Successful"}},
+	{"CPU", "critical", "firing", &alertstore.JobInfo{OperariusName: "cpu-operarius", LastExecutionStatus: "Failed"}},
+	{"Disk", "critical", "resolved", nil},
+	{"Memory", "warning", "firing", &alertstore.JobInfo{OperariusName: "disk-operarius", LastExecutionStatus: "Skipped: Deduplication"}},
+	{"Disk", "critical", "firing", &alertstore.JobInfo{OperariusName: "disk-operarius", LastExecutionStatus: "Running"}},
+	{"CPU", "warning", "resolved", nil},
+}
+
+// Run runs the conformance tests of QueryAlerts against stores created by
+// newStore. Each call must return an empty store holding at least 10 alerts.
+func Run(t *testing.T, newStore func(t *testing.T) alertstore.Store) {
+	store := newStore(t)
+	var middle time.Time
+	for i, fixture := range fixtures {
+		alert := alertstore.Alert{Labels: map[string]string{
+			"alertname": fixture.alertname,
+			"severity":  fixture.severity,
+			"n":         strconv.Itoa(i),
+		}}
+		if err := store.SaveAlertWithJobInfo(alert, fixture.status, fixture.jobInfo); err != nil {
+			t.Fatalf("Failed to save alert: %v", err)
+		}
+		// Separate the first and second half in time
+		if i == 2 {
+			time.Sleep(5 * time.Millisecond)
+			middle = time.Now()
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		query    alertstore.Query
+		expected []string
+	}{
+		{"newest first by default", alertstore.Query{}, []string{"5", "4", "3", "2", "1", "0"}},
+		{"oldest first", alertstore.Query{Order: alertstore.SortOldestFirst}, []string{"0", "1", "2", "3", "4", "5"}},
+		{"equality matcher", alertstore.Query{Matchers: matchers(t, `alertname="Disk"`)}, []string{"4", "2", "0"}},
+		{"several matchers", alertstore.Query{Matchers: matchers(t, `alertname="Disk", severity="critical"`)}, []string{"4", "2"}},
+		{"regexp matcher", alertstore.Query{Matchers: matchers(t, `alertname=~"CPU|Memory"`)}, []string{"5", "3", "1"}},
+		{"negative matcher", alertstore.Query{Matchers: matchers(t, `alertname!="Disk", severity!~"crit.*"`)}, []string{"5", "3"}},
+		{"status", alertstore.Query{Status: "resolved"}, []string{"5", "2"}},
+		{"status and matcher", alertstore.Query{Status: "firing", Matchers: matchers(t, `alertname="Disk"`), Order: alertstore.SortOldestFirst}, []string{"0", "4"}},
+		{"operarius", alertstore.Query{Operarius: "disk-operarius"}, []string{"4", "3", "0"}},
+		{"no job", alertstore.Query{JobOutcome: alertstore.JobOutcomeNone}, []string{"5", "2"}},
+		{"failed job", alertstore.Query{JobOutcome: alertstore.JobOutcomeFailed}, []string{"1"}},
+		{"skipped job", alertstore.Query{JobOutcome: alertstore.JobOutcomeSkipped}, []string{"3"}},
+		{"running job", alertstore.Query{JobOutcome: "Running"}, []string{"4"}},
+		{"from", alertstore.Query{From: middle}, []string{"5", "4", "3"}},
+		{"to", alertstore.Query{To: middle}, []string{"2", "1", "0"}},
+		{"from with matcher", alertstore.Query{From: middle, Matchers: matchers(t, `alertname="Disk"`), Order: alertstore.SortOldestFirst}, []string{"4"}},
+		{"to with status", alertstore.Query{To: middle, Status: "resolved"}, []string{"2"}},
+		{"text", alertstore.Query{Text: "memory"}, []string{"3"}},
+		{"no match", alertstore.Query{Matchers: matchers(t, `alertname="Network"`)}, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := store.QueryAlerts(tt.query)
+			if err != nil {
+				t.Fatalf("QueryAlerts returned error: %v", err)
+			}
+			if got := numbers(result.Entries); !slices.Equal(got, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+			if result.NextCursor != "" {
+				t.Errorf("Expected no cursor without a limit, got %q", result.NextCursor)
+			}
+		})
+
+		// Paging through the result must return the same entries
+		for _, limit := range []int{1, 2, 3} {
+			t.Run(tt.name+" paginated by "+strconv.Itoa(limit), func(t *testing.T) {
+				query := tt.query
+				query.Limit = limit
+				var got []string
+				for page := 0; ; page++ {
+					if page > len(fixtures) {
+						t.Fatalf("Pagination did not terminate")
+					}
+					result, err := store.QueryAlerts(query)
+					if err != nil {
+						t.Fatalf("QueryAlerts returned error: %v", err)
+					}
+					if len(result.Entries) > limit {
+						t.Fatalf("Expected at most %d entries, got %d", limit, len(result.Entries))
+					}
+					got = append(got, numbers(result.Entries)...)
+					if result.NextCursor == "" {
+						break
+					}
+					query.Cursor = result.NextCursor
+				}
+				if got == nil {
+					got = []string{}
+				}
+				if !slices.Equal(got, tt.expected) {
+					t.Errorf("Expected %v, got %v", tt.expected, got)
+				}
+			})
+		}
+	}
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		_, err := store.QueryAlerts(alertstore.Query{Cursor: "not a cursor"})
+		if !errors.Is(err, alertstore.ErrInvalidCursor) {
+			t.Errorf("Expected ErrInvalidCursor, got %v", err)
+		}
+	})
+
+	t.Run("invalid query", func(t *testing.T) {
+		for _, query := range []alertstore.Query{
+			{Order: "sideways"},
+			{JobOutcome: "exploded"},
+			{From: middle, To: middle.Add(-time.Second)},
+		} {
+			if _, err := store.QueryAlerts(query); err == nil {
+				t.Errorf("Expected an error for %+v", query)
+			}
+		}
+	})
+
+	t.Run("new entries don't shift pages", func(t *testing.T) {
+		store := newStore(t)
+		for i := range 3 {
+			alert := alertstore.Alert{Labels: map[string]string{"alertname": "Paged", "n": strconv.Itoa(i)}}
+			if err := store.SaveAlert(alert, "firing"); err != nil {
+				t.Fatalf("Failed to save alert: %v", err)
+			}
+		}
+		first, err := store.QueryAlerts(alertstore.Query{Limit: 2})
+		if err != nil {
+			t.Fatalf("QueryAlerts returned error: %v", err)
+		}
+		if err := store.SaveAlert(alertstore.Alert{Labels: map[string]string{"alertname": "Paged", "n": "3"}}, "firing"); err != nil {
+			t.Fatalf("Failed to save alert: %v", err)
+		}
+		second, err := store.QueryAlerts(alertstore.Query{Limit: 2, Cursor: first.NextCursor})
+		if err != nil {
+			t.Fatalf("QueryAlerts returned error: %v", err)
+		}
+		got := append(numbers(first.Entries), numbers(second.Entries)...)
+		if expected := []string{"2", "1", "0"}; !slices.Equal(got, expected) {
+			t.Errorf("Expected %v, got %v", expected, got)
+		}
+	})
+}
+
+func matchers(t *testing.T, input string) []*alertstore.Matcher {
+	t.Helper()
+	m, err := alertstore.ParseMatchers(input)
+	if err != nil {
+		t.Fatalf("Failed to parse matchers %q: %v", input, err)
+	}
+	return m
+}
+
+func numbers(entries []alertstore.AlertEntry) []string {
+	n := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		n = append(n, entry.Alert.Labels["n"])
+	}
+	return n
+}
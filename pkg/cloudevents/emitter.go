@@ -0,0 +1,193 @@
+package cloudevents
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	log "github.com/OpenFero/openfero/pkg/logging"
+	"github.com/OpenFero/openfero/pkg/metadata"
+)
+
+// Types of the lifecycle events OpenFero emits
+const (
+	TypeAlertReceived   = "io.openfero.alert.received"
+	TypeJobCreated      = "io.openfero.job.created"
+	TypeJobSucceeded    = "io.openfero.job.succeeded"
+	TypeJobFailed       = "io.openfero.job.failed"
+	TypeJobDeduplicated = "io.openfero.job.deduplicated"
+)
+
+const (
+	// emitAttempts is the number of times delivering an event is tried
+	emitAttempts = 3
+	// emitBackoff is the wait before the first retry, doubled for each further retry
+	emitBackoff = 500 * time.Millisecond
+)
+
+// JobData is the data of the job lifecycle events
+type JobData struct {
+	Operarius  string `json:"operarius,omitempty"`
+	Namespace  string `json:"namespace"`
+	JobName    string `json:"jobName,omitempty"`
+	GroupKey   string `json:"groupKey,omitempty"`
+	DeliveryID string `json:"deliveryId,omitempty"`
+	Reason     string `json:"reason,omitempty"`
+}
+
+// Emitter sends events in binary mode to an HTTP sink. Events are sent in the
+// background, so emitting never blocks the caller; events are dropped while
+// the buffer is full. A nil Emitter discards all events.
+type Emitter struct {
+	sink   string
+	source string
+	client *http.Client
+	events chan Event
+
+	mu     sync.RWMutex
+	closed bool
+	done   chan struct{}
+}
+
+// NewEmitter creates an emitter sending events with the given source
+// attribute to sink, buffering up to bufferSize events
+func NewEmitter(sink, source string, bufferSize int) *Emitter {
+	if bufferSize < 1 {
+		bufferSize = 1
+	}
+	e := &Emitter{
+		sink:   sink,
+		source: source,
+		client: &http.Client{Timeout: 10 * time.Second},
+		events: make(chan Event, bufferSize),
+		done:   make(chan struct{}),
+	}
+	go e.run()
+	return e
+}
+
+// Emit queues an event of the given type. Data is encoded as JSON.
+func (e *Emitter) Emit(eventType, subject string, data any) {
+	if e == nil {
+		return
+	}
+
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		log.Error("Failed to encode CloudEvent data", "type", eventType, "error", err)
+		return
+	}
+	id, err := newEventID()
+	if err != nil {
+		log.Error("Failed to create CloudEvent", "type", eventType, "error", err)
+		return
+	}
+	event := Event{
+		ID:              id,
+		Source:          e.source,
+		SpecVersion:     SpecVersion,
+		Type:            eventType,
+		Subject:         subject,
+		Time:            time.Now(),
+		DataContentType: "application/json",
+		Data:            encoded,
+	}
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closed {
+		return
+	}
+	select {
+	case e.events <- event:
+	default:
+		log.Warn("Dropping CloudEvent, buffer is full", "type", eventType, "subject", subject)
+		metadata.CloudEventsEmittedTotal.WithLabelValues(eventType, "dropped").Inc()
+	}
+}
+
+// Close stops accepting events and waits until the buffered events are sent
+// or ctx expires
+func (e *Emitter) Close(ctx context.Context) error {
+	if e == nil {
+		return nil
+	}
+	e.mu.Lock()
+	if !e.closed {
+		e.closed = true
+		close(e.events)
+	}
+	e.mu.Unlock()
+
+	select {
+	case <-e.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// run sends the buffered events until the emitter is closed
+func (e *Emitter) run() {
+	defer close(e.done)
+	for event := range e.events {
+		result := "sent"
+		if err := e.send(event); err != nil {
+			log.Warn("Failed to send CloudEvent", "type", event.Type, "subject", event.Subject, "sink", e.sink, "error", err)
+			result = "failed"
+		}
+		metadata.CloudEventsEmittedTotal.WithLabelValues(event.Type, result).Inc()
+	}
+}
+
+// send delivers an event, retrying on network errors and 5xx or 429 responses
+func (e *Emitter) send(event Event) error {
+	var err error
+	backoff := emitBackoff
+	for attempt := 1; attempt <= emitAttempts; attempt++ {
+		var retry bool
+		if retry, err = e.post(event); err == nil || !retry {
+			return err
+		}
+		if attempt < emitAttempts {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+	}
+	return err
+}
+
+// post delivers an event once and reports whether a failure is worth retrying
+func (e *Emitter) post(event Event) (bool, error) {
+	req, err := event.NewRequest(e.sink)
+	if err != nil {
+		return false, err
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("sink responded with %s", resp.Status)
+}
+
+// newEventID returns a random event ID
+func newEventID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,302 @@
+// Package cloudevents implements the parts of the CloudEvents 1.0 HTTP protocol
+// binding OpenFero needs to accept events as triggers and to emit its own
+// lifecycle events.
+package cloudevents
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// SpecVersion is the supported version of the CloudEvents specification
+	SpecVersion = "1.0"
+
+	// ContentTypeStructured is the media type of events in structured mode
+	ContentTypeStructured = "application/cloudevents+json"
+	// ContentTypeBatch is the media type of batched events, which are not supported
+	ContentTypeBatch = "application/cloudevents-batch+json"
+
+	// headerPrefix prefixes the attributes of events in binary mode
+	headerPrefix = "Ce-"
+)
+
+// Event is a CloudEvent. Data holds the JSON encoded data, or a JSON string
+// if the data isn't JSON.
+type Event struct {
+	ID              string
+	Source          string
+	SpecVersion     string
+	Type            string
+	Subject         string
+	Time            time.Time
+	DataContentType string
+	DataSchema      string
+	Data            json.RawMessage
+	// Extensions holds extension attributes by their lowercase name
+	Extensions map[string]string
+}
+
+// contextAttributes are the attributes the specification defines, everything
+// else is an extension
+var contextAttributes = map[string]bool{
+	"id": true, "source": true, "specversion": true, "type": true, "subject": true,
+	"time": true, "datacontenttype": true, "dataschema": true, "data": true, "data_base64": true,
+}
+
+// Validate checks the required attributes are present
+func (e *Event) Validate() error {
+	var missing []string
+	for name, value := range map[string]string{"id": e.ID, "source": e.Source, "specversion": e.SpecVersion, "type": e.Type} {
+		if value == "" {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		slices.Sort(missing)
+		return fmt.Errorf("event is missing required attributes: %s", strings.Join(missing, ", "))
+	}
+	if e.SpecVersion != SpecVersion {
+		return fmt.Errorf("unsupported specversion %q", e.SpecVersion)
+	}
+	return nil
+}
+
+// MarshalJSON encodes the event in the JSON event format of structured mode
+func (e Event) MarshalJSON() ([]byte, error) {
+	attributes := make(map[string]any, len(e.Extensions)+9)
+	for name, value := range e.Extensions {
+		attributes[name] = value
+	}
+	attributes["id"] = e.ID
+	attributes["source"] = e.Source
+	attributes["specversion"] = e.SpecVersion
+	attributes["type"] = e.Type
+	setIfNotEmpty(attributes, "subject", e.Subject)
+	setIfNotEmpty(attributes, "datacontenttype", e.DataContentType)
+	setIfNotEmpty(attributes, "dataschema", e.DataSchema)
+	if !e.Time.IsZero() {
+		attributes["time"] = e.Time.UTC().Format(time.RFC3339Nano)
+	}
+	if len(e.Data) > 0 {
+		attributes["data"] = e.Data
+	}
+	return json.Marshal(attributes)
+}
+
+// UnmarshalJSON decodes an event in the JSON event format
+func (e *Event) UnmarshalJSON(data []byte) error {
+	var attributes map[string]json.RawMessage
+	if err := json.Unmarshal(data, &attributes); err != nil {
+		return err
+	}
+
+	*e = Event{Extensions: make(map[string]string)}
+	str := func(name string) (string, error) {
+		raw, ok := attributes[name]
+		if !ok {
+			return "", nil
+		}
+		var value string
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return "", fmt.Errorf("attribute %s must be a string", name)
+		}
+		return value, nil
+	}
+
+	var err error
+	for name, field := range map[string]*string{
+		"id": &e.ID, "source": &e.Source, "specversion": &e.SpecVersion, "type": &e.Type,
+		"subject": &e.Subject, "datacontenttype": &e.DataContentType, "dataschema": &e.DataSchema,
+	} {
+		if *field, err = str(name); err != nil {
+			return err
+		}
+	}
+	if value, err := str("time"); err != nil {
+		return err
+	} else if value != "" {
+		if e.Time, err = time.Parse(time.RFC3339Nano, value); err != nil {
+			return fmt.Errorf("invalid time: %w", err)
+		}
+	}
+
+	if raw, ok := attributes["data"]; ok {
+		e.Data = raw
+	} else if value, err := str("data_base64"); err != nil {
+		return err
+	} else if value != "" {
+		// Binary data isn't JSON, keep it as the base64 encoded string
+		e.Data, _ = json.Marshal(value)
+	}
+
+	for name, raw := range attributes {
+		if contextAttributes[name] {
+			continue
+		}
+		var value any
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return err
+		}
+		e.Extensions[name] = fmt.Sprint(value)
+	}
+	return nil
+}
+
+// FromRequest reads an event from an HTTP request in binary or structured mode
+func FromRequest(r *http.Request) (Event, error) {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil && r.Header.Get("Content-Type") != "" {
+		return Event{}, fmt.Errorf("invalid content type: %w", err)
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return Event{}, fmt.Errorf("failed to read event: %w", err)
+	}
+
+	var event Event
+	switch {
+	case mediaType == ContentTypeBatch:
+		return Event{}, errors.New("batched events are not supported")
+	case mediaType == ContentTypeStructured:
+		if err := json.Unmarshal(body, &event); err != nil {
+			return Event{}, fmt.Errorf("invalid structured event: %w", err)
+		}
+	default:
+		event = fromHeaders(r.Header)
+		event.DataContentType = r.Header.Get("Content-Type")
+		if event.Time, err = parseTime(r.Header.Get(headerPrefix + "Time")); err != nil {
+			return Event{}, err
+		}
+		if event.Data, err = binaryData(mediaType, body); err != nil {
+			return Event{}, err
+		}
+	}
+
+	if err := event.Validate(); err != nil {
+		return Event{}, err
+	}
+	return event, nil
+}
+
+// NewRequest returns a request delivering the event in binary mode
+func (e *Event) NewRequest(target string) (*http.Request, error) {
+	req, err := http.NewRequest(http.MethodPost, target, bytes.NewReader(e.Data))
+	if err != nil {
+		return nil, err
+	}
+	attributes := map[string]string{
+		"Id":          e.ID,
+		"Source":      e.Source,
+		"Specversion": e.SpecVersion,
+		"Type":        e.Type,
+		"Subject":     e.Subject,
+		"Dataschema":  e.DataSchema,
+	}
+	if !e.Time.IsZero() {
+		attributes["Time"] = e.Time.UTC().Format(time.RFC3339Nano)
+	}
+	for name, value := range e.Extensions {
+		attributes[name] = value
+	}
+	for name, value := range attributes {
+		if value != "" {
+			req.Header.Set(headerPrefix+name, encodeHeaderValue(value))
+		}
+	}
+	if e.DataContentType != "" {
+		req.Header.Set("Content-Type", e.DataContentType)
+	}
+	return req, nil
+}
+
+// fromHeaders reads the attributes of an event in binary mode
+func fromHeaders(header http.Header) Event {
+	event := Event{Extensions: make(map[string]string)}
+	for key, values := range header {
+		canonical := http.CanonicalHeaderKey(key)
+		if !strings.HasPrefix(canonical, headerPrefix) || len(values) == 0 {
+			continue
+		}
+		// Header values are percent-encoded
+		value, err := url.PathUnescape(values[0])
+		if err != nil {
+			value = values[0]
+		}
+		switch name := strings.ToLower(strings.TrimPrefix(canonical, headerPrefix)); name {
+		case "id":
+			event.ID = value
+		case "source":
+			event.Source = value
+		case "specversion":
+			event.SpecVersion = value
+		case "type":
+			event.Type = value
+		case "subject":
+			event.Subject = value
+		case "dataschema":
+			event.DataSchema = value
+		case "time":
+		default:
+			event.Extensions[name] = value
+		}
+	}
+	return event
+}
+
+// binaryData keeps JSON data as it is and encodes other data as JSON string
+func binaryData(mediaType string, body []byte) (json.RawMessage, error) {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil, nil
+	}
+	if mediaType == "" || mediaType == "application/json" || strings.HasSuffix(mediaType, "+json") {
+		if !json.Valid(body) {
+			return nil, errors.New("event data is not valid JSON")
+		}
+		return body, nil
+	}
+	return json.Marshal(string(body))
+}
+
+// parseTime parses the optional time attribute
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time: %w", err)
+	}
+	return t, nil
+}
+
+func setIfNotEmpty(attributes map[string]any, name, value string) {
+	if value != "" {
+		attributes[name] = value
+	}
+}
+
+// encodeHeaderValue percent-encodes the characters the HTTP binding requires
+// to be encoded in header values
+func encodeHeaderValue(value string) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if c <= ' ' || c > '~' || c == '"' || c == '%' {
+			fmt.Fprintf(&b, "%%%02X", c)
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
@@ -0,0 +1,159 @@
+package cloudevents
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromRequest(t *testing.T) {
+	t.Run("binary mode", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"disk":"sda"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("ce-id", "42")
+		req.Header.Set("ce-source", "//monitoring/node-1")
+		req.Header.Set("ce-specversion", "1.0")
+		req.Header.Set("ce-type", "com.example.disk.full")
+		req.Header.Set("ce-time", "2024-01-02T03:04:05Z")
+		req.Header.Set("ce-severity", "critical%20now")
+
+		event, err := FromRequest(req)
+		require.NoError(t, err)
+		assert.Equal(t, "42", event.ID)
+		assert.Equal(t, "//monitoring/node-1", event.Source)
+		assert.Equal(t, "com.example.disk.full", event.Type)
+		assert.Equal(t, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), event.Time)
+		assert.Equal(t, map[string]string{"severity": "critical now"}, event.Extensions)
+		assert.JSONEq(t, `{"disk":"sda"}`, string(event.Data))
+	})
+
+	t.Run("binary mode with text data", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("disk full"))
+		req.Header.Set("Content-Type", "text/plain")
+		req.Header.Set("ce-id", "42")
+		req.Header.Set("ce-source", "monitoring")
+		req.Header.Set("ce-specversion", "1.0")
+		req.Header.Set("ce-type", "com.example.disk.full")
+
+		event, err := FromRequest(req)
+		require.NoError(t, err)
+		assert.Equal(t, `"disk full"`, string(event.Data))
+	})
+
+	t.Run("structured mode", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{
+			"specversion": "1.0",
+			"id": "42",
+			"source": "monitoring",
+			"type": "com.example.disk.full",
+			"subject": "node-1",
+			"severity": "critical",
+			"data": {"disk": "sda"}
+		}`))
+		req.Header.Set("Content-Type", "application/cloudevents+json; charset=utf-8")
+
+		event, err := FromRequest(req)
+		require.NoError(t, err)
+		assert.Equal(t, "node-1", event.Subject)
+		assert.Equal(t, map[string]string{"severity": "critical"}, event.Extensions)
+		assert.JSONEq(t, `{"disk":"sda"}`, string(event.Data))
+	})
+
+	t.Run("invalid events", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("ce-id", "42")
+		_, err := FromRequest(req)
+		assert.ErrorContains(t, err, "source, specversion, type")
+
+		req = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`[]`))
+		req.Header.Set("Content-Type", ContentTypeBatch)
+		_, err = FromRequest(req)
+		assert.ErrorContains(t, err, "batched")
+
+		req = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"specversion":"0.3","id":"1","source":"s","type":"t"}`))
+		req.Header.Set("Content-Type", ContentTypeStructured)
+		_, err = FromRequest(req)
+		assert.ErrorContains(t, err, "specversion")
+	})
+}
+
+func TestEvent_RoundTrip(t *testing.T) {
+	event := Event{
+		ID:              "42",
+		Source:          "/openfero",
+		SpecVersion:     SpecVersion,
+		Type:            TypeJobCreated,
+		Subject:         "openfero/job-1",
+		Time:            time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		DataContentType: "application/json",
+		Data:            json.RawMessage(`{"jobName":"job-1"}`),
+		Extensions:      map[string]string{"traceparent": "00-abc \"quoted\""},
+	}
+
+	req, err := event.NewRequest("http://sink")
+	require.NoError(t, err)
+	binary, err := FromRequest(req)
+	require.NoError(t, err)
+	assert.Equal(t, event, binary)
+
+	data, err := json.Marshal(event)
+	require.NoError(t, err)
+	var structured Event
+	require.NoError(t, json.Unmarshal(data, &structured))
+	assert.Equal(t, event, structured)
+}
+
+func TestEmitter(t *testing.T) {
+	var mu sync.Mutex
+	var received []Event
+	attempts := 0
+	sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		if attempts == 1 {
+			// The first delivery fails and is retried
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		event, err := FromRequest(r)
+		if !assert.NoError(t, err) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		received = append(received, event)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer sink.Close()
+
+	emitter := NewEmitter(sink.URL, "/openfero", 10)
+	emitter.Emit(TypeJobCreated, "openfero/job-1", JobData{Namespace: "openfero", JobName: "job-1"})
+	emitter.Emit(TypeJobSucceeded, "openfero/job-1", JobData{Namespace: "openfero", JobName: "job-1"})
+	require.NoError(t, emitter.Close(context.Background()))
+	emitter.Emit(TypeJobFailed, "ignored", nil)
+
+	require.Len(t, received, 2)
+	assert.Equal(t, TypeJobCreated, received[0].Type)
+	assert.Equal(t, "/openfero", received[0].Source)
+	assert.Equal(t, "openfero/job-1", received[0].Subject)
+	assert.NotEmpty(t, received[0].ID)
+	assert.False(t, received[0].Time.IsZero())
+	assert.JSONEq(t, `{"namespace":"openfero","jobName":"job-1"}`, string(received[0].Data))
+	assert.Equal(t, TypeJobSucceeded, received[1].Type)
+	assert.NotEqual(t, received[0].ID, received[1].ID)
+}
+
+func TestEmitter_Nil(t *testing.T) {
+	var emitter *Emitter
+	emitter.Emit(TypeAlertReceived, "group", nil)
+	assert.NoError(t, emitter.Close(context.Background()))
+}
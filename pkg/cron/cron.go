@@ -0,0 +1,228 @@
+// Package cron parses standard five-field cron schedules, as used by
+// Kubernetes CronJobs, and computes their activation times.
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule is a parsed cron schedule. Each field is a bit set of the values it matches.
+type Schedule struct {
+	minute, hour, dom, month, dow uint64
+	// domStar and dowStar record whether the day fields are unrestricted; if
+	// both are restricted, a day matches if either field matches
+	domStar, dowStar bool
+	location         *time.Location
+}
+
+// field describes the range and names of a schedule field
+type field struct {
+	name     string
+	min, max uint
+	names    map[string]uint
+}
+
+var (
+	minuteField = field{name: "minute", min: 0, max: 59}
+	hourField   = field{name: "hour", min: 0, max: 23}
+	domField    = field{name: "day of month", min: 1, max: 31}
+	monthField  = field{name: "month", min: 1, max: 12, names: map[string]uint{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	dowField = field{name: "day of week", min: 0, max: 7, names: map[string]uint{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+// macros are the predefined schedules
+var macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// maxSearchYears bounds the search for the next activation, schedules like
+// "0 0 30 2 *" never activate
+const maxSearchYears = 5
+
+// Parse parses a schedule in the given location. A nil location means UTC.
+func Parse(spec string, location *time.Location) (*Schedule, error) {
+	if location == nil {
+		location = time.UTC
+	}
+	spec = strings.TrimSpace(spec)
+	if macro, ok := macros[strings.ToLower(spec)]; ok {
+		spec = macro
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid schedule %q: expected 5 fields, got %d", spec, len(fields))
+	}
+
+	s := &Schedule{location: location}
+	var err error
+	if s.minute, err = parseField(fields[0], minuteField); err != nil {
+		return nil, err
+	}
+	if s.hour, err = parseField(fields[1], hourField); err != nil {
+		return nil, err
+	}
+	if s.dom, err = parseField(fields[2], domField); err != nil {
+		return nil, err
+	}
+	if s.month, err = parseField(fields[3], monthField); err != nil {
+		return nil, err
+	}
+	if s.dow, err = parseField(fields[4], dowField); err != nil {
+		return nil, err
+	}
+	// Sunday is 0 and 7
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	s.domStar = isStar(fields[2])
+	s.dowStar = isStar(fields[4])
+	return s, nil
+}
+
+// Next returns the first activation after t, or the zero time if the
+// schedule doesn't activate within the next years
+func (s *Schedule) Next(t time.Time) time.Time {
+	t = t.In(s.location).Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(maxSearchYears, 0, 0)
+
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = s.date(t.Year(), t.Month()+1, 1, 0)
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = s.date(t.Year(), t.Month(), t.Day()+1, 0)
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			// Hours start at local time, which isn't a multiple of an
+			// hour since the epoch in zones like Asia/Kolkata
+			t = s.date(t.Year(), t.Month(), t.Day(), t.Hour()+1)
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// date returns the start of an hour in the schedule's location. An hour
+// skipped by a DST change starts where the change ends; time.Date would
+// return a time before it.
+func (s *Schedule) date(year int, month time.Month, day, hour int) time.Time {
+	wall := time.Date(year, month, day, hour, 0, 0, 0, time.UTC)
+	t := time.Date(year, month, day, hour, 0, 0, 0, s.location)
+	if t.Day() != wall.Day() || t.Hour() != wall.Hour() {
+		_, end := t.ZoneBounds()
+		return end
+	}
+	return t
+}
+
+// dayMatches applies the cron rule for the two day fields
+func (s *Schedule) dayMatches(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+// parseField parses a comma separated list of values, ranges and steps
+func parseField(value string, f field) (uint64, error) {
+	var set uint64
+	for part := range strings.SplitSeq(value, ",") {
+		bitsOfPart, err := parseRange(part, f)
+		if err != nil {
+			return 0, fmt.Errorf("invalid %s %q: %w", f.name, value, err)
+		}
+		set |= bitsOfPart
+	}
+	return set, nil
+}
+
+// parseRange parses "*", "a", "a-b" with an optional "/step"
+func parseRange(part string, f field) (uint64, error) {
+	rangePart, stepPart, hasStep := strings.Cut(part, "/")
+	step := uint(1)
+	if hasStep {
+		n, err := strconv.ParseUint(stepPart, 10, 8)
+		if err != nil || n == 0 {
+			return 0, fmt.Errorf("invalid step %q", stepPart)
+		}
+		step = uint(n)
+	}
+
+	var low, high uint
+	switch {
+	case rangePart == "*" || rangePart == "?":
+		low, high = f.min, f.max
+	case strings.Contains(rangePart, "-"):
+		lowPart, highPart, _ := strings.Cut(rangePart, "-")
+		var err error
+		if low, err = parseValue(lowPart, f); err != nil {
+			return 0, err
+		}
+		if high, err = parseValue(highPart, f); err != nil {
+			return 0, err
+		}
+		if low > high {
+			return 0, fmt.Errorf("range %q is reversed", rangePart)
+		}
+	default:
+		var err error
+		if low, err = parseValue(rangePart, f); err != nil {
+			return 0, err
+		}
+		high = low
+		// "a/step" means from a to the end of the range
+		if hasStep {
+			high = f.max
+		}
+	}
+
+	var set uint64
+	for v := low; v <= high; v += step {
+		set |= 1 << v
+	}
+	return set, nil
+}
+
+// parseValue parses a number or name within the range of a field
+func parseValue(value string, f field) (uint, error) {
+	if n, ok := f.names[strings.ToLower(value)]; ok {
+		return n, nil
+	}
+	n, err := strconv.ParseUint(value, 10, 8)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", value)
+	}
+	if uint(n) < f.min || uint(n) > f.max {
+		return 0, fmt.Errorf("value %d out of range %d-%d", n, f.min, f.max)
+	}
+	return uint(n), nil
+}
+
+// isStar reports whether a day field matches every day
+func isStar(value string) bool {
+	return value == "*" || value == "?" || value == "*/1"
+}
@@ -0,0 +1,112 @@
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchedule_Next(t *testing.T) {
+	// Thursday
+	from := time.Date(2024, 2, 29, 10, 30, 15, 0, time.UTC)
+
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2024, 2, 29, 10, 31, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2024, 2, 29, 10, 45, 0, 0, time.UTC)},
+		{"0 9-17/4 * * *", time.Date(2024, 2, 29, 13, 0, 0, 0, time.UTC)},
+		{"30 10 * * *", time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC)},
+		{"0 0 1,15 * *", time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 12 * * mon-fri", time.Date(2024, 2, 29, 12, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2024, 3, 3, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * dec *", time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC)},
+		{"5/20 * * * *", time.Date(2024, 2, 29, 10, 45, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2024, 2, 29, 11, 0, 0, 0, time.UTC)},
+		{"@yearly", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		// Either day field matches if both are restricted
+		{"0 0 13 * fri", time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			schedule, err := Parse(tt.spec, nil)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, schedule.Next(from))
+		})
+	}
+}
+
+func TestSchedule_NextInLocation(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+	schedule, err := Parse("0 2 * * *", berlin)
+	require.NoError(t, err)
+
+	next := schedule.Next(time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC))
+	assert.Equal(t, time.Date(2024, 6, 2, 0, 0, 0, 0, time.UTC), next.UTC())
+}
+
+func TestSchedule_NextInHalfHourZone(t *testing.T) {
+	kolkata, err := time.LoadLocation("Asia/Kolkata")
+	require.NoError(t, err)
+	schedule, err := Parse("0 11 * * *", kolkata)
+	require.NoError(t, err)
+
+	next := schedule.Next(time.Date(2024, 6, 1, 10, 45, 0, 0, kolkata))
+	assert.Equal(t, time.Date(2024, 6, 1, 11, 0, 0, 0, kolkata), next)
+}
+
+func TestSchedule_NextAcrossDSTTransitions(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	santiago, err := time.LoadLocation("America/Santiago")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		spec string
+		from time.Time
+		want time.Time
+	}{
+		// Clocks jump from 2:00 to 3:00 on 2024-03-10
+		{"hourly over the gap", "0 * * * *", time.Date(2024, 3, 10, 1, 30, 0, 0, newYork), time.Date(2024, 3, 10, 3, 0, 0, 0, newYork)},
+		{"skipped time", "30 2 * * *", time.Date(2024, 3, 10, 0, 0, 0, 0, newYork), time.Date(2024, 3, 11, 2, 30, 0, 0, newYork)},
+		{"after the gap", "0 4 * * *", time.Date(2024, 3, 10, 1, 0, 0, 0, newYork), time.Date(2024, 3, 10, 4, 0, 0, 0, newYork)},
+		// Clocks fall back from 2:00 to 1:00 on 2024-11-03
+		{"after the repeated hour", "0 3 * * *", time.Date(2024, 11, 3, 0, 30, 0, 0, newYork), time.Date(2024, 11, 3, 3, 0, 0, 0, newYork)},
+		// Clocks jump from 0:00 to 1:00 on 2024-09-08, midnight is skipped
+		{"skipped midnight", "0 1 * * *", time.Date(2024, 9, 7, 12, 0, 0, 0, santiago), time.Date(2024, 9, 8, 1, 0, 0, 0, santiago)},
+		{"daily over skipped midnight", "0 0 * * *", time.Date(2024, 9, 7, 12, 0, 0, 0, santiago), time.Date(2024, 9, 9, 0, 0, 0, 0, santiago)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := Parse(tt.spec, tt.from.Location())
+			require.NoError(t, err)
+			assert.Equal(t, tt.want.UTC(), schedule.Next(tt.from).UTC())
+		})
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"* * * foo *",
+		"@every 5m",
+	} {
+		_, err := Parse(spec, nil)
+		assert.Error(t, err, spec)
+	}
+}
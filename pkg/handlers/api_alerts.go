@@ -4,13 +4,18 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"strconv"
 	"strings"
 	"sync/atomic"
 	"time"
 
 	operariusv1alpha1 "github.com/OpenFero/openfero/api/v1alpha1"
 	"github.com/OpenFero/openfero/pkg/alertstore"
+	"github.com/OpenFero/openfero/pkg/cloudevents"
+	"github.com/OpenFero/openfero/pkg/ingest"
 	"github.com/OpenFero/openfero/pkg/kubernetes"
 	log "github.com/OpenFero/openfero/pkg/logging"
 	"github.com/OpenFero/openfero/pkg/metadata"
@@ -32,7 +37,18 @@ type Server struct {
 	AlertStore       alertstore.Store
 	AuthConfig       AuthConfig
 	OperariusService *services.OperariusService // Service for Operarius CRDs
+	WebhookQueue     *ingest.Queue              // Processes webhook messages asynchronously if set
+	Adapters         ingest.Adapters            // Ingestion adapters served under /alerts/{adapter}
+	Events           *cloudevents.Emitter       // Receives lifecycle events if set
+	Lifecycles       *services.LifecycleTracker // Records the timing metrics of alert lifecycles if set
 	StartupComplete  atomic.Bool                // Set to true after informer caches are synced
+
+	EmptyWebhookResponse  bool                // Answer webhooks with an empty body unless response=json is requested
+	WebhookMaxBodyBytes   int64               // Maximum size of a webhook request body, unlimited if 0
+	WebhookMaxAlerts      int                 // Maximum number of alerts in a webhook message, unlimited if 0
+	StrictWebhookDecoding bool                // Reject Alertmanager webhooks with unknown fields or trailing data
+	WebhookRateLimiter    *RateLimiter        // Limits the webhook requests of each source if set
+	Deliveries            *ingest.DeliveryLog // Acknowledges re-delivered webhooks without processing them if set
 }
 
 // AlertsGetHandler handles GET requests to /alerts
@@ -46,141 +62,435 @@ func (s *Server) AlertsGetHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 // AlertsPostHandler handles POST requests to /alerts
+// @Summary Receive an Alertmanager webhook
+// @Description Matches the alerts against the Operarii and creates remediation Jobs. With a webhook queue the message
+// @Description is processed asynchronously and 202 is returned, unless wait=true is set. The body describes what was
+// @Description done for each alert; response=empty returns an empty body instead.
+// @Tags alerts
+// @Accept json
+// @Produce json
+// @Param message body models.HookMessage true "Alertmanager webhook message"
+// @Param wait query bool false "Process the message before responding, even if a webhook queue is configured"
+// @Param response query string false "Response body" Enums(json, empty)
+// @Param Idempotency-Key header string false "Identifies the delivery, retries with the same key are not processed again"
+// @Success 200 {object} WebhookResponse
+// @Success 202 {object} WebhookResponse
+// @Failure 400 {string} string "invalid request body or too many alerts"
+// @Failure 413 {string} string "request body too large"
+// @Failure 429 {string} string "webhook queue is full or rate limit exceeded"
+// @Router /alerts [post]
 func (s *Server) AlertsPostHandler(w http.ResponseWriter, r *http.Request) {
 	dec := json.NewDecoder(r.Body)
+	if s.StrictWebhookDecoding {
+		dec.DisallowUnknownFields()
+	}
 	defer func() {
 		if err := r.Body.Close(); err != nil {
 			log.Error("Failed to close request body", "error", err)
 		}
 	}()
 
+	responseMode, wait, ok := s.webhookParams(w, r)
+	if !ok {
+		return
+	}
+
 	message := models.HookMessage{}
-	if err := dec.Decode(&message); err != nil {
+	err := dec.Decode(&message)
+	if err == nil && s.StrictWebhookDecoding {
+		if _, trailing := dec.Token(); !errors.Is(trailing, io.EOF) {
+			err = errors.New("unexpected data after the message")
+		}
+	}
+	if err != nil {
 		log.Error("error decoding message: ", "error", err.Error())
-		http.Error(w, "invalid request body", http.StatusBadRequest)
+		rejectBody(w, err)
+		return
+	}
+
+	s.acceptHookMessage(w, r, message, responseMode, wait)
+}
+
+// webhookParams parses the query parameters of the webhook endpoints. It
+// answers the request with 400 and returns false if they are invalid.
+func (s *Server) webhookParams(w http.ResponseWriter, r *http.Request) (string, bool, bool) {
+	responseMode, ok := s.webhookResponseMode(r)
+	if !ok {
+		http.Error(w, "response must be json or empty", http.StatusBadRequest)
+		return "", false, false
+	}
+	wait := false
+	if value := r.URL.Query().Get("wait"); value != "" {
+		var err error
+		if wait, err = strconv.ParseBool(value); err != nil {
+			http.Error(w, "invalid wait parameter", http.StatusBadRequest)
+			return "", false, false
+		}
+	}
+	return responseMode, wait, true
+}
+
+// LimitBody caps the size of webhook request bodies. It wraps the
+// authentication, so signatures aren't verified over larger bodies either.
+func (s *Server) LimitBody(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.WebhookMaxBodyBytes > 0 {
+			r.Body = http.MaxBytesReader(w, r.Body, s.WebhookMaxBodyBytes)
+		}
+		next(w, r)
+	}
+}
+
+// rejectBody answers a webhook whose body could not be decoded with 413 if it
+// was too large, and 400 otherwise
+func rejectBody(w http.ResponseWriter, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		metadata.WebhookRejectedTotal.WithLabelValues("body_too_large").Inc()
+		http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
 		return
 	}
+	metadata.WebhookRejectedTotal.WithLabelValues("invalid_body").Inc()
+	http.Error(w, "invalid request body", http.StatusBadRequest)
+}
 
+// acceptHookMessage processes a decoded hook message, or queues it, and
+// writes the webhook response
+func (s *Server) acceptHookMessage(w http.ResponseWriter, r *http.Request, message models.HookMessage, responseMode string, wait bool) {
 	status := utils.SanitizeInput(message.Status)
 	alertcount := len(message.Alerts)
+	message.Credential = CredentialFromContext(r.Context())
+
+	if s.WebhookMaxAlerts > 0 && alertcount > s.WebhookMaxAlerts {
+		log.Warn("Rejecting webhook, too many alerts",
+			"alertCount", alertcount,
+			"maxAlerts", s.WebhookMaxAlerts,
+			"groupKey", message.GroupKey)
+		metadata.WebhookRejectedTotal.WithLabelValues("too_many_alerts").Inc()
+		http.Error(w, fmt.Sprintf("too many alerts, at most %d are accepted per message", s.WebhookMaxAlerts), http.StatusBadRequest)
+		return
+	}
 
 	// Use zap's fields for structured logging instead of string concatenation
 	log.Debug("Webhook received",
 		"status", status,
 		"alertCount", alertcount,
-		"groupKey", message.GroupKey)
+		"groupKey", message.GroupKey,
+		"credential", message.Credential)
 
 	if !services.CheckAlertStatus(status) {
 		log.Warn("Status of alert was neither firing nor resolved, stop creating a response job.")
+		writeWebhookResponse(w, responseMode, http.StatusOK, newWebhookResponse(message, webhookOutcome{
+			Action: WebhookActionSkipped,
+			Reason: "status is neither firing nor resolved",
+		}))
 		return
 	}
 
-	if s.OperariusService != nil {
-		s.handleOperariusBasedJobs(r.Context(), message)
-	} else {
+	if s.OperariusService == nil {
 		log.Error("OperariusService is not initialized")
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	// Acknowledge re-deliveries, e.g. retries after a timeout, without
+	// processing them again
+	key := idempotencyKey(r, &message)
+	if s.Deliveries != nil {
+		if deliveryID, ok := s.Deliveries.Claim(key); !ok {
+			log.Info("Acknowledging re-delivered webhook",
+				"groupKey", message.GroupKey,
+				"deliveryId", deliveryID)
+			metadata.WebhookDuplicatesTotal.Inc()
+			message.DeliveryID = deliveryID
+			writeWebhookResponse(w, responseMode, http.StatusOK, newWebhookResponse(message, webhookOutcome{
+				Action: WebhookActionDuplicate,
+				Reason: "delivery was already accepted",
+			}))
+			return
+		}
+	}
+
+	metadata.WebhookMessagesTotal.WithLabelValues(message.Credential).Inc()
+
+	if s.WebhookQueue == nil || wait {
+		outcome := s.handleOperariusBasedJobs(r.Context(), message)
+		if s.Deliveries != nil {
+			if outcome.Action == WebhookActionError {
+				// Let the sender's retry try again
+				s.Deliveries.Forget(key)
+			} else {
+				s.Deliveries.Accept(key, message.DeliveryID)
+			}
+		}
+		writeWebhookResponse(w, responseMode, http.StatusOK, newWebhookResponse(message, outcome))
+		return
 	}
+
+	// Hand the message to the worker pool, so slow Kubernetes API calls don't
+	// exceed Alertmanager's webhook timeout and cause duplicate deliveries
+	deliveryID, err := s.WebhookQueue.Enqueue(message)
+	if s.Deliveries != nil {
+		if err != nil {
+			s.Deliveries.Forget(key)
+		} else {
+			s.Deliveries.Accept(key, deliveryID)
+		}
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, ingest.ErrQueueFull):
+			log.Warn("Rejecting webhook, queue is full", "groupKey", message.GroupKey)
+			metadata.WebhookRejectedTotal.WithLabelValues("queue_full").Inc()
+			w.Header().Set("Retry-After", "5")
+			http.Error(w, err.Error(), http.StatusTooManyRequests)
+		case errors.Is(err, ingest.ErrQueueClosed):
+			metadata.WebhookRejectedTotal.WithLabelValues("shutting_down").Inc()
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		default:
+			log.Error("Failed to enqueue webhook", "error", err, "groupKey", message.GroupKey)
+			metadata.WebhookRejectedTotal.WithLabelValues("enqueue_error").Inc()
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	message.DeliveryID = deliveryID
+	writeWebhookResponse(w, responseMode, http.StatusAccepted, newWebhookResponse(message, webhookOutcome{
+		Action: WebhookActionQueued,
+	}))
 }
 
-// handleOperariusBasedJobs handles job creation using Operarius CRDs
-func (s *Server) handleOperariusBasedJobs(ctx context.Context, hookMessage models.HookMessage) {
+// idempotencyKey returns the key identifying a delivery. A key sent in the
+// Idempotency-Key header is scoped to the credential and also becomes the
+// delivery ID, so the Jobs of the delivery are named after it and replicas
+// that haven't heard of it yet can't create them a second time. It is hashed
+// to be a valid label value.
+func idempotencyKey(r *http.Request, message *models.HookMessage) string {
+	if header := r.Header.Get(ingest.IdempotencyKeyHeader); header != "" {
+		message.DeliveryID = utils.HashGroupKey(message.Credential + "\n" + header)
+	}
+	return ingest.IdempotencyKey(*message)
+}
+
+// ProcessHookMessage runs the Operarius handling for an accepted hook message.
+// It is the handler of the webhook queue and returns an error if the Job
+// could not be created, so the message is kept for a replay.
+func (s *Server) ProcessHookMessage(ctx context.Context, hookMessage models.HookMessage) error {
+	outcome := s.handleOperariusBasedJobs(ctx, hookMessage)
+	log.Debug("Processed queued webhook",
+		"deliveryId", hookMessage.DeliveryID,
+		"action", outcome.Action,
+		"operarius", outcome.Operarius,
+		"jobName", outcome.JobName)
+	if outcome.Action == WebhookActionError {
+		return errors.New(outcome.Reason)
+	}
+	return nil
+}
+
+// SubmitHookMessage hands a hook message from an internal source, like the
+// Kubernetes event watcher, to the webhook queue, or processes it right away
+// if there is no queue
+func (s *Server) SubmitHookMessage(ctx context.Context, hookMessage models.HookMessage) {
+	if s.WebhookQueue == nil {
+		// Failures are logged while processing, there is nothing to retry
+		_ = s.ProcessHookMessage(ctx, hookMessage)
+		return
+	}
+	if _, err := s.WebhookQueue.Enqueue(hookMessage); err != nil {
+		log.Warn("Dropping hook message", "groupKey", hookMessage.GroupKey, "receiver", hookMessage.Receiver, "error", err)
+	}
+}
+
+// handleOperariusBasedJobs handles job creation using Operarius CRDs and
+// returns what was done
+func (s *Server) handleOperariusBasedJobs(ctx context.Context, hookMessage models.HookMessage) webhookOutcome {
 	log.Debug("Processing webhook with Operarius CRDs",
 		"status", hookMessage.Status,
 		"groupKey", hookMessage.GroupKey,
 		"alertCount", len(hookMessage.Alerts))
+	if hookMessage.TruncatedAlerts > 0 {
+		log.Warn("Alertmanager truncated the webhook message, some alerts are not stored",
+			"groupKey", hookMessage.GroupKey,
+			"truncatedAlerts", hookMessage.TruncatedAlerts)
+	}
+
+	s.Events.Emit(cloudevents.TypeAlertReceived, hookMessage.GroupKey, hookMessage)
 
 	var jobInfo *alertstore.JobInfo
+	var outcome webhookOutcome
 
 	operarii, err := s.OperariusService.GetOperariiForNamespace(ctx, "")
 	if err != nil {
 		log.Error("Failed to get Operarii", "error", err)
 		// Continue to store alert even if we can't get Operarii
+		outcome = webhookOutcome{Action: WebhookActionError, Reason: "failed to get Operarii: " + err.Error()}
 	} else {
 		// Find matching Operarius
 		operarius, err := s.OperariusService.FindMatchingOperarius(hookMessage, operarii)
 		if err != nil {
 			log.Info("No matching Operarius found - alert will be stored without remediation", "error", err)
+			outcome = webhookOutcome{Action: WebhookActionSkipped, Reason: err.Error()}
 		} else {
 			log.Info("Found matching Operarius",
 				"operarius", operarius.Name,
 				"namespace", operarius.Namespace,
 				"priority", operarius.Spec.Priority)
-
-			// Check deduplication
-			shouldCreate, err := s.OperariusService.CheckDeduplication(ctx, operarius, hookMessage)
-			if err != nil {
-				log.Error("Failed to check deduplication", "error", err)
-			} else if !shouldCreate {
-				log.Info("Skipping job creation due to deduplication",
-					"operarius", operarius.Name,
-					"groupKey", hookMessage.GroupKey)
-
-				jobInfo = s.buildDedupSkippedJobInfo(ctx, operarius)
-			} else {
-				// Create the job
-				job, err := s.OperariusService.CreateJobFromOperarius(ctx, operarius, hookMessage)
-				if err != nil {
-					if errors.Is(err, services.ErrJobDeduplicated) {
-						// A concurrent request won the race for this deduplication
-						// window; treat it the same as the advisory check above.
-						log.Info("Job creation deduplicated at creation time (concurrent request)",
-							"operarius", operarius.Name,
-							"groupKey", hookMessage.GroupKey)
-						jobInfo = s.buildDedupSkippedJobInfo(ctx, operarius)
-					} else {
-						log.Error("Failed to create job from Operarius",
-							"error", err,
-							"operarius", operarius.Name)
-						metadata.JobsFailedTotal.Inc()
-					}
-				} else {
-
-					log.Info("Successfully created remediation job",
-						"jobName", job.Name,
-						"operarius", operarius.Name,
-						"namespace", job.Namespace,
-						"groupKey", hookMessage.GroupKey)
-
-					// Update Operarius status with execution info
-					if err := s.OperariusService.UpdateOperariusStatus(ctx, operarius, job.Name); err != nil {
-						log.Warn("Failed to update Operarius status",
-							"error", err,
-							"operarius", operarius.Name)
-						// Don't return - job was created successfully, status update is best-effort
-					}
-
-					var lastExecutionTime *time.Time
-					if operarius.Status.LastExecutionTime != nil {
-						t := operarius.Status.LastExecutionTime.Time
-						lastExecutionTime = &t
-					}
-
-					jobInfo = &alertstore.JobInfo{
-						JobName:             job.Name,
-						Namespace:           job.Namespace,
-						OperariusName:       operarius.Name, // Operarius name for tracking
-						Image:               getFirstContainerImage(job),
-						ExecutionCount:      operarius.Status.ExecutionCount,
-						LastExecutionTime:   lastExecutionTime,
-						LastExecutedJobName: operarius.Status.LastExecutedJobName,
-						LastExecutionStatus: operarius.Status.LastExecutionStatus,
-					}
-				}
-			}
+			outcome, jobInfo = s.runOperarius(ctx, operarius, hookMessage, services.JobOptions{})
 		}
 	}
 
+	// A delivery processed before stored its alerts already
+	if outcome.Action == WebhookActionDuplicate {
+		return outcome
+	}
+
 	// Store alert in alert store for tracking and broadcast to SSE clients
 	for _, alert := range hookMessage.Alerts {
+		// A resolved alert can be part of a firing group, store its own status
+		status := alert.StatusOr(hookMessage.Status)
+		alert.Credential = hookMessage.Credential
 		if jobInfo != nil {
 			// Use the service function which handles both storage and SSE broadcast
-			services.SaveAlertWithJobInfo(s.AlertStore, alert, hookMessage.Status, jobInfo)
+			services.SaveAlertWithJobInfo(s.AlertStore, alert, status, jobInfo)
 		} else {
 			// Save without job info
-			services.SaveAlert(s.AlertStore, alert, hookMessage.Status)
+			services.SaveAlert(s.AlertStore, alert, status)
+		}
+		s.Lifecycles.AlertSaved(alert, status, jobInfo)
+	}
+
+	s.emitOutcome(hookMessage, outcome)
+	return outcome
+}
+
+// runOperarius creates the Job of an Operarius for a hook message, honouring
+// its deduplication settings, and updates the Operarius status
+func (s *Server) runOperarius(ctx context.Context, operarius *operariusv1alpha1.Operarius, hookMessage models.HookMessage, opts services.JobOptions) (webhookOutcome, *alertstore.JobInfo) {
+	outcome := webhookOutcome{Operarius: operarius.Name, Namespace: operarius.Namespace}
+
+	// Check deduplication
+	shouldCreate, err := s.OperariusService.CheckDeduplication(ctx, operarius, hookMessage)
+	if err != nil {
+		log.Error("Failed to check deduplication", "error", err)
+		outcome.Action = WebhookActionError
+		outcome.Reason = "failed to check deduplication: " + err.Error()
+		return outcome, nil
+	}
+	if !shouldCreate {
+		if handled, ok := s.deliveryHandled(ctx, operarius, hookMessage); ok {
+			return handled, nil
+		}
+		log.Info("Skipping job creation due to deduplication",
+			"operarius", operarius.Name,
+			"groupKey", hookMessage.GroupKey)
+
+		jobInfo := s.buildDedupSkippedJobInfo(ctx, operarius)
+		outcome.Action = WebhookActionDeduplicated
+		outcome.JobName = jobInfo.LastExecutedJobName
+		outcome.Reason = "a job for this alert group was created within the deduplication TTL"
+		return outcome, jobInfo
+	}
+
+	// Create the job
+	job, err := s.OperariusService.CreateJobFromOperariusWithOptions(ctx, operarius, hookMessage, opts)
+	if err != nil {
+		if errors.Is(err, services.ErrJobDeduplicated) {
+			if handled, ok := s.deliveryHandled(ctx, operarius, hookMessage); ok {
+				return handled, nil
+			}
+			// A concurrent request won the race for this deduplication
+			// window; treat it the same as the advisory check above.
+			log.Info("Job creation deduplicated at creation time (concurrent request)",
+				"operarius", operarius.Name,
+				"groupKey", hookMessage.GroupKey)
+			jobInfo := s.buildDedupSkippedJobInfo(ctx, operarius)
+			outcome.Action = WebhookActionDeduplicated
+			outcome.JobName = jobInfo.LastExecutedJobName
+			outcome.Reason = err.Error()
+			return outcome, jobInfo
 		}
+		log.Error("Failed to create job from Operarius",
+			"error", err,
+			"operarius", operarius.Name)
+		metadata.JobsFailedTotal.Inc()
+		outcome.Action = WebhookActionError
+		outcome.Reason = err.Error()
+		return outcome, nil
+	}
+
+	log.Info("Successfully created remediation job",
+		"jobName", job.Name,
+		"operarius", operarius.Name,
+		"namespace", job.Namespace,
+		"groupKey", hookMessage.GroupKey)
+	s.Lifecycles.JobCreated(job.Namespace, job.Name)
+
+	// Update Operarius status with execution info
+	if err := s.OperariusService.UpdateOperariusStatus(ctx, operarius, job.Name); err != nil {
+		log.Warn("Failed to update Operarius status",
+			"error", err,
+			"operarius", operarius.Name)
+		// Don't return - job was created successfully, status update is best-effort
+	}
+
+	outcome.Action = WebhookActionCreated
+	outcome.JobName = job.Name
+	return outcome, newJobInfo(job, operarius)
+}
+
+// deliveryHandled reports whether the Operarius already created a Job for the
+// delivery of a hook message, e.g. before a restart replayed it from the
+// queue. Its alerts were stored and the Operarius status was updated then.
+func (s *Server) deliveryHandled(ctx context.Context, operarius *operariusv1alpha1.Operarius, hookMessage models.HookMessage) (webhookOutcome, bool) {
+	if hookMessage.DeliveryID == "" {
+		return webhookOutcome{}, false
+	}
+	job, err := s.OperariusService.DeliveryJob(ctx, operarius, hookMessage.DeliveryID)
+	if err != nil {
+		log.Warn("Failed to look up the job of the delivery",
+			"error", err,
+			"operarius", operarius.Name,
+			"deliveryId", hookMessage.DeliveryID)
+		return webhookOutcome{}, false
+	}
+	if job == nil {
+		return webhookOutcome{}, false
+	}
+
+	log.Info("Delivery was already processed",
+		"operarius", operarius.Name,
+		"deliveryId", hookMessage.DeliveryID,
+		"jobName", job.Name)
+	return webhookOutcome{
+		Operarius: operarius.Name,
+		Namespace: operarius.Namespace,
+		Action:    WebhookActionDuplicate,
+		JobName:   job.Name,
+		Reason:    "the job for this delivery was already created",
+	}, true
+}
+
+// emitOutcome emits the lifecycle event for the outcome of a hook message
+func (s *Server) emitOutcome(hookMessage models.HookMessage, outcome webhookOutcome) {
+	var eventType string
+	switch outcome.Action {
+	case WebhookActionCreated:
+		eventType = cloudevents.TypeJobCreated
+	case WebhookActionDeduplicated:
+		eventType = cloudevents.TypeJobDeduplicated
+	default:
+		return
 	}
+	s.Events.Emit(eventType, outcome.Namespace+"/"+outcome.JobName, cloudevents.JobData{
+		Operarius:  outcome.Operarius,
+		Namespace:  outcome.Namespace,
+		JobName:    outcome.JobName,
+		GroupKey:   hookMessage.GroupKey,
+		DeliveryID: hookMessage.DeliveryID,
+		Reason:     outcome.Reason,
+	})
 }
 
 // buildDedupSkippedJobInfo marks the Operarius' dedup status and returns the
@@ -210,6 +520,26 @@ func (s *Server) buildDedupSkippedJobInfo(ctx context.Context, operarius *operar
 	}
 }
 
+// newJobInfo returns the JobInfo stored with the alerts that triggered a newly created job
+func newJobInfo(job *batchv1.Job, operarius *operariusv1alpha1.Operarius) *alertstore.JobInfo {
+	var lastExecutionTime *time.Time
+	if operarius.Status.LastExecutionTime != nil {
+		t := operarius.Status.LastExecutionTime.Time
+		lastExecutionTime = &t
+	}
+
+	return &alertstore.JobInfo{
+		JobName:             job.Name,
+		Namespace:           job.Namespace,
+		OperariusName:       operarius.Name, // Operarius name for tracking
+		Image:               getFirstContainerImage(job),
+		ExecutionCount:      operarius.Status.ExecutionCount,
+		LastExecutionTime:   lastExecutionTime,
+		LastExecutedJobName: operarius.Status.LastExecutedJobName,
+		LastExecutionStatus: operarius.Status.LastExecutionStatus,
+	}
+}
+
 // getFirstContainerImage extracts the image from the first container in the job
 func getFirstContainerImage(job *batchv1.Job) string {
 	if len(job.Spec.Template.Spec.Containers) > 0 {
@@ -218,18 +548,68 @@ func getFirstContainerImage(job *batchv1.Job) string {
 	return "unknown"
 }
 
-// AlertStoreGetHandler handles GET requests to /alertStore
+// NextCursorHeader carries the cursor of the next page of alerts
+const NextCursorHeader = "X-Next-Cursor"
+
+// Page sizes of GET /api/alerts
+const (
+	defaultAlertsPageSize = 100
+	maxAlertsPageSize     = 1000
+)
+
+// AlertStoreGetHandler handles GET requests to /api/alerts
+// @Summary Query the alert history
+// @Description Returns stored alerts matching all given filters, newest first unless order=asc. If more alerts match
+// @Description than the limit, the X-Next-Cursor header holds a cursor returning the next page.
+// @Tags alerts
+// @Produce json
+// @Produce application/x-ndjson
+// @Param q query string false "Free text search in labels, annotations, status and Job"
+// @Param match[] query []string false "Label matchers like alertname=\"Disk\",severity=~\"warning|critical\"" collectionFormat(multi)
+// @Param status query string false "Status the alert was received with" Enums(firing, resolved)
+// @Param operarius query string false "Name of the Operarius that handled the alert"
+// @Param job query string false "Outcome of the Job" Enums(none, skipped, pending, running, successful, failed, cancelled)
+// @Param from query string false "Only alerts received at or after this RFC 3339 time"
+// @Param to query string false "Only alerts received before this RFC 3339 time"
+// @Param order query string false "Sort order by time received" Enums(desc, asc)
+// @Param limit query int false "Maximum number of alerts, 100 by default and at most 1000; all alerts with format=ndjson"
+// @Param cursor query string false "Cursor of the next page from the X-Next-Cursor header of the previous page"
+// @Param format query string false "Response format" Enums(json, ndjson)
+// @Success 200 {array} alertstore.AlertEntry
+// @Failure 400 {string} string "invalid query parameter or cursor"
+// @Router /api/alerts [get]
 func (s *Server) AlertStoreGetHandler(w http.ResponseWriter, r *http.Request) {
-	// Get search query parameter
-	query := r.URL.Query().Get("q")
-	limit := 100 // Default limit
+	// NDJSON exports the full history as stored, e.g. for offline backtesting
+	ndjson := r.URL.Query().Get("format") == "ndjson"
 
-	alerts, err := s.AlertStore.GetAlerts(query, limit)
+	query, err := alertQueryFromRequest(r, ndjson)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := s.AlertStore.QueryAlerts(query)
+	if errors.Is(err, alertstore.ErrInvalidCursor) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	if err != nil {
 		log.Error("Error retrieving alerts", "error", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	alerts := result.Entries
+	if result.NextCursor != "" {
+		w.Header().Set(NextCursorHeader, result.NextCursor)
+	}
+
+	if ndjson {
+		w.Header().Set(ContentTypeHeader, "application/x-ndjson")
+		if err := alertstore.WriteNDJSON(w, alerts); err != nil {
+			log.Error("Error encoding alerts", "error", err)
+		}
+		return
+	}
 
 	// Enrich alerts with live job status
 	ctx := r.Context()
@@ -242,6 +622,10 @@ func (s *Server) AlertStoreGetHandler(w http.ResponseWriter, r *http.Request) {
 
 			job, jobErr := s.KubeClient.Clientset.BatchV1().Jobs(alerts[i].JobInfo.Namespace).Get(ctx, alerts[i].JobInfo.JobName, metav1.GetOptions{})
 			if jobErr == nil {
+				// Work on a copy, the store may share the JobInfo with other readers
+				jobInfo := *alerts[i].JobInfo
+				alerts[i].JobInfo = &jobInfo
+
 				// Determine status
 				status := "Pending"
 				if job.Status.Succeeded > 0 {
@@ -268,3 +652,48 @@ func (s *Server) AlertStoreGetHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "", http.StatusInternalServerError)
 	}
 }
+
+// alertQueryFromRequest builds a store query from the parameters of GET /api/alerts
+func alertQueryFromRequest(r *http.Request, ndjson bool) (alertstore.Query, error) {
+	params := r.URL.Query()
+	query := alertstore.Query{
+		Text:       params.Get("q"),
+		Status:     params.Get("status"),
+		Operarius:  params.Get("operarius"),
+		JobOutcome: params.Get("job"),
+		Order:      alertstore.SortOrder(params.Get("order")),
+		Cursor:     params.Get("cursor"),
+		Limit:      defaultAlertsPageSize,
+	}
+	if ndjson {
+		query.Limit = 0
+	}
+
+	for _, input := range append(params["match[]"], params["match"]...) {
+		matchers, err := alertstore.ParseMatchers(input)
+		if err != nil {
+			return alertstore.Query{}, err
+		}
+		query.Matchers = append(query.Matchers, matchers...)
+	}
+
+	for name, t := range map[string]*time.Time{"from": &query.From, "to": &query.To} {
+		if value := params.Get(name); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return alertstore.Query{}, fmt.Errorf("invalid %s, expected an RFC 3339 time: %w", name, err)
+			}
+			*t = parsed
+		}
+	}
+
+	if value := params.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 0 || (!ndjson && (limit == 0 || limit > maxAlertsPageSize)) {
+			return alertstore.Query{}, fmt.Errorf("invalid limit %q, expected 1 to %d", value, maxAlertsPageSize)
+		}
+		query.Limit = limit
+	}
+
+	return query, query.Validate()
+}
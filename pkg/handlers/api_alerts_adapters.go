@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	log "github.com/OpenFero/openfero/pkg/logging"
+	"github.com/OpenFero/openfero/pkg/metadata"
+)
+
+// AlertsAdapterPostHandler handles POST requests to /alerts/{adapter}
+// @Summary Receive a webhook of another alert source
+// @Description The payload is converted by the named ingestion adapter, e.g. grafana or generic, and then handled like
+// @Description an Alertmanager webhook.
+// @Tags alerts
+// @Accept json
+// @Produce json
+// @Param adapter path string true "Ingestion adapter" Enums(grafana, generic)
+// @Param wait query bool false "Process the message before responding, even if a webhook queue is configured"
+// @Param response query string false "Response body" Enums(json, empty)
+// @Success 200 {object} WebhookResponse
+// @Success 202 {object} WebhookResponse
+// @Failure 400 {string} string "invalid request body or too many alerts"
+// @Failure 404 {string} string "unknown adapter"
+// @Failure 413 {string} string "request body too large"
+// @Failure 429 {string} string "webhook queue is full or rate limit exceeded"
+// @Router /alerts/{adapter} [post]
+func (s *Server) AlertsAdapterPostHandler(w http.ResponseWriter, r *http.Request) {
+	defer func() {
+		if err := r.Body.Close(); err != nil {
+			log.Error("Failed to close request body", "error", err)
+		}
+	}()
+
+	name := r.PathValue("adapter")
+	adapter, ok := s.Adapters.Get(name)
+	if !ok {
+		http.Error(w, "unknown adapter", http.StatusNotFound)
+		return
+	}
+
+	responseMode, wait, ok := s.webhookParams(w, r)
+	if !ok {
+		return
+	}
+
+	message, err := adapter.Decode(r)
+	if err != nil {
+		log.Warn("Failed to decode webhook", "adapter", name, "error", err)
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			rejectBody(w, err)
+			return
+		}
+		metadata.WebhookRejectedTotal.WithLabelValues("invalid_body").Inc()
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.acceptHookMessage(w, r, message, responseMode, wait)
+}
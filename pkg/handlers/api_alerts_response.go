@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	log "github.com/OpenFero/openfero/pkg/logging"
+	"github.com/OpenFero/openfero/pkg/models"
+)
+
+// WebhookAction is what OpenFero did for an alert of a webhook message
+type WebhookAction string
+
+const (
+	// WebhookActionCreated means a remediation Job was created
+	WebhookActionCreated WebhookAction = "created"
+	// WebhookActionDeduplicated means a Job for the alert group already exists
+	WebhookActionDeduplicated WebhookAction = "deduplicated"
+	// WebhookActionSkipped means no Job was needed, e.g. no Operarius matched
+	WebhookActionSkipped WebhookAction = "skipped"
+	// WebhookActionError means creating the Job failed
+	WebhookActionError WebhookAction = "error"
+	// WebhookActionQueued means the message was accepted for asynchronous processing
+	WebhookActionQueued WebhookAction = "queued"
+	// WebhookActionDuplicate means the delivery was accepted before and is not processed again
+	WebhookActionDuplicate WebhookAction = "duplicate"
+)
+
+// Webhook response modes, selected with the response query parameter
+const (
+	webhookResponseJSON  = "json"
+	webhookResponseEmpty = "empty"
+)
+
+// WebhookAlertResult describes how a single alert was handled
+type WebhookAlertResult struct {
+	Alertname string            `json:"alertname"`
+	Labels    map[string]string `json:"labels"`
+	// Operarius is the matched Operarius, if any
+	Operarius string        `json:"operarius,omitempty"`
+	Namespace string        `json:"namespace,omitempty"`
+	Action    WebhookAction `json:"action"`
+	JobName   string        `json:"jobName,omitempty"`
+	Reason    string        `json:"reason,omitempty"`
+}
+
+// WebhookResponse is the response body of POST /alerts
+type WebhookResponse struct {
+	Status   string `json:"status"`
+	GroupKey string `json:"groupKey"`
+	// DeliveryID identifies a queued message, Jobs created for it carry it as label
+	DeliveryID string               `json:"deliveryId,omitempty"`
+	Alerts     []WebhookAlertResult `json:"alerts"`
+}
+
+// webhookOutcome is the outcome of processing a hook message, it applies to
+// all of its alerts
+type webhookOutcome struct {
+	Operarius string
+	Namespace string
+	Action    WebhookAction
+	JobName   string
+	Reason    string
+}
+
+// newWebhookResponse applies an outcome to every alert of a hook message
+func newWebhookResponse(hookMessage models.HookMessage, outcome webhookOutcome) WebhookResponse {
+	response := WebhookResponse{
+		Status:     hookMessage.Status,
+		GroupKey:   hookMessage.GroupKey,
+		DeliveryID: hookMessage.DeliveryID,
+		Alerts:     make([]WebhookAlertResult, 0, len(hookMessage.Alerts)),
+	}
+	for _, alert := range hookMessage.Alerts {
+		response.Alerts = append(response.Alerts, WebhookAlertResult{
+			Alertname: alert.Labels["alertname"],
+			Labels:    alert.Labels,
+			Operarius: outcome.Operarius,
+			Namespace: outcome.Namespace,
+			Action:    outcome.Action,
+			JobName:   outcome.JobName,
+			Reason:    outcome.Reason,
+		})
+	}
+	return response
+}
+
+// webhookResponseMode returns the response mode requested by the caller,
+// falling back to the server default
+func (s *Server) webhookResponseMode(r *http.Request) (string, bool) {
+	switch mode := r.URL.Query().Get("response"); mode {
+	case "":
+		if s.EmptyWebhookResponse {
+			return webhookResponseEmpty, true
+		}
+		return webhookResponseJSON, true
+	case webhookResponseJSON, webhookResponseEmpty:
+		return mode, true
+	default:
+		return "", false
+	}
+}
+
+// writeWebhookResponse writes the response of POST /alerts in the requested mode
+func writeWebhookResponse(w http.ResponseWriter, mode string, statusCode int, response WebhookResponse) {
+	if mode == webhookResponseEmpty {
+		w.WriteHeader(statusCode)
+		return
+	}
+
+	w.Header().Set(ContentTypeHeader, ApplicationJSONVal)
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Error("Error encoding webhook response", "error", err)
+	}
+}
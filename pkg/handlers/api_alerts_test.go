@@ -2,8 +2,14 @@ package handlers
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
@@ -15,7 +21,10 @@ import (
 	corev1 "k8s.io/api/core/v1"
 
 	operariusv1alpha1 "github.com/OpenFero/openfero/api/v1alpha1"
+	"github.com/OpenFero/openfero/pkg/alertstore"
 	"github.com/OpenFero/openfero/pkg/alertstore/memory"
+	"github.com/OpenFero/openfero/pkg/cloudevents"
+	"github.com/OpenFero/openfero/pkg/ingest"
 	"github.com/OpenFero/openfero/pkg/metadata"
 	"github.com/OpenFero/openfero/pkg/models"
 	"github.com/OpenFero/openfero/pkg/services"
@@ -169,3 +178,475 @@ func TestHandleOperariusBasedJobs_ConcurrentDeduplication(t *testing.T) {
 	assert.Equal(t, 1, successCount, "exactly one request should have created the real job")
 	assert.Equal(t, concurrency-1, skippedCount, "all other requests should be marked as deduplicated")
 }
+
+// TestHandleOperariusBasedJobs_ReplayedDelivery ensures that a delivery
+// processed again, e.g. after a restart replayed it from the queue, neither
+// stores its alerts again nor marks the Operarius as deduplicated.
+func TestHandleOperariusBasedJobs_ReplayedDelivery(t *testing.T) {
+	tests := []struct {
+		name  string
+		dedup bool
+	}{
+		{name: "delivery job name", dedup: false},
+		{name: "deduplication window", dedup: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, kubeClient, operariusClient := newJobActionsTestServer(t)
+			if !tt.dedup {
+				operariusClient.operarii[0].Spec.Deduplication = nil
+			}
+
+			hookMessage := models.HookMessage{
+				Status:     "firing",
+				GroupKey:   "replayed-group",
+				DeliveryID: "0123456789abcdef",
+				Alerts: []models.Alert{
+					{Labels: map[string]string{"alertname": "TestAlert"}},
+				},
+			}
+
+			first := server.handleOperariusBasedJobs(context.Background(), hookMessage)
+			require.Equal(t, WebhookActionCreated, first.Action)
+			operarii, _ := operariusClient.List()
+			status := operarii[0].Status
+
+			replayed := server.handleOperariusBasedJobs(context.Background(), hookMessage)
+			assert.Equal(t, WebhookActionDuplicate, replayed.Action)
+			assert.Equal(t, first.JobName, replayed.JobName)
+
+			jobs, err := kubeClient.BatchV1().Jobs("openfero").List(context.Background(), metav1.ListOptions{})
+			require.NoError(t, err)
+			assert.Len(t, jobs.Items, 1)
+
+			entries, err := server.AlertStore.GetAlerts("", 0)
+			require.NoError(t, err)
+			require.Len(t, entries, 1, "a replayed delivery must not store its alerts again")
+			assert.Equal(t, first.JobName, entries[0].JobInfo.JobName)
+
+			operarii, _ = operariusClient.List()
+			assert.Equal(t, status, operarii[0].Status, "a replayed delivery must not touch the Operarius status")
+		})
+	}
+}
+
+func TestAlertsPostHandler_Queued(t *testing.T) {
+	server, kubeClient, _ := newJobActionsTestServer(t)
+
+	release := make(chan struct{})
+	processed := make(chan models.HookMessage, 2)
+	server.WebhookQueue = ingest.NewQueue(1, 1, func(ctx context.Context, hookMessage models.HookMessage) error {
+		<-release
+		err := server.ProcessHookMessage(ctx, hookMessage)
+		processed <- hookMessage
+		return err
+	})
+	server.WebhookQueue.Start(context.Background())
+
+	post := func(groupKey string) *httptest.ResponseRecorder {
+		body := `{"status":"firing","groupKey":"` + groupKey + `","alerts":[{"labels":{"alertname":"TestAlert"}}]}`
+		rec := httptest.NewRecorder()
+		server.AlertsPostHandler(rec, httptest.NewRequest(http.MethodPost, "/alerts", strings.NewReader(body)))
+		return rec
+	}
+
+	// The first message occupies the worker, the second waits in the queue
+	rec := post("group-1")
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	var accepted WebhookResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&accepted))
+	assert.NotEmpty(t, accepted.DeliveryID)
+	require.Len(t, accepted.Alerts, 1)
+	assert.Equal(t, WebhookActionQueued, accepted.Alerts[0].Action)
+	require.Eventually(t, func() bool { return server.WebhookQueue.Len() == 0 }, time.Second, time.Millisecond)
+	assert.Equal(t, http.StatusAccepted, post("group-2").Code)
+
+	rejectedBefore := testutil.ToFloat64(metadata.WebhookRejectedTotal.WithLabelValues("queue_full"))
+	rec = post("group-3")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.NotEmpty(t, rec.Header().Get("Retry-After"))
+	assert.Equal(t, rejectedBefore+1, testutil.ToFloat64(metadata.WebhookRejectedTotal.WithLabelValues("queue_full")))
+
+	// Nothing is processed on the request goroutine
+	jobs, err := kubeClient.BatchV1().Jobs("openfero").List(context.Background(), metav1.ListOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, jobs.Items)
+
+	close(release)
+	require.NoError(t, server.WebhookQueue.Shutdown(context.Background()))
+	assert.Len(t, processed, 2)
+
+	alerts, err := server.AlertStore.GetAlerts("", 0)
+	require.NoError(t, err)
+	assert.Len(t, alerts, 2)
+
+	assert.Equal(t, http.StatusServiceUnavailable, post("group-4").Code)
+}
+
+func TestAlertsPostHandler_Response(t *testing.T) {
+	post := func(server *Server, target, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		server.AlertsPostHandler(rec, httptest.NewRequest(http.MethodPost, target, strings.NewReader(body)))
+		return rec
+	}
+	decode := func(t *testing.T, rec *httptest.ResponseRecorder) WebhookResponse {
+		t.Helper()
+		var response WebhookResponse
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&response), rec.Body.String())
+		return response
+	}
+	const matching = `{"status":"firing","groupKey":"group-1","alerts":[{"labels":{"alertname":"TestAlert","pod":"web-0"}},{"labels":{"alertname":"TestAlert","pod":"web-1"}}]}`
+
+	t.Run("created then deduplicated", func(t *testing.T) {
+		server, _, _ := newJobActionsTestServer(t)
+
+		rec := post(server, "/alerts", matching)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, ApplicationJSONVal, rec.Header().Get(ContentTypeHeader))
+		response := decode(t, rec)
+		assert.Equal(t, "group-1", response.GroupKey)
+		require.Len(t, response.Alerts, 2)
+		created := response.Alerts[0]
+		assert.Equal(t, "TestAlert", created.Alertname)
+		assert.Equal(t, "web-0", created.Labels["pod"])
+		assert.Equal(t, "dedup-operarius", created.Operarius)
+		assert.Equal(t, WebhookActionCreated, created.Action)
+		assert.NotEmpty(t, created.JobName)
+		assert.Equal(t, WebhookActionCreated, response.Alerts[1].Action)
+
+		response = decode(t, post(server, "/alerts", matching))
+		require.Len(t, response.Alerts, 2)
+		assert.Equal(t, WebhookActionDeduplicated, response.Alerts[0].Action)
+		assert.Equal(t, created.JobName, response.Alerts[0].JobName)
+		assert.NotEmpty(t, response.Alerts[0].Reason)
+	})
+
+	t.Run("no matching Operarius", func(t *testing.T) {
+		server, _, _ := newJobActionsTestServer(t)
+		response := decode(t, post(server, "/alerts", `{"status":"firing","alerts":[{"labels":{"alertname":"Other"}}]}`))
+		require.Len(t, response.Alerts, 1)
+		assert.Equal(t, WebhookActionSkipped, response.Alerts[0].Action)
+		assert.Empty(t, response.Alerts[0].Operarius)
+		assert.NotEmpty(t, response.Alerts[0].Reason)
+	})
+
+	t.Run("invalid status", func(t *testing.T) {
+		server, _, _ := newJobActionsTestServer(t)
+		rec := post(server, "/alerts", `{"status":"unknown","alerts":[{"labels":{"alertname":"TestAlert"}}]}`)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, WebhookActionSkipped, decode(t, rec).Alerts[0].Action)
+	})
+
+	t.Run("empty body", func(t *testing.T) {
+		server, _, _ := newJobActionsTestServer(t)
+		rec := post(server, "/alerts?response=empty", matching)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, rec.Body.String())
+
+		server.EmptyWebhookResponse = true
+		rec = post(server, "/alerts", matching)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, rec.Body.String())
+
+		rec = post(server, "/alerts?response=json", matching)
+		assert.Equal(t, WebhookActionDeduplicated, decode(t, rec).Alerts[0].Action)
+	})
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		server, _, _ := newJobActionsTestServer(t)
+		assert.Equal(t, http.StatusBadRequest, post(server, "/alerts?response=xml", matching).Code)
+		assert.Equal(t, http.StatusBadRequest, post(server, "/alerts?wait=maybe", matching).Code)
+	})
+
+	t.Run("wait bypasses the queue", func(t *testing.T) {
+		server, _, _ := newJobActionsTestServer(t)
+		server.WebhookQueue = ingest.NewQueue(1, 1, server.ProcessHookMessage)
+
+		rec := post(server, "/alerts?wait=true", matching)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, WebhookActionCreated, decode(t, rec).Alerts[0].Action)
+		assert.Zero(t, server.WebhookQueue.Len())
+	})
+}
+
+// TestAlertsPostHandler_V4Payload verifies the per-alert fields of the
+// Alertmanager webhook payload version 4 are stored with the alerts.
+func TestAlertsPostHandler_V4Payload(t *testing.T) {
+	server, _, _ := newJobActionsTestServer(t)
+	body := `{
+		"version": "4",
+		"status": "firing",
+		"groupKey": "group-1",
+		"truncatedAlerts": 3,
+		"alerts": [
+			{"status": "firing", "labels": {"alertname": "TestAlert", "pod": "web-0"}, "generatorURL": "http://prometheus/graph?g0.expr=up", "fingerprint": "1a2b3c4d5e6f7a8b"},
+			{"status": "resolved", "labels": {"alertname": "TestAlert", "pod": "web-1"}, "fingerprint": "8b7a6f5e4d3c2b1a"}
+		]
+	}`
+
+	rec := httptest.NewRecorder()
+	server.AlertsPostHandler(rec, httptest.NewRequest(http.MethodPost, "/alerts?wait=true", strings.NewReader(body)))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	entries, err := server.AlertStore.GetAlerts("", 0)
+	require.NoError(t, err)
+	byFingerprint := make(map[string]string)
+	for _, entry := range entries {
+		byFingerprint[entry.Alert.Fingerprint] = entry.Status
+		if entry.Alert.Fingerprint == "1a2b3c4d5e6f7a8b" {
+			assert.Equal(t, "http://prometheus/graph?g0.expr=up", entry.Alert.GeneratorURL)
+		}
+	}
+	assert.Equal(t, map[string]string{"1a2b3c4d5e6f7a8b": "firing", "8b7a6f5e4d3c2b1a": "resolved"}, byFingerprint)
+
+	found, err := server.AlertStore.GetAlerts("8b7a6f", 0)
+	require.NoError(t, err)
+	assert.Len(t, found, 1, "alerts can be searched by fingerprint")
+}
+
+// TestAlertsPostHandler_RecordsCredential verifies the label of the
+// credential a webhook was sent with ends up on the stored alerts, also when
+// the message goes through the queue
+func TestAlertsPostHandler_RecordsCredential(t *testing.T) {
+	server, _, _ := newJobActionsTestServer(t)
+	server.WebhookQueue = ingest.NewQueue(10, 1, server.ProcessHookMessage)
+	server.WebhookQueue.Start(context.Background())
+
+	body := `{"status": "firing", "groupKey": "group-1", "alerts": [{"status": "firing", "labels": {"alertname": "TestAlert"}}]}`
+	req := httptest.NewRequest(http.MethodPost, "/alerts", strings.NewReader(body))
+	req = req.WithContext(withCredential(req.Context(), "alertmanager-eu"))
+	rec := httptest.NewRecorder()
+	server.AlertsPostHandler(rec, req)
+	require.Equal(t, http.StatusAccepted, rec.Code, rec.Body.String())
+	require.NoError(t, server.WebhookQueue.Shutdown(context.Background()))
+
+	entries, err := server.AlertStore.GetAlerts("", 0)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "alertmanager-eu", entries[0].Alert.Credential)
+
+	found, err := server.AlertStore.GetAlerts("alertmanager-eu", 0)
+	require.NoError(t, err)
+	assert.Len(t, found, 1, "alerts can be searched by credential")
+}
+
+// TestAlertsPostHandler_Limits verifies oversized, malformed and overfull
+// webhooks are rejected and counted by reason
+func TestAlertsPostHandler_Limits(t *testing.T) {
+	server, _, _ := newJobActionsTestServer(t)
+	server.WebhookMaxBodyBytes = 512
+	server.WebhookMaxAlerts = 2
+	server.StrictWebhookDecoding = true
+
+	post := func(body string) int {
+		rec := httptest.NewRecorder()
+		server.LimitBody(server.AlertsPostHandler)(rec, httptest.NewRequest(http.MethodPost, "/alerts?wait=true", strings.NewReader(body)))
+		return rec.Code
+	}
+	rejected := func(reason string) float64 {
+		return testutil.ToFloat64(metadata.WebhookRejectedTotal.WithLabelValues(reason))
+	}
+
+	assert.Equal(t, http.StatusOK, post(`{"version":"4","status":"firing","receiver":"openfero","groupKey":"group-1","truncatedAlerts":0,`+
+		`"alerts":[{"status":"firing","labels":{"alertname":"TestAlert"},"fingerprint":"1a2b"}]}`), "all fields of Alertmanager are known")
+
+	tooLarge := rejected("body_too_large")
+	assert.Equal(t, http.StatusRequestEntityTooLarge, post(`{"status":"firing","groupKey":"`+strings.Repeat("x", 512)+`"}`))
+	assert.Equal(t, tooLarge+1, rejected("body_too_large"))
+
+	invalid := rejected("invalid_body")
+	assert.Equal(t, http.StatusBadRequest, post(`{"status":"firing","alerts":[],"unknown":true}`))
+	assert.Equal(t, http.StatusBadRequest, post(`{"status":"firing","alerts":[]}{"status":"firing"}`))
+	assert.Equal(t, invalid+2, rejected("invalid_body"))
+
+	tooMany := rejected("too_many_alerts")
+	alert := `{"labels":{"alertname":"TestAlert"}}`
+	assert.Equal(t, http.StatusBadRequest, post(`{"status":"firing","alerts":[`+alert+`,`+alert+`,`+alert+`]}`))
+	assert.Equal(t, tooMany+1, rejected("too_many_alerts"))
+
+	// Adapters read the body through the same limit
+	server.Adapters = ingest.Adapters{}
+	server.Adapters.Register(ingest.GrafanaAdapter{})
+	req := httptest.NewRequest(http.MethodPost, "/alerts/grafana", strings.NewReader(`{"title":"`+strings.Repeat("x", 512)+`"}`))
+	req.SetPathValue("adapter", "grafana")
+	rec := httptest.NewRecorder()
+	server.LimitBody(server.AlertsAdapterPostHandler)(rec, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+
+	// Signatures aren't verified over bodies beyond the limit
+	signed := AuthMiddleware(AuthConfig{Method: AuthMethodHMAC, HMACSecret: "shared-secret"})(server.AlertsPostHandler)
+	req = httptest.NewRequest(http.MethodPost, "/alerts", strings.NewReader(`{"groupKey":"`+strings.Repeat("x", 512)+`"}`))
+	req.Header.Set(DefaultHMACSignatureHeader, "sha256=00")
+	req.Header.Set(DefaultHMACTimestampHeader, "0")
+	req.Header.Set(DefaultHMACNonceHeader, "nonce")
+	rec = httptest.NewRecorder()
+	tooLarge = rejected("body_too_large")
+	server.LimitBody(signed)(rec, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	assert.Equal(t, tooLarge+1, rejected("body_too_large"))
+}
+
+// TestAlertsPostHandler_Idempotency verifies re-delivered webhooks are
+// acknowledged without creating a second Job
+func TestAlertsPostHandler_Idempotency(t *testing.T) {
+	server, kubeClient, _ := newJobActionsTestServer(t)
+	server.Deliveries = ingest.NewDeliveryLog(time.Minute)
+
+	post := func(body, idempotencyKey string) WebhookResponse {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "/alerts?wait=true", strings.NewReader(body))
+		if idempotencyKey != "" {
+			req.Header.Set(ingest.IdempotencyKeyHeader, idempotencyKey)
+		}
+		rec := httptest.NewRecorder()
+		server.AlertsPostHandler(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var response WebhookResponse
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+		require.Len(t, response.Alerts, 1)
+		return response
+	}
+	body := func(startsAt string) string {
+		return `{"status":"firing","groupKey":"group-1","alerts":[{"labels":{"alertname":"TestAlert"},"startsAt":"` + startsAt + `"}]}`
+	}
+
+	duplicatesBefore := testutil.ToFloat64(metadata.WebhookDuplicatesTotal)
+	assert.Equal(t, WebhookActionCreated, post(body("2026-01-01T00:00:00Z"), "").Alerts[0].Action)
+	assert.Equal(t, WebhookActionDuplicate, post(body("2026-01-01T00:00:00Z"), "").Alerts[0].Action)
+	assert.Equal(t, duplicatesBefore+1, testutil.ToFloat64(metadata.WebhookDuplicatesTotal))
+
+	// A key sent by the caller identifies the delivery even if the payload differs
+	first := post(body("2026-01-01T01:00:00Z"), "delivery-1")
+	assert.NotEmpty(t, first.DeliveryID)
+	retried := post(body("2026-01-01T01:00:01Z"), "delivery-1")
+	assert.Equal(t, WebhookActionDuplicate, retried.Alerts[0].Action)
+	assert.Equal(t, first.DeliveryID, retried.DeliveryID)
+
+	jobs, err := kubeClient.BatchV1().Jobs("openfero").List(context.Background(), metav1.ListOptions{})
+	require.NoError(t, err)
+	assert.Len(t, jobs.Items, 1, "the deduplication of the Operarius still applies to new deliveries")
+}
+
+func TestAlertStoreGetHandler_Query(t *testing.T) {
+	store := memory.NewMemoryStore(10)
+	for _, alertname := range []string{"Disk", "CPU", "Disk", "Memory", "Disk"} {
+		require.NoError(t, store.SaveAlert(alertstore.Alert{Labels: map[string]string{"alertname": alertname}}, "firing"))
+	}
+	require.NoError(t, store.SaveAlert(alertstore.Alert{Labels: map[string]string{"alertname": "Disk"}}, "resolved"))
+	server := &Server{AlertStore: store}
+
+	get := func(query string) *httptest.ResponseRecorder {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		server.AlertStoreGetHandler(rec, httptest.NewRequest(http.MethodGet, "/api/alerts?"+query, nil))
+		return rec
+	}
+	decode := func(rec *httptest.ResponseRecorder) []alertstore.AlertEntry {
+		t.Helper()
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var alerts []alertstore.AlertEntry
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&alerts))
+		return alerts
+	}
+
+	alerts := decode(get(url.Values{"match[]": {`alertname="Disk"`}, "status": {"firing"}}.Encode()))
+	assert.Len(t, alerts, 3)
+
+	// Paging through the alerts returns each of them once
+	seen := map[string]bool{}
+	query := url.Values{"match": {`alertname=~"Disk|CPU"`}, "order": {"asc"}, "limit": {"2"}}
+	for range 3 {
+		rec := get(query.Encode())
+		for _, alert := range decode(rec) {
+			assert.False(t, seen[alert.ID], "alert %s returned twice", alert.ID)
+			seen[alert.ID] = true
+		}
+		query.Set("cursor", rec.Header().Get(NextCursorHeader))
+	}
+	assert.Len(t, seen, 5)
+	assert.Empty(t, query.Get("cursor"), "the last page has no cursor")
+
+	for _, invalid := range []string{
+		"match[]=" + url.QueryEscape("not a matcher"),
+		"from=yesterday",
+		"order=sideways",
+		"job=exploded",
+		"limit=0",
+		"limit=1001",
+		"cursor=garbage!",
+	} {
+		assert.Equal(t, http.StatusBadRequest, get(invalid).Code, invalid)
+	}
+}
+
+// TestAlertsAdapterPostHandler verifies payloads of other alert sources go
+// through the Operarius matching of Alertmanager webhooks
+func TestAlertsAdapterPostHandler(t *testing.T) {
+	server, _, _ := newJobActionsTestServer(t)
+	server.Adapters = ingest.Adapters{}
+	server.Adapters.Register(ingest.GrafanaAdapter{})
+
+	post := func(adapter, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/alerts/"+adapter, strings.NewReader(body))
+		req.SetPathValue("adapter", adapter)
+		rec := httptest.NewRecorder()
+		server.AlertsAdapterPostHandler(rec, req)
+		return rec
+	}
+
+	rec := post("grafana", `{"status":"firing","alerts":[{"status":"firing","labels":{"alertname":"TestAlert"}}]}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var response WebhookResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+	require.Len(t, response.Alerts, 1)
+	assert.Equal(t, WebhookActionCreated, response.Alerts[0].Action)
+	assert.Equal(t, "dedup-operarius", response.Alerts[0].Operarius)
+
+	assert.Equal(t, http.StatusBadRequest, post("grafana", `{"status":"firing","alerts":[]}`).Code)
+	assert.Equal(t, http.StatusNotFound, post("generic", `{}`).Code)
+}
+
+// TestHandleOperariusBasedJobs_EmitsCloudEvents verifies lifecycle events
+// are sent to the configured sink
+func TestHandleOperariusBasedJobs_EmitsCloudEvents(t *testing.T) {
+	var mu sync.Mutex
+	var received []cloudevents.Event
+	sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		event, err := cloudevents.FromRequest(r)
+		require.NoError(t, err)
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, event)
+	}))
+	defer sink.Close()
+
+	server, _, _ := newJobActionsTestServer(t)
+	server.Events = cloudevents.NewEmitter(sink.URL, "/openfero", 10)
+
+	hookMessage := models.HookMessage{
+		Status:   "firing",
+		GroupKey: "group-1",
+		Alerts:   []models.Alert{{Labels: map[string]string{"alertname": "TestAlert"}}},
+	}
+	created := server.handleOperariusBasedJobs(context.Background(), hookMessage)
+	server.handleOperariusBasedJobs(context.Background(), hookMessage)
+	require.NoError(t, server.Events.Close(context.Background()))
+
+	types := make([]string, 0, len(received))
+	for _, event := range received {
+		types = append(types, event.Type)
+	}
+	assert.Equal(t, []string{
+		cloudevents.TypeAlertReceived,
+		cloudevents.TypeJobCreated,
+		cloudevents.TypeAlertReceived,
+		cloudevents.TypeJobDeduplicated,
+	}, types)
+
+	var data cloudevents.JobData
+	require.NoError(t, json.Unmarshal(received[1].Data, &data))
+	assert.Equal(t, created.JobName, data.JobName)
+	assert.Equal(t, "dedup-operarius", data.Operarius)
+	assert.Equal(t, "group-1", data.GroupKey)
+	assert.Equal(t, "openfero/"+created.JobName, received[1].Subject)
+}
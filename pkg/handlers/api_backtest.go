@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/OpenFero/openfero/pkg/alertstore"
+	log "github.com/OpenFero/openfero/pkg/logging"
+	"github.com/OpenFero/openfero/pkg/services"
+)
+
+// maxBacktestBodyBytes bounds uploaded alert history
+const maxBacktestBodyBytes = 32 << 20
+
+// BacktestAPIHandler handles POST requests to /api/backtest
+// @Summary Backtest Operarii against alert history
+// @Description Replays alerts through the current matching and deduplication logic without creating Jobs.
+// @Description Without a body the alert store is replayed; otherwise the body is read as NDJSON as exported by GET /api/alerts?format=ndjson.
+// @Tags jobs
+// @Accept x-ndjson
+// @Produce json
+// @Param includeDisabled query bool false "Treat disabled Operarii as enabled"
+// @Success 200 {object} services.BacktestResult
+// @Failure 400 {string} string "invalid alert history"
+// @Router /api/backtest [post]
+func (s *Server) BacktestAPIHandler(w http.ResponseWriter, r *http.Request) {
+	var opts services.BacktestOptions
+	if value := r.URL.Query().Get("includeDisabled"); value != "" {
+		includeDisabled, err := strconv.ParseBool(value)
+		if err != nil {
+			http.Error(w, "invalid includeDisabled parameter", http.StatusBadRequest)
+			return
+		}
+		opts.IncludeDisabled = includeDisabled
+	}
+
+	entries, err := alertstore.ReadNDJSON(http.MaxBytesReader(w, r.Body, maxBacktestBodyBytes))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if !s.requireOperariusService(w) {
+		return
+	}
+
+	if len(entries) == 0 {
+		entries, err = s.AlertStore.GetAlerts("", 0)
+		if err != nil {
+			log.Error("Error retrieving alerts", "error", err)
+			http.Error(w, "failed to read alert store", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	operarii, err := s.OperariusService.GetOperariiForNamespace(r.Context(), "")
+	if err != nil {
+		log.Error("Failed to get Operarii", "error", err)
+		http.Error(w, "failed to get Operarii", http.StatusInternalServerError)
+		return
+	}
+
+	result := s.OperariusService.Backtest(operarii, entries, opts)
+
+	w.Header().Set(ContentTypeHeader, ApplicationJSONVal)
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		log.Error("Error encoding backtest result", "error", err)
+	}
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/OpenFero/openfero/pkg/alertstore"
+	"github.com/OpenFero/openfero/pkg/services"
+)
+
+func TestBacktestAPIHandler(t *testing.T) {
+	server, _, _ := newJobActionsTestServer(t)
+
+	matching := alertstore.Alert{Labels: map[string]string{"alertname": "TestAlert"}}
+	other := alertstore.Alert{Labels: map[string]string{"alertname": "Other"}}
+	require.NoError(t, server.AlertStore.SaveAlert(matching, "firing"))
+	require.NoError(t, server.AlertStore.SaveAlert(other, "firing"))
+
+	t.Run("invalid parameter", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		server.BacktestAPIHandler(rec, httptest.NewRequest(http.MethodPost, "/api/backtest?includeDisabled=maybe", nil))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("invalid history", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		server.BacktestAPIHandler(rec, httptest.NewRequest(http.MethodPost, "/api/backtest", strings.NewReader("{\n")))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("alert store", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		server.BacktestAPIHandler(rec, httptest.NewRequest(http.MethodPost, "/api/backtest", nil))
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		var result services.BacktestResult
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&result))
+		assert.Equal(t, 2, result.Alerts)
+		assert.Equal(t, 1, result.Unmatched)
+		require.Len(t, result.Operarii, 1)
+		assert.Equal(t, 1, result.Operarii[0].WouldRun)
+	})
+
+	t.Run("exported history", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		server.AlertStoreGetHandler(rec, httptest.NewRequest(http.MethodGet, "/api/alerts?format=ndjson", nil))
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "application/x-ndjson", rec.Header().Get(ContentTypeHeader))
+
+		exported, err := alertstore.ReadNDJSON(bytes.NewReader(rec.Body.Bytes()))
+		require.NoError(t, err)
+		require.Len(t, exported, 2)
+
+		// Replay the matching alert twice, the test Operarius deduplicates forever
+		history := []alertstore.AlertEntry{exported[1], exported[1]}
+		history[1].Timestamp = history[1].Timestamp.Add(time.Hour)
+		var body bytes.Buffer
+		require.NoError(t, alertstore.WriteNDJSON(&body, history))
+
+		rec = httptest.NewRecorder()
+		server.BacktestAPIHandler(rec, httptest.NewRequest(http.MethodPost, "/api/backtest", &body))
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		var result services.BacktestResult
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&result))
+		assert.Equal(t, 2, result.Alerts)
+		assert.Zero(t, result.Unmatched)
+		require.Len(t, result.Operarii, 1)
+		assert.Equal(t, 1, result.Operarii[0].WouldRun)
+		assert.Equal(t, 1, result.Operarii[0].DedupSuppressed)
+	})
+}
@@ -0,0 +1,377 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"maps"
+	"net/http"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+
+	operariusv1alpha1 "github.com/OpenFero/openfero/api/v1alpha1"
+	"github.com/OpenFero/openfero/pkg/alertstore"
+	log "github.com/OpenFero/openfero/pkg/logging"
+	"github.com/OpenFero/openfero/pkg/metadata"
+	"github.com/OpenFero/openfero/pkg/models"
+	"github.com/OpenFero/openfero/pkg/services"
+)
+
+// Operator actions on remediation jobs
+const (
+	JobActionCancel  = "cancel"
+	JobActionRetry   = "retry"
+	JobActionRerun   = "rerun"
+	JobActionTrigger = "trigger"
+)
+
+// JobActionResult describes an operator action on a remediation job. It is
+// returned to the caller and broadcast to WebSocket clients as "job_action".
+type JobActionResult struct {
+	Action     string    `json:"action" enum:"cancel,retry,rerun,trigger"`
+	Namespace  string    `json:"namespace"`
+	JobName    string    `json:"jobName,omitempty"`    // Job the action was applied to
+	NewJobName string    `json:"newJobName,omitempty"` // Job created by a retry, rerun or trigger
+	AlertID    string    `json:"alertId,omitempty"`    // Stored alert a rerun was based on
+	Operarius  string    `json:"operarius,omitempty"`
+	Principal  string    `json:"principal"`
+	Timestamp  time.Time `json:"timestamp"`
+}
+
+// RerunRequest is the optional body of a rerun request
+type RerunRequest struct {
+	// Operarius to run; defaults to the one that handled the alert, or the best match
+	Operarius string `json:"operarius,omitempty"`
+}
+
+// JobCancelAPIHandler handles POST requests to /api/jobs/{namespace}/{name}/cancel
+// @Summary Cancel a running remediation job
+// @Description Deletes a running Job created by OpenFero together with its pods
+// @Tags jobs
+// @Produce json
+// @Param namespace path string true "Namespace of the job"
+// @Param name path string true "Name of the job"
+// @Success 200 {object} JobActionResult
+// @Failure 403 {string} string "job is not managed by OpenFero"
+// @Failure 404 {string} string "job not found"
+// @Failure 409 {string} string "job has already finished"
+// @Router /api/jobs/{namespace}/{name}/cancel [post]
+func (s *Server) JobCancelAPIHandler(w http.ResponseWriter, r *http.Request) {
+	result := s.newJobActionResult(r, JobActionCancel)
+	result.Namespace = r.PathValue("namespace")
+	result.JobName = r.PathValue("name")
+
+	if !s.requireOperariusService(w) {
+		return
+	}
+
+	ctx := r.Context()
+	job, err := s.OperariusService.CancelJob(ctx, result.Namespace, result.JobName)
+	if err != nil {
+		s.jobActionFailed(w, result, err)
+		return
+	}
+	result.Operarius = job.Labels["openfero.io/operarius"]
+
+	if operarius := s.jobOperarius(r, job); operarius != nil {
+		if err := s.OperariusService.UpdateOperariusCancelledStatus(ctx, operarius, job.Name); err != nil {
+			log.Warn("Failed to update Operarius status",
+				"error", err,
+				"operarius", operarius.Name)
+		}
+	}
+	services.UpdateAlertJobStatus(s.AlertStore, job.Namespace, job.Name, services.ExecutionStatusCancelled)
+
+	s.jobActionSucceeded(w, http.StatusOK, result)
+}
+
+// JobRetryAPIHandler handles POST requests to /api/jobs/{namespace}/{name}/retry
+// @Summary Retry a failed remediation job
+// @Description Creates a new Job with the same rendered spec as a failed Job created by OpenFero
+// @Tags jobs
+// @Produce json
+// @Param namespace path string true "Namespace of the job"
+// @Param name path string true "Name of the job"
+// @Success 201 {object} JobActionResult
+// @Failure 403 {string} string "job is not managed by OpenFero"
+// @Failure 404 {string} string "job not found"
+// @Failure 409 {string} string "only failed jobs can be retried"
+// @Router /api/jobs/{namespace}/{name}/retry [post]
+func (s *Server) JobRetryAPIHandler(w http.ResponseWriter, r *http.Request) {
+	result := s.newJobActionResult(r, JobActionRetry)
+	result.Namespace = r.PathValue("namespace")
+	result.JobName = r.PathValue("name")
+
+	if !s.requireOperariusService(w) {
+		return
+	}
+
+	ctx := r.Context()
+	job, err := s.OperariusService.RetryJob(ctx, result.Namespace, result.JobName)
+	if err != nil {
+		s.jobActionFailed(w, result, err)
+		return
+	}
+	metadata.JobsCreatedTotal.Inc()
+	s.Lifecycles.JobCreated(job.Namespace, job.Name)
+	result.NewJobName = job.Name
+	result.Operarius = job.Labels["openfero.io/operarius"]
+
+	jobInfo := &alertstore.JobInfo{
+		JobName:       job.Name,
+		Namespace:     job.Namespace,
+		OperariusName: result.Operarius,
+		Image:         getFirstContainerImage(job),
+	}
+	operarius := s.jobOperarius(r, job)
+	if operarius != nil {
+		if err := s.OperariusService.UpdateOperariusStatus(ctx, operarius, job.Name); err != nil {
+			log.Warn("Failed to update Operarius status",
+				"error", err,
+				"operarius", operarius.Name)
+		}
+		jobInfo = newJobInfo(job, operarius)
+	}
+
+	// Record the retry as a new alert entry, so the failure, output and
+	// timings of its job are recorded like those of the original
+	s.saveTriggeredAlert(s.retriedHookMessage(result.Namespace, result.JobName, operarius), services.TriggerRetry, jobInfo)
+
+	s.jobActionSucceeded(w, http.StatusCreated, result)
+}
+
+// retriedHookMessage returns the hook message of the stored alert that
+// triggered a job. If it is no longer stored, the alert is made up from the
+// alert selector of the Operarius like for a manual trigger.
+func (s *Server) retriedHookMessage(namespace, jobName string, operarius *operariusv1alpha1.Operarius) models.HookMessage {
+	result, err := s.AlertStore.QueryAlerts(alertstore.Query{Text: jobName})
+	if err != nil {
+		log.Warn("Failed to look up the alert of a retried job",
+			"error", err,
+			"job", jobName)
+	}
+	for _, entry := range result.Entries {
+		if entry.JobInfo != nil && entry.JobInfo.Namespace == namespace && entry.JobInfo.JobName == jobName {
+			return services.HookMessageFromAlertEntry(entry)
+		}
+	}
+	if operarius == nil {
+		return manualHookMessage(TriggerRequest{}, "", "firing")
+	}
+	return manualHookMessage(TriggerRequest{}, operarius.Spec.AlertSelector.AlertName, "firing")
+}
+
+// AlertRerunAPIHandler handles POST requests to /api/alerts/{id}/rerun
+// @Summary Re-run an Operarius against a stored alert
+// @Description Creates a new remediation Job for an alert from the alert store, bypassing deduplication
+// @Tags alerts
+// @Accept json
+// @Produce json
+// @Param id path string true "ID of the stored alert"
+// @Param request body RerunRequest false "Operarius to run"
+// @Success 201 {object} JobActionResult
+// @Failure 400 {string} string "invalid request body"
+// @Failure 404 {string} string "alert or Operarius not found"
+// @Router /api/alerts/{id}/rerun [post]
+func (s *Server) AlertRerunAPIHandler(w http.ResponseWriter, r *http.Request) {
+	result := s.newJobActionResult(r, JobActionRerun)
+	result.AlertID = r.PathValue("id")
+
+	var request RerunRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if !s.requireOperariusService(w) {
+		return
+	}
+
+	entry, err := s.AlertStore.GetAlert(result.AlertID)
+	if err != nil {
+		s.jobActionFailed(w, result, err)
+		return
+	}
+
+	ctx := r.Context()
+	hookMessage := services.HookMessageFromAlertEntry(*entry)
+
+	operariusName := request.Operarius
+	if operariusName == "" && entry.JobInfo != nil {
+		operariusName = entry.JobInfo.OperariusName
+	}
+	operarius, err := s.findOperarius(r, operariusName, hookMessage)
+	if err != nil {
+		s.jobActionFailed(w, result, err)
+		return
+	}
+	result.Operarius = operarius.Name
+	result.Namespace = operarius.Namespace
+
+	job, err := s.OperariusService.CreateJobFromOperariusWithOptions(ctx, operarius, hookMessage, services.JobOptions{
+		Trigger:             services.TriggerRerun,
+		IgnoreDeduplication: true,
+		Annotations:         map[string]string{services.RerunOfAnnotation: entry.ID},
+	})
+	if err != nil {
+		metadata.JobsFailedTotal.Inc()
+		s.jobActionFailed(w, result, err)
+		return
+	}
+	metadata.JobsCreatedTotal.Inc()
+	s.Lifecycles.JobCreated(job.Namespace, job.Name)
+	result.NewJobName = job.Name
+
+	if err := s.OperariusService.UpdateOperariusStatus(ctx, operarius, job.Name); err != nil {
+		log.Warn("Failed to update Operarius status",
+			"error", err,
+			"operarius", operarius.Name)
+	}
+
+	// Record the rerun as a new alert entry so its job shows up in the alert history
+	s.saveTriggeredAlert(hookMessage, services.TriggerRerun, newJobInfo(job, operarius))
+
+	s.jobActionSucceeded(w, http.StatusCreated, result)
+}
+
+// saveTriggeredAlert stores the alert of a hook message that did not come from
+// Alertmanager, marked with what triggered it. jobInfo is nil if no Job was created.
+func (s *Server) saveTriggeredAlert(hookMessage models.HookMessage, trigger string, jobInfo *alertstore.JobInfo) {
+	for _, alert := range hookMessage.Alerts {
+		alert.Labels = maps.Clone(alert.Labels)
+		if alert.Labels == nil {
+			alert.Labels = make(map[string]string)
+		}
+		alert.Labels[services.TriggerLabel] = trigger
+		if jobInfo == nil {
+			services.SaveAlert(s.AlertStore, alert, hookMessage.Status)
+		} else {
+			services.SaveAlertWithJobInfo(s.AlertStore, alert, hookMessage.Status, jobInfo)
+		}
+		s.Lifecycles.AlertSaved(alert, hookMessage.Status, jobInfo)
+	}
+}
+
+// errOperariusNotFound is returned when a job action names an unknown Operarius
+var errOperariusNotFound = errors.New("operarius not found")
+
+// errOperariusDisabled is returned when manually triggering a disabled Operarius
+var errOperariusDisabled = errors.New("operarius is disabled")
+
+// findOperarius returns a copy of the named Operarius, or the best match for the hook message if name is empty
+func (s *Server) findOperarius(r *http.Request, name string, hookMessage models.HookMessage) (*operariusv1alpha1.Operarius, error) {
+	operarii, err := s.OperariusService.GetOperariiForNamespace(r.Context(), "")
+	if err != nil {
+		return nil, err
+	}
+
+	if name == "" {
+		operarius, err := s.OperariusService.FindMatchingOperarius(hookMessage, operarii)
+		if err != nil {
+			return nil, errors.Join(errOperariusNotFound, err)
+		}
+		return operarius.DeepCopy(), nil
+	}
+
+	for i := range operarii {
+		if operarii[i].Name == name {
+			return operarii[i].DeepCopy(), nil
+		}
+	}
+	return nil, errOperariusNotFound
+}
+
+// jobOperarius returns a copy of the Operarius a job was created from, or nil if it cannot be found
+func (s *Server) jobOperarius(r *http.Request, job *batchv1.Job) *operariusv1alpha1.Operarius {
+	name, ok := job.Labels["openfero.io/operarius"]
+	if !ok {
+		return nil
+	}
+	operarius, err := s.OperariusService.GetOperarius(r.Context(), name, job.Namespace)
+	if err != nil {
+		log.Warn("Failed to get Operarius for job",
+			"error", err,
+			"job", job.Name,
+			"operarius", name)
+		return nil
+	}
+	return operarius.DeepCopy()
+}
+
+// requireOperariusService writes an error if Operarius support is not initialized
+func (s *Server) requireOperariusService(w http.ResponseWriter) bool {
+	if s.OperariusService == nil {
+		log.Error("OperariusService is not initialized")
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return false
+	}
+	return true
+}
+
+// newJobActionResult starts the record of a job action requested by r
+func (s *Server) newJobActionResult(r *http.Request, action string) JobActionResult {
+	return JobActionResult{
+		Action:    action,
+		Principal: PrincipalFromContext(r.Context()),
+		Timestamp: time.Now(),
+	}
+}
+
+// jobActionFailed audits a failed job action and writes the matching error response
+func (s *Server) jobActionFailed(w http.ResponseWriter, result JobActionResult, err error) {
+	status := http.StatusInternalServerError
+	message := "failed to " + result.Action + " job"
+	switch {
+	case errors.Is(err, services.ErrJobNotManaged):
+		status, message = http.StatusForbidden, err.Error()
+	case errors.Is(err, services.ErrJobAlreadyFinished), errors.Is(err, services.ErrJobNotFailed),
+		errors.Is(err, errOperariusDisabled), errors.Is(err, services.ErrJobDeduplicated):
+		status, message = http.StatusConflict, err.Error()
+	case errors.Is(err, alertstore.ErrAlertNotFound), errors.Is(err, errOperariusNotFound):
+		status, message = http.StatusNotFound, err.Error()
+	case k8serrors.IsNotFound(err):
+		status, message = http.StatusNotFound, "job not found"
+	}
+
+	outcome := "rejected"
+	if status == http.StatusInternalServerError {
+		outcome = "failed"
+	}
+	auditJobAction(result, outcome, err)
+
+	http.Error(w, message, status)
+}
+
+// jobActionSucceeded audits a successful job action, broadcasts it and writes the result
+func (s *Server) jobActionSucceeded(w http.ResponseWriter, status int, result JobActionResult) {
+	auditJobAction(result, "succeeded", nil)
+	GetWSHub().Broadcast("job_action", result)
+
+	w.Header().Set(ContentTypeHeader, ApplicationJSONVal)
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		log.Error("Error encoding job action result", "error", err)
+	}
+}
+
+// auditJobAction writes the audit log entry for a job action and counts it
+func auditJobAction(result JobActionResult, outcome string, err error) {
+	metadata.JobActionsTotal.WithLabelValues(result.Action, outcome).Inc()
+
+	attrs := []any{
+		"action", result.Action,
+		"outcome", outcome,
+		"principal", result.Principal,
+		"namespace", result.Namespace,
+		"job", result.JobName,
+		"newJob", result.NewJobName,
+		"alertId", result.AlertID,
+		"operarius", result.Operarius,
+	}
+	if err != nil {
+		attrs = append(attrs, "error", err)
+	}
+	log.Info("Audit: job action", attrs...)
+}
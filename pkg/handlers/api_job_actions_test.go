@@ -0,0 +1,248 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+
+	operariusv1alpha1 "github.com/OpenFero/openfero/api/v1alpha1"
+	"github.com/OpenFero/openfero/pkg/alertstore"
+	"github.com/OpenFero/openfero/pkg/alertstore/memory"
+	"github.com/OpenFero/openfero/pkg/services"
+)
+
+func managedJob(name string, conditionType batchv1.JobConditionType) *batchv1.Job {
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "openfero",
+			Labels: map[string]string{
+				"openfero.io/managed-by":   "openfero",
+				"openfero.io/operarius":    "dedup-operarius",
+				batchv1.ControllerUidLabel: "1234",
+			},
+		},
+		Spec: batchv1.JobSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{batchv1.ControllerUidLabel: "1234"}},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{
+					batchv1.ControllerUidLabel: "1234",
+					batchv1.JobNameLabel:       name,
+					"openfero.io/managed-by":   "openfero",
+				}},
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					Containers:    []corev1.Container{{Name: "remediation", Image: "busybox", Args: []string{"rendered"}}},
+				},
+			},
+		},
+	}
+	if conditionType != "" {
+		job.Status.Conditions = []batchv1.JobCondition{{Type: conditionType, Status: corev1.ConditionTrue}}
+	}
+	return job
+}
+
+func newJobActionsTestServer(t *testing.T, objects ...runtime.Object) (*Server, *fake.Clientset, *stubOperariusClient) {
+	t.Helper()
+	kubeClient := fake.NewSimpleClientset(objects...)
+	operarius := dedupTestOperarius()
+	operarius.Status.LastExecutedJobName = "running-job"
+	operarius.Status.LastExecutionStatus = "Pending"
+	operariusClient := &stubOperariusClient{
+		namespace: "openfero",
+		operarii:  []operariusv1alpha1.Operarius{operarius},
+	}
+
+	server := &Server{
+		AlertStore:       memory.NewMemoryStore(100),
+		OperariusService: services.NewOperariusServiceWithClient(kubeClient, operariusClient),
+	}
+	require.NoError(t, server.AlertStore.Initialize())
+	return server, kubeClient, operariusClient
+}
+
+func jobActionsMux(server *Server) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/jobs/{namespace}/{name}/cancel", server.JobCancelAPIHandler)
+	mux.HandleFunc("POST /api/jobs/{namespace}/{name}/retry", server.JobRetryAPIHandler)
+	mux.HandleFunc("POST /api/alerts/{id}/rerun", server.AlertRerunAPIHandler)
+	return mux
+}
+
+func TestJobCancelAPIHandler(t *testing.T) {
+	server, kubeClient, operariusClient := newJobActionsTestServer(t,
+		managedJob("running-job", ""),
+		managedJob("finished-job", batchv1.JobComplete),
+		&batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "foreign-job", Namespace: "openfero"}},
+	)
+	require.NoError(t, server.AlertStore.SaveAlertWithJobInfo(
+		alertstore.Alert{Labels: map[string]string{"alertname": "TestAlert"}},
+		"firing",
+		&alertstore.JobInfo{JobName: "running-job", Namespace: "openfero", LastExecutionStatus: "Pending"},
+	))
+	mux := jobActionsMux(server)
+
+	t.Run("running job", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/jobs/openfero/running-job/cancel", nil))
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		var result JobActionResult
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&result))
+		assert.Equal(t, JobActionCancel, result.Action)
+		assert.Equal(t, "running-job", result.JobName)
+		assert.Equal(t, "dedup-operarius", result.Operarius)
+		assert.Equal(t, AnonymousPrincipal, result.Principal)
+
+		_, err := kubeClient.BatchV1().Jobs("openfero").Get(context.Background(), "running-job", metav1.GetOptions{})
+		assert.True(t, k8serrors.IsNotFound(err), "cancelled job should be deleted")
+
+		operarii, _ := operariusClient.List()
+		assert.Equal(t, services.ExecutionStatusCancelled, operarii[0].Status.LastExecutionStatus)
+
+		alerts, err := server.AlertStore.GetAlerts("", 10)
+		require.NoError(t, err)
+		assert.Equal(t, services.ExecutionStatusCancelled, alerts[0].JobInfo.LastExecutionStatus)
+	})
+
+	tests := []struct {
+		name       string
+		job        string
+		wantStatus int
+	}{
+		{"finished job", "finished-job", http.StatusConflict},
+		{"job not managed by openfero", "foreign-job", http.StatusForbidden},
+		{"missing job", "missing", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/jobs/openfero/"+tt.job+"/cancel", nil))
+			assert.Equal(t, tt.wantStatus, rec.Code)
+		})
+	}
+}
+
+func TestJobRetryAPIHandler(t *testing.T) {
+	server, kubeClient, operariusClient := newJobActionsTestServer(t,
+		managedJob("failed-job", batchv1.JobFailed),
+		managedJob("running-job", ""),
+	)
+	require.NoError(t, server.AlertStore.SaveAlertWithJobInfo(
+		alertstore.Alert{Labels: map[string]string{"alertname": "TestAlert"}},
+		"firing",
+		&alertstore.JobInfo{OperariusName: "dedup-operarius", JobName: "failed-job", Namespace: "openfero"},
+	))
+	mux := jobActionsMux(server)
+
+	t.Run("running job cannot be retried", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/jobs/openfero/running-job/retry", nil))
+		assert.Equal(t, http.StatusConflict, rec.Code)
+	})
+
+	t.Run("failed job", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/jobs/openfero/failed-job/retry", nil))
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+		jobs, err := kubeClient.BatchV1().Jobs("openfero").List(context.Background(), metav1.ListOptions{
+			LabelSelector: services.TriggerLabel + "=" + services.TriggerRetry,
+		})
+		require.NoError(t, err)
+		require.Len(t, jobs.Items, 1)
+
+		retry := jobs.Items[0]
+		assert.Equal(t, "dedup-operarius-", retry.GenerateName)
+		assert.Equal(t, "failed-job", retry.Annotations[services.RetryOfAnnotation])
+		assert.Equal(t, []string{"rendered"}, retry.Spec.Template.Spec.Containers[0].Args, "rendered spec should be reused")
+		assert.Nil(t, retry.Spec.Selector)
+		assert.NotContains(t, retry.Labels, batchv1.ControllerUidLabel)
+		assert.NotContains(t, retry.Spec.Template.Labels, batchv1.JobNameLabel)
+		assert.Equal(t, "openfero", retry.Spec.Template.Labels["openfero.io/managed-by"])
+
+		operarii, _ := operariusClient.List()
+		assert.Equal(t, int32(1), operarii[0].Status.ExecutionCount)
+		assert.Equal(t, "Pending", operarii[0].Status.LastExecutionStatus)
+
+		// The retry is stored with the alert of the original job, so its
+		// failure, output and timings are recorded
+		alerts, err := server.AlertStore.GetAlerts("", 1)
+		require.NoError(t, err)
+		require.Len(t, alerts, 1)
+		assert.Equal(t, "TestAlert", alerts[0].Alert.Labels["alertname"])
+		assert.Equal(t, services.TriggerRetry, alerts[0].Alert.Labels[services.TriggerLabel])
+		require.NotNil(t, alerts[0].JobInfo)
+		assert.Equal(t, "dedup-operarius", alerts[0].JobInfo.OperariusName)
+		assert.Equal(t, retry.Name, alerts[0].JobInfo.JobName)
+	})
+}
+
+func TestAlertRerunAPIHandler(t *testing.T) {
+	server, kubeClient, operariusClient := newJobActionsTestServer(t)
+	require.NoError(t, server.AlertStore.SaveAlertWithJobInfo(
+		alertstore.Alert{Labels: map[string]string{"alertname": "TestAlert"}},
+		"firing",
+		&alertstore.JobInfo{OperariusName: "dedup-operarius", JobName: "old-job", Namespace: "openfero"},
+	))
+	alerts, err := server.AlertStore.GetAlerts("", 10)
+	require.NoError(t, err)
+	alertID := alerts[0].ID
+	require.NotEmpty(t, alertID)
+	mux := jobActionsMux(server)
+
+	t.Run("unknown alert", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/alerts/unknown/rerun", nil))
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("unknown operarius", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/alerts/"+alertID+"/rerun",
+			strings.NewReader(`{"operarius":"missing"}`)))
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("stored alert", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/alerts/"+alertID+"/rerun", nil))
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+		var result JobActionResult
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&result))
+		assert.Equal(t, alertID, result.AlertID)
+		assert.Equal(t, "dedup-operarius", result.Operarius)
+
+		// Reruns bypass deduplication, so the job gets a generated name
+		jobs, err := kubeClient.BatchV1().Jobs("openfero").List(context.Background(), metav1.ListOptions{})
+		require.NoError(t, err)
+		require.Len(t, jobs.Items, 1)
+		assert.Equal(t, "dedup-operarius-", jobs.Items[0].GenerateName)
+		assert.Equal(t, services.TriggerRerun, jobs.Items[0].Labels[services.TriggerLabel])
+		assert.Equal(t, alertID, jobs.Items[0].Annotations[services.RerunOfAnnotation])
+
+		operarii, _ := operariusClient.List()
+		assert.Equal(t, int32(1), operarii[0].Status.ExecutionCount)
+
+		alerts, err := server.AlertStore.GetAlerts("", 10)
+		require.NoError(t, err)
+		require.Len(t, alerts, 2)
+		assert.Equal(t, services.TriggerRerun, alerts[0].Alert.Labels[services.TriggerLabel])
+		assert.Equal(t, "dedup-operarius", alerts[0].JobInfo.OperariusName)
+		assert.NotContains(t, alerts[1].Alert.Labels, services.TriggerLabel, "original alert must stay untouched")
+	})
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+
+	log "github.com/OpenFero/openfero/pkg/logging"
+	"github.com/OpenFero/openfero/pkg/services"
+)
+
+// logCopyBufferSize is the size of the chunks in which logs are forwarded to the client
+const logCopyBufferSize = 4096
+
+// JobLogsAPIHandler handles GET requests to /api/jobs/{namespace}/{name}/logs
+// @Summary Get the logs of a remediation job
+// @Description Streams the pod logs of a Job created by OpenFero. With follow=true the response stays open until the job's container exits.
+// @Tags jobs
+// @Produce plain
+// @Param namespace path string true "Namespace of the job"
+// @Param name path string true "Name of the job"
+// @Param follow query bool false "Follow the log stream"
+// @Param container query string false "Container to read logs from (defaults to the first container)"
+// @Success 200 {string} string "log output"
+// @Failure 403 {string} string "job is not managed by OpenFero"
+// @Failure 404 {string} string "job or pod not found"
+// @Router /api/jobs/{namespace}/{name}/logs [get]
+func (s *Server) JobLogsAPIHandler(w http.ResponseWriter, r *http.Request) {
+	namespace := r.PathValue("namespace")
+	name := r.PathValue("name")
+	container := r.URL.Query().Get("container")
+
+	follow := false
+	if value := r.URL.Query().Get("follow"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			http.Error(w, "invalid follow parameter", http.StatusBadRequest)
+			return
+		}
+		follow = parsed
+	}
+
+	if s.OperariusService == nil {
+		log.Error("OperariusService is not initialized")
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	log.Debug("Processing job logs request",
+		"namespace", namespace,
+		"job", name,
+		"follow", follow,
+		"remoteAddr", r.RemoteAddr)
+
+	stream, err := s.OperariusService.StreamJobLogs(r.Context(), namespace, name, container, follow)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrJobNotManaged):
+			http.Error(w, err.Error(), http.StatusForbidden)
+		case errors.Is(err, services.ErrJobHasNoPods), k8serrors.IsNotFound(err):
+			http.Error(w, "job not found", http.StatusNotFound)
+		default:
+			log.Error("Failed to stream job logs",
+				"namespace", namespace,
+				"job", name,
+				"error", err)
+			http.Error(w, "failed to stream job logs", http.StatusInternalServerError)
+		}
+		return
+	}
+	defer func() {
+		if err := stream.Close(); err != nil {
+			log.Debug("Failed to close log stream", "job", name, "error", err)
+		}
+	}()
+
+	rc := http.NewResponseController(w)
+	if follow {
+		// A followed stream outlives the server's write timeout
+		if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+			log.Debug("Could not clear write deadline for log stream", "error", err)
+		}
+	}
+
+	w.Header().Set(ContentTypeHeader, "text/plain; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+
+	buf := make([]byte, logCopyBufferSize)
+	for {
+		n, readErr := stream.Read(buf)
+		if n > 0 {
+			if _, err := w.Write(buf[:n]); err != nil {
+				log.Debug("Client went away while streaming job logs", "job", name, "error", err)
+				return
+			}
+			// Flush every chunk so followers see lines as they are written
+			_ = rc.Flush()
+		}
+		if readErr != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/OpenFero/openfero/pkg/services"
+)
+
+func newJobLogsTestServer() *Server {
+	kubeClient := fake.NewSimpleClientset(
+		&batchv1.Job{ObjectMeta: metav1.ObjectMeta{
+			Name:      "remediation-abc",
+			Namespace: "openfero",
+			Labels:    map[string]string{"openfero.io/managed-by": "openfero"},
+		}},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "remediation-abc-1",
+				Namespace: "openfero",
+				Labels:    map[string]string{batchv1.JobNameLabel: "remediation-abc"},
+			},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "remediation", Image: "busybox"}}},
+		},
+		&batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "foreign-job", Namespace: "openfero"}},
+		&batchv1.Job{ObjectMeta: metav1.ObjectMeta{
+			Name:      "pending-job",
+			Namespace: "openfero",
+			Labels:    map[string]string{"openfero.io/managed-by": "openfero"},
+		}},
+	)
+	return &Server{OperariusService: services.NewOperariusService(kubeClient)}
+}
+
+func TestJobLogsAPIHandler(t *testing.T) {
+	server := newJobLogsTestServer()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/jobs/{namespace}/{name}/logs", server.JobLogsAPIHandler)
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+		wantBody   string
+	}{
+		{"managed job", "/api/jobs/openfero/remediation-abc/logs", http.StatusOK, "fake logs"},
+		{"managed job followed", "/api/jobs/openfero/remediation-abc/logs?follow=true", http.StatusOK, "fake logs"},
+		{"job not managed by openfero", "/api/jobs/openfero/foreign-job/logs", http.StatusForbidden, ""},
+		{"job without pods", "/api/jobs/openfero/pending-job/logs", http.StatusNotFound, ""},
+		{"missing job", "/api/jobs/openfero/missing/logs", http.StatusNotFound, ""},
+		{"invalid follow", "/api/jobs/openfero/remediation-abc/logs?follow=maybe", http.StatusBadRequest, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			if tt.wantBody != "" {
+				assert.Equal(t, tt.wantBody, rec.Body.String())
+				assert.Equal(t, "text/plain; charset=utf-8", rec.Header().Get(ContentTypeHeader))
+			}
+		})
+	}
+}
+
+func TestWSHub_LogSubscriptions(t *testing.T) {
+	hub := newWSHub()
+	go hub.run()
+
+	opened := make(chan JobRef, 4)
+	hub.SetLogStreamer(func(ctx context.Context, namespace, name string) (io.ReadCloser, error) {
+		opened <- JobRef{Namespace: namespace, Name: name}
+		return io.NopCloser(strings.NewReader("line one\nline two\n")), nil
+	})
+
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		require.NoError(t, err)
+		client := &WSClient{hub: hub, conn: conn, send: make(chan []byte, 256)}
+		hub.register <- client
+		go client.writePump()
+		go client.readPump()
+	}))
+	defer httpServer.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(httpServer.URL, "http"), nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	require.NoError(t, conn.WriteJSON(map[string]any{
+		"type": wsTypeSubscribeLogs,
+		"data": JobRef{Namespace: "openfero", Name: "remediation-abc"},
+	}))
+
+	select {
+	case ref := <-opened:
+		assert.Equal(t, JobRef{Namespace: "openfero", Name: "remediation-abc"}, ref)
+	case <-time.After(5 * time.Second):
+		t.Fatal("log stream was not opened")
+	}
+
+	var received []WSMessage
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for len(received) == 0 || received[len(received)-1].Type != wsTypeJobLogEnd {
+		_, data, err := conn.ReadMessage()
+		require.NoError(t, err)
+		// The write pump batches queued messages separated by newlines
+		for _, raw := range strings.Split(string(data), "\n") {
+			var msg WSMessage
+			require.NoError(t, json.Unmarshal([]byte(raw), &msg))
+			received = append(received, msg)
+		}
+	}
+
+	require.Len(t, received, 3)
+	assert.Equal(t, wsTypeJobLog, received[0].Type)
+	assert.Equal(t, "line one", received[0].Data.(map[string]any)["line"])
+	assert.Equal(t, "remediation-abc", received[0].Data.(map[string]any)["name"])
+	assert.Equal(t, "line two", received[1].Data.(map[string]any)["line"])
+
+	// The finished stream is dropped so a new subscription starts a fresh one
+	assert.Eventually(t, func() bool {
+		hub.logs.mu.RLock()
+		defer hub.logs.mu.RUnlock()
+		return len(hub.logs.streams) == 0
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestWSHub_UnsubscribeAllLogsStopsStream(t *testing.T) {
+	hub := newWSHub()
+
+	ctxCh := make(chan context.Context, 1)
+	hub.SetLogStreamer(func(ctx context.Context, namespace, name string) (io.ReadCloser, error) {
+		ctxCh <- ctx
+		reader, writer := io.Pipe()
+		go func() {
+			<-ctx.Done()
+			_ = writer.Close()
+		}()
+		return reader, nil
+	})
+
+	client := &WSClient{hub: hub, send: make(chan []byte, 1)}
+	ref := JobRef{Namespace: "openfero", Name: "remediation-abc"}
+	hub.subscribeLogs(client, ref)
+
+	var streamCtx context.Context
+	select {
+	case streamCtx = <-ctxCh:
+	case <-time.After(5 * time.Second):
+		t.Fatal("log stream was not opened")
+	}
+
+	hub.unsubscribeAllLogs(client)
+
+	select {
+	case <-streamCtx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("log stream was not cancelled after the last subscriber left")
+	}
+
+	// A client that is going away must not be subscribed again
+	hub.subscribeLogs(client, ref)
+	hub.logs.mu.RLock()
+	defer hub.logs.mu.RUnlock()
+	assert.Empty(t, hub.logs.streams)
+}
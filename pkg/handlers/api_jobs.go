@@ -7,6 +7,7 @@ import (
 
 	log "github.com/OpenFero/openfero/pkg/logging"
 	"github.com/OpenFero/openfero/pkg/models"
+	"github.com/OpenFero/openfero/pkg/services"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -53,6 +54,7 @@ func (s *Server) JobsAPIHandler(w http.ResponseWriter, r *http.Request) {
 					LastExecutionTime:   lastExecutionTime,
 					LastExecutedJobName: op.Status.LastExecutedJobName,
 					LastExecutionStatus: op.Status.LastExecutionStatus,
+					LastFailure:         services.ToAlertStoreJobFailure(op.Status.LastFailure),
 				})
 			}
 		}
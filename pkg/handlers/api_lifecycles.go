@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/OpenFero/openfero/pkg/alertstore"
+	log "github.com/OpenFero/openfero/pkg/logging"
+)
+
+// States of alert lifecycles a query can filter by
+const (
+	LifecycleStateOpen     = "open"
+	LifecycleStateResolved = "resolved"
+)
+
+// lifecycleFilter selects lifecycles correlated from the alert store
+type lifecycleFilter struct {
+	matchers    []*alertstore.Matcher
+	fingerprint string
+	operarius   string
+	state       string
+	remediated  *bool
+	from, to    time.Time
+	limit       int
+}
+
+// LifecyclesAPIHandler handles GET requests to /api/lifecycles
+// @Summary Query alert lifecycles
+// @Description Correlates the stored firing and resolved alerts by fingerprint into lifecycles, with the time to
+// @Description remediation, the duration of the Job and the time to resolve. Lifecycles are returned most recently
+// @Description firing first.
+// @Tags alerts
+// @Produce json
+// @Param match[] query []string false "Label matchers like alertname=\"Disk\"" collectionFormat(multi)
+// @Param fingerprint query string false "Fingerprint of the alert"
+// @Param operarius query string false "Name of the Operarius whose Job remediated the alert"
+// @Param state query string false "Whether the alert is still firing" Enums(open, resolved)
+// @Param remediated query bool false "Whether the alert resolved after its Job succeeded"
+// @Param from query string false "Only lifecycles that started firing at or after this RFC 3339 time"
+// @Param to query string false "Only lifecycles that started firing before this RFC 3339 time"
+// @Param limit query int false "Maximum number of lifecycles, 100 by default and at most 1000"
+// @Success 200 {array} alertstore.Lifecycle
+// @Failure 400 {string} string "invalid query parameter"
+// @Router /api/lifecycles [get]
+func (s *Server) LifecyclesAPIHandler(w http.ResponseWriter, r *http.Request) {
+	filter, err := lifecycleFilterFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Label matchers select whole lifecycles, all entries of an alert have its labels
+	result, err := s.AlertStore.QueryAlerts(alertstore.Query{Matchers: filter.matchers, Order: alertstore.SortOldestFirst})
+	if err != nil {
+		log.Error("Error retrieving alerts", "error", err)
+		http.Error(w, "failed to read alert store", http.StatusInternalServerError)
+		return
+	}
+
+	lifecycles := make([]alertstore.Lifecycle, 0)
+	for _, lifecycle := range alertstore.Correlate(result.Entries) {
+		if filter.matches(lifecycle) {
+			lifecycles = append(lifecycles, lifecycle)
+			if len(lifecycles) == filter.limit {
+				break
+			}
+		}
+	}
+
+	w.Header().Set(ContentTypeHeader, ApplicationJSONVal)
+	if err := json.NewEncoder(w).Encode(lifecycles); err != nil {
+		log.Error("Error encoding lifecycles", "error", err)
+	}
+}
+
+// lifecycleFilterFromRequest parses the parameters of GET /api/lifecycles
+func lifecycleFilterFromRequest(r *http.Request) (lifecycleFilter, error) {
+	params := r.URL.Query()
+	filter := lifecycleFilter{
+		fingerprint: params.Get("fingerprint"),
+		operarius:   params.Get("operarius"),
+		state:       params.Get("state"),
+		limit:       defaultAlertsPageSize,
+	}
+
+	for _, input := range append(params["match[]"], params["match"]...) {
+		matchers, err := alertstore.ParseMatchers(input)
+		if err != nil {
+			return lifecycleFilter{}, err
+		}
+		filter.matchers = append(filter.matchers, matchers...)
+	}
+
+	switch filter.state {
+	case "", LifecycleStateOpen, LifecycleStateResolved:
+	default:
+		return lifecycleFilter{}, fmt.Errorf("invalid state %q, expected %s or %s", filter.state, LifecycleStateOpen, LifecycleStateResolved)
+	}
+
+	if value := params.Get("remediated"); value != "" {
+		remediated, err := strconv.ParseBool(value)
+		if err != nil {
+			return lifecycleFilter{}, fmt.Errorf("invalid remediated %q, expected true or false", value)
+		}
+		filter.remediated = &remediated
+	}
+
+	for name, t := range map[string]*time.Time{"from": &filter.from, "to": &filter.to} {
+		if value := params.Get(name); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return lifecycleFilter{}, fmt.Errorf("invalid %s, expected an RFC 3339 time: %w", name, err)
+			}
+			*t = parsed
+		}
+	}
+
+	if value := params.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxAlertsPageSize {
+			return lifecycleFilter{}, fmt.Errorf("invalid limit %q, expected 1 to %d", value, maxAlertsPageSize)
+		}
+		filter.limit = limit
+	}
+	return filter, nil
+}
+
+// matches reports whether a lifecycle matches the filter
+func (f lifecycleFilter) matches(lifecycle alertstore.Lifecycle) bool {
+	switch {
+	case f.fingerprint != "" && lifecycle.Fingerprint != f.fingerprint,
+		f.operarius != "" && lifecycle.Operarius != f.operarius,
+		f.state == LifecycleStateOpen && lifecycle.ResolvedAt != nil,
+		f.state == LifecycleStateResolved && lifecycle.ResolvedAt == nil,
+		f.remediated != nil && lifecycle.Remediated != *f.remediated,
+		!f.from.IsZero() && lifecycle.FiringAt.Before(f.from),
+		!f.to.IsZero() && !lifecycle.FiringAt.Before(f.to):
+		return false
+	}
+	return true
+}
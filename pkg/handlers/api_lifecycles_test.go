@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/OpenFero/openfero/pkg/alertstore"
+	"github.com/OpenFero/openfero/pkg/alertstore/memory"
+)
+
+func TestLifecyclesAPIHandler(t *testing.T) {
+	store := memory.NewMemoryStore(10)
+	disk := alertstore.Alert{Labels: map[string]string{"alertname": "Disk"}}
+	cpu := alertstore.Alert{Labels: map[string]string{"alertname": "CPU"}}
+	jobInfo := &alertstore.JobInfo{OperariusName: "disk-operarius", Namespace: "openfero", JobName: "disk-1", LastExecutionStatus: "Successful"}
+	require.NoError(t, store.SaveAlert(disk, "firing"))
+	require.NoError(t, store.SaveAlertWithJobInfo(disk, "firing", jobInfo))
+	require.NoError(t, store.SaveAlert(cpu, "firing"))
+	require.NoError(t, store.SaveAlert(disk, "resolved"))
+	server := &Server{AlertStore: store}
+
+	get := func(query string) []alertstore.Lifecycle {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		server.LifecyclesAPIHandler(rec, httptest.NewRequest(http.MethodGet, "/api/lifecycles?"+query, nil))
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var lifecycles []alertstore.Lifecycle
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&lifecycles))
+		return lifecycles
+	}
+
+	lifecycles := get("")
+	require.Len(t, lifecycles, 2)
+	assert.Equal(t, "CPU", lifecycles[0].Labels["alertname"], "most recently firing first")
+
+	lifecycles = get("state=resolved")
+	require.Len(t, lifecycles, 1)
+	assert.Equal(t, "disk-operarius", lifecycles[0].Operarius)
+	assert.Len(t, lifecycles[0].EntryIDs, 3)
+	assert.NotNil(t, lifecycles[0].TimeToRemediationStartSeconds)
+	assert.NotNil(t, lifecycles[0].TimeToResolveSeconds)
+	assert.True(t, lifecycles[0].Remediated)
+
+	assert.Len(t, get("operarius=disk-operarius&remediated=true"), 1)
+	assert.Empty(t, get("operarius=other"))
+	assert.Len(t, get("match[]=alertname%3D%22CPU%22&state=open"), 1)
+	assert.Len(t, get("limit=1"), 1)
+
+	for _, invalid := range []string{"state=maybe", "remediated=maybe", "from=yesterday", "limit=0", "match[]=invalid"} {
+		rec := httptest.NewRecorder()
+		server.LifecyclesAPIHandler(rec, httptest.NewRequest(http.MethodGet, "/api/lifecycles?"+invalid, nil))
+		assert.Equal(t, http.StatusBadRequest, rec.Code, invalid)
+	}
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	log "github.com/OpenFero/openfero/pkg/logging"
+	"github.com/OpenFero/openfero/pkg/models"
+	"github.com/OpenFero/openfero/pkg/services"
+)
+
+// SimulateAPIHandler handles POST requests to /api/simulate
+// @Summary Simulate how an alert would be handled
+// @Description Evaluates an Alertmanager webhook message against all Operarii without creating Jobs.
+// @Description Returns every Operarius with the reason it did or didn't match, the deduplication verdict and the rendered Job YAML.
+// @Tags jobs
+// @Accept json
+// @Produce json
+// @Param message body models.HookMessage true "Alertmanager webhook message"
+// @Success 200 {object} services.SimulationResult
+// @Failure 400 {string} string "invalid request body"
+// @Router /api/simulate [post]
+func (s *Server) SimulateAPIHandler(w http.ResponseWriter, r *http.Request) {
+	var hookMessage models.HookMessage
+	if err := json.NewDecoder(r.Body).Decode(&hookMessage); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if !services.CheckAlertStatus(hookMessage.Status) {
+		http.Error(w, "status must be firing or resolved", http.StatusBadRequest)
+		return
+	}
+
+	if !s.requireOperariusService(w) {
+		return
+	}
+
+	result, err := s.OperariusService.Simulate(r.Context(), hookMessage)
+	if err != nil {
+		log.Error("Failed to simulate alert", "error", err)
+		http.Error(w, "failed to simulate alert", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set(ContentTypeHeader, ApplicationJSONVal)
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		log.Error("Error encoding simulation result", "error", err)
+	}
+}
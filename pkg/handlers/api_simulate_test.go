@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/OpenFero/openfero/pkg/services"
+)
+
+func TestSimulateAPIHandler(t *testing.T) {
+	server, kubeClient, _ := newJobActionsTestServer(t)
+
+	t.Run("invalid body", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		server.SimulateAPIHandler(rec, httptest.NewRequest(http.MethodPost, "/api/simulate", strings.NewReader("{")))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("matching alert", func(t *testing.T) {
+		body := `{"status":"firing","groupKey":"g","alerts":[{"labels":{"alertname":"TestAlert"}}]}`
+		rec := httptest.NewRecorder()
+		server.SimulateAPIHandler(rec, httptest.NewRequest(http.MethodPost, "/api/simulate", strings.NewReader(body)))
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		var result services.SimulationResult
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&result))
+		assert.Equal(t, "dedup-operarius", result.Selected)
+		require.Len(t, result.Candidates, 1)
+		assert.Contains(t, result.Candidates[0].JobYAML, "kind: Job")
+
+		jobs, err := kubeClient.BatchV1().Jobs("openfero").List(context.Background(), metav1.ListOptions{})
+		require.NoError(t, err)
+		assert.Empty(t, jobs.Items)
+
+		alerts, err := server.AlertStore.GetAlerts("", 10)
+		require.NoError(t, err)
+		assert.Empty(t, alerts, "simulation must not store alerts")
+	})
+}
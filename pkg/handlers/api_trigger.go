@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"maps"
+	"net/http"
+
+	operariusv1alpha1 "github.com/OpenFero/openfero/api/v1alpha1"
+	"github.com/OpenFero/openfero/pkg/alertstore"
+	"github.com/OpenFero/openfero/pkg/cloudevents"
+	log "github.com/OpenFero/openfero/pkg/logging"
+	"github.com/OpenFero/openfero/pkg/metadata"
+	"github.com/OpenFero/openfero/pkg/models"
+	"github.com/OpenFero/openfero/pkg/services"
+)
+
+// TriggerRequest describes the synthetic alert a manual trigger runs an Operarius against
+type TriggerRequest struct {
+	// Labels of the synthetic alert; alertname defaults to the Operarius' alert selector
+	Labels map[string]string `json:"labels"`
+	// Annotations of the synthetic alert
+	Annotations map[string]string `json:"annotations"`
+	// Status of the synthetic alert, defaults to the Operarius' alert selector
+	Status string `json:"status,omitempty" enum:"firing,resolved" example:"firing"`
+	// Group key used for deduplication, defaults to a unique key per trigger
+	GroupKey string `json:"groupKey,omitempty"`
+}
+
+// OperariusTriggerAPIHandler handles POST requests to /api/operarii/{name}/trigger
+// @Summary Manually trigger an Operarius
+// @Description Runs an Operarius against a synthetic alert, e.g. for game days or runbook testing.
+// @Description The created Job and the stored alert are labeled openfero.io/trigger=manual.
+// @Tags jobs
+// @Accept json
+// @Produce json
+// @Param name path string true "Name of the Operarius"
+// @Param request body TriggerRequest false "Synthetic alert"
+// @Success 201 {object} JobActionResult
+// @Failure 400 {string} string "invalid request body"
+// @Failure 404 {string} string "operarius not found"
+// @Failure 409 {string} string "operarius is disabled or the job was deduplicated"
+// @Router /api/operarii/{name}/trigger [post]
+func (s *Server) OperariusTriggerAPIHandler(w http.ResponseWriter, r *http.Request) {
+	result := s.newJobActionResult(r, JobActionTrigger)
+	result.Operarius = r.PathValue("name")
+
+	var request TriggerRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if request.Status != "" && !services.CheckAlertStatus(request.Status) {
+		http.Error(w, "status must be firing or resolved", http.StatusBadRequest)
+		return
+	}
+
+	if !s.requireOperariusService(w) {
+		return
+	}
+
+	operarius, err := s.findOperarius(r, result.Operarius, models.HookMessage{})
+	if err != nil {
+		s.jobActionFailed(w, result, err)
+		return
+	}
+	result.Namespace = operarius.Namespace
+	if operarius.Spec.Enabled != nil && !*operarius.Spec.Enabled {
+		s.jobActionFailed(w, result, errOperariusDisabled)
+		return
+	}
+
+	hookMessage := manualHookMessage(request, operarius.Spec.AlertSelector.AlertName, operarius.Spec.AlertSelector.Status)
+	log.Info("Manually triggering Operarius",
+		"operarius", operarius.Name,
+		"alertname", hookMessage.CommonLabels["alertname"],
+		"groupKey", hookMessage.GroupKey)
+
+	ctx := r.Context()
+	job, err := s.OperariusService.CreateJobFromOperariusWithOptions(ctx, operarius, hookMessage, services.JobOptions{
+		Trigger: services.TriggerManual,
+	})
+	if err != nil {
+		if !errors.Is(err, services.ErrJobDeduplicated) {
+			metadata.JobsFailedTotal.Inc()
+		}
+		s.jobActionFailed(w, result, err)
+		return
+	}
+	metadata.JobsCreatedTotal.Inc()
+	s.Lifecycles.JobCreated(job.Namespace, job.Name)
+	result.NewJobName = job.Name
+
+	if err := s.OperariusService.UpdateOperariusStatus(ctx, operarius, job.Name); err != nil {
+		log.Warn("Failed to update Operarius status",
+			"error", err,
+			"operarius", operarius.Name)
+	}
+
+	s.saveTriggeredAlert(hookMessage, services.TriggerManual, newJobInfo(job, operarius))
+
+	s.jobActionSucceeded(w, http.StatusCreated, result)
+}
+
+// RunScheduledOperarius runs an Operarius whose cron trigger is due. It goes
+// through the same deduplication, status updates and alert store entries as
+// incoming alerts; the Job and the stored alert are labeled openfero.io/trigger=cron.
+func (s *Server) RunScheduledOperarius(ctx context.Context, operarius *operariusv1alpha1.Operarius, hookMessage models.HookMessage) {
+	s.Events.Emit(cloudevents.TypeAlertReceived, hookMessage.GroupKey, hookMessage)
+
+	outcome, jobInfo := s.runOperarius(ctx, operarius, hookMessage, services.JobOptions{
+		Trigger: services.TriggerCron,
+	})
+	if outcome.Action == WebhookActionDuplicate {
+		return
+	}
+	s.saveTriggeredAlert(hookMessage, services.TriggerCron, jobInfo)
+
+	s.emitOutcome(hookMessage, outcome)
+}
+
+// manualHookMessage builds the hook message for a manual trigger, filling in
+// what the request leaves out from the Operarius' alert selector
+func manualHookMessage(request TriggerRequest, alertName, status string) models.HookMessage {
+	labels := maps.Clone(request.Labels)
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	if _, ok := labels["alertname"]; !ok && alertName != "" {
+		labels["alertname"] = alertName
+	}
+
+	annotations := maps.Clone(request.Annotations)
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+
+	if request.Status != "" {
+		status = request.Status
+	}
+
+	groupKey := request.GroupKey
+	if groupKey == "" {
+		groupKey = "manual:" + alertstore.NewEntryID()
+	}
+
+	return models.HookMessage{
+		Version:           "4",
+		GroupKey:          groupKey,
+		Status:            status,
+		Receiver:          "openfero-manual-trigger",
+		CommonLabels:      labels,
+		CommonAnnotations: annotations,
+		Alerts: []models.Alert{{
+			Labels:      labels,
+			Annotations: annotations,
+		}},
+	}
+}
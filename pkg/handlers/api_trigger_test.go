@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	operariusv1alpha1 "github.com/OpenFero/openfero/api/v1alpha1"
+	"github.com/OpenFero/openfero/pkg/services"
+)
+
+func TestOperariusTriggerAPIHandler(t *testing.T) {
+	server, kubeClient, operariusClient := newJobActionsTestServer(t)
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/operarii/{name}/trigger", server.OperariusTriggerAPIHandler)
+
+	trigger := func(name, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/operarii/"+name+"/trigger", strings.NewReader(body)))
+		return rec
+	}
+
+	t.Run("synthetic alert", func(t *testing.T) {
+		rec := trigger("dedup-operarius", `{"labels":{"instance":"game-day"},"annotations":{"summary":"drill"},"groupKey":"drill-1"}`)
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+		var result JobActionResult
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&result))
+		assert.Equal(t, JobActionTrigger, result.Action)
+		assert.NotEmpty(t, result.NewJobName)
+
+		job, err := kubeClient.BatchV1().Jobs("openfero").Get(context.Background(), result.NewJobName, metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, services.TriggerManual, job.Labels[services.TriggerLabel])
+		assert.Equal(t, "TestAlert", job.Labels["openfero.io/alert"], "alertname should default to the alert selector")
+		assert.Contains(t, job.Spec.Template.Spec.Containers[0].Env, corev1.EnvVar{Name: "OPENFERO_INSTANCE", Value: "game-day"})
+
+		operarii, _ := operariusClient.List()
+		assert.Equal(t, result.NewJobName, operarii[0].Status.LastExecutedJobName)
+
+		alerts, err := server.AlertStore.GetAlerts("", 10)
+		require.NoError(t, err)
+		require.Len(t, alerts, 1)
+		assert.Equal(t, services.TriggerManual, alerts[0].Alert.Labels[services.TriggerLabel])
+		assert.Equal(t, "firing", alerts[0].Status)
+		assert.Equal(t, result.NewJobName, alerts[0].JobInfo.JobName)
+	})
+
+	t.Run("same group key is deduplicated", func(t *testing.T) {
+		rec := trigger("dedup-operarius", `{"groupKey":"drill-1"}`)
+		assert.Equal(t, http.StatusConflict, rec.Code)
+	})
+
+	t.Run("invalid status", func(t *testing.T) {
+		rec := trigger("dedup-operarius", `{"status":"pending"}`)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("unknown operarius", func(t *testing.T) {
+		rec := trigger("missing", "")
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("disabled operarius", func(t *testing.T) {
+		disabled := false
+		operariusClient.mu.Lock()
+		operariusClient.operarii[0].Spec.Enabled = &disabled
+		operariusClient.mu.Unlock()
+
+		rec := trigger("dedup-operarius", "")
+		assert.Equal(t, http.StatusConflict, rec.Code)
+	})
+}
+
+func TestRunScheduledOperarius(t *testing.T) {
+	server, kubeClient, operariusClient := newJobActionsTestServer(t)
+	operarii, _ := operariusClient.List()
+	operarius := operarii[0].DeepCopy()
+	operarius.Spec.CronTrigger = &operariusv1alpha1.CronTrigger{Schedule: "0 * * * *"}
+	hookMessage := services.CronHookMessage(operarius, time.Date(2024, 5, 1, 11, 0, 0, 0, time.UTC))
+
+	server.RunScheduledOperarius(context.Background(), operarius, hookMessage)
+
+	jobs, err := kubeClient.BatchV1().Jobs("openfero").List(context.Background(), metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, jobs.Items, 1)
+	job := jobs.Items[0]
+	assert.Equal(t, services.TriggerCron, job.Labels[services.TriggerLabel])
+
+	operarii, _ = operariusClient.List()
+	assert.Equal(t, job.Name, operarii[0].Status.LastExecutedJobName)
+
+	alerts, err := server.AlertStore.GetAlerts("", 10)
+	require.NoError(t, err)
+	require.Len(t, alerts, 1)
+	assert.Equal(t, services.TriggerCron, alerts[0].Alert.Labels[services.TriggerLabel])
+	assert.Equal(t, job.Name, alerts[0].JobInfo.JobName)
+
+	// The same due time, e.g. run by another replica, was handled already
+	server.RunScheduledOperarius(context.Background(), operarius, hookMessage)
+	alerts, err = server.AlertStore.GetAlerts("", 10)
+	require.NoError(t, err)
+	require.Len(t, alerts, 1)
+
+	// The next due time is deduplicated like an alert
+	next := services.CronHookMessage(operarius, time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	server.RunScheduledOperarius(context.Background(), operarius, next)
+	jobs, err = kubeClient.BatchV1().Jobs("openfero").List(context.Background(), metav1.ListOptions{})
+	require.NoError(t, err)
+	assert.Len(t, jobs.Items, 1)
+	alerts, err = server.AlertStore.GetAlerts("", 10)
+	require.NoError(t, err)
+	require.Len(t, alerts, 2)
+	assert.Equal(t, "Skipped: Deduplication", alerts[0].JobInfo.LastExecutionStatus)
+}
@@ -1,10 +1,14 @@
 package handlers
 
 import (
-	"crypto/subtle"
+	"context"
+	"errors"
 	"net/http"
+	"slices"
 	"strings"
+	"time"
 
+	"github.com/OpenFero/openfero/pkg/ingest"
 	log "github.com/OpenFero/openfero/pkg/logging"
 )
 
@@ -15,6 +19,7 @@ const (
 	AuthMethodNone   AuthMethod = "none"
 	AuthMethodBasic  AuthMethod = "basic"
 	AuthMethodBearer AuthMethod = "bearer"
+	AuthMethodHMAC   AuthMethod = "hmac"
 )
 
 // AuthConfig holds authentication configuration
@@ -23,10 +28,58 @@ type AuthConfig struct {
 	BasicUser   string
 	BasicPass   string
 	BearerToken string
+
+	// HMACSecret is the shared secret requests are signed with (HMAC-SHA256)
+	HMACSecret string
+	// HMACSignatureHeader, HMACTimestampHeader and HMACNonceHeader name the
+	// headers carrying the signature, the Unix time and a unique nonce
+	HMACSignatureHeader string
+	HMACTimestampHeader string
+	HMACNonceHeader     string
+	// HMACTolerance is how far the timestamp may be off from the current time
+	HMACTolerance time.Duration
+	// HMACNonces remembers the nonces of signed requests. It can be shared
+	// between replicas, a log of its own is used if unset.
+	HMACNonces *ingest.NonceLog
+
+	// Credentials are named basic auth, bearer or HMAC credentials accepted in
+	// addition to the ones above, reloaded when they change
+	Credentials *CredentialStore
+
+	// ClientCertRequired requires webhooks to present a client certificate
+	// (mTLS), in addition to the method above
+	ClientCertRequired bool
+	// ClientCertNames restricts the accepted client certificates by common or DNS name
+	ClientCertNames []string
+}
+
+// principalContextKey is the context key under which AuthMiddleware stores the authenticated principal
+type principalContextKey struct{}
+
+// AnonymousPrincipal is the principal of requests that were not authenticated
+const AnonymousPrincipal = "anonymous"
+
+// PrincipalFromContext returns who made a request, as determined by AuthMiddleware
+func PrincipalFromContext(ctx context.Context) string {
+	if principal, ok := ctx.Value(principalContextKey{}).(string); ok && principal != "" {
+		return principal
+	}
+	return AnonymousPrincipal
+}
+
+// withPrincipal attaches the authenticated principal to a request
+func withPrincipal(r *http.Request, principal string) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), principalContextKey{}, principal))
 }
 
 // AuthMiddleware creates a middleware function that handles authentication
 func AuthMiddleware(config AuthConfig) func(http.HandlerFunc) http.HandlerFunc {
+	// Shared by all routes, so a nonce can't be replayed against another route
+	var verifier *hmacVerifier
+	if config.Method == AuthMethodHMAC {
+		verifier = newHMACVerifier(config)
+	}
+
 	return func(next http.HandlerFunc) http.HandlerFunc {
 		return func(w http.ResponseWriter, r *http.Request) {
 			// Skip authentication if method is "none"
@@ -37,13 +90,24 @@ func AuthMiddleware(config AuthConfig) func(http.HandlerFunc) http.HandlerFunc {
 
 			// Authenticate based on configured method
 			authenticated := false
-			var authMethod string
+			var authMethod, credential string
 
 			switch config.Method {
 			case AuthMethodBasic:
-				authenticated, authMethod = authenticateBasic(r, config.BasicUser, config.BasicPass)
+				credential, authenticated = authenticateBasic(r, config.credentials())
+				authMethod = "basic"
 			case AuthMethodBearer:
-				authenticated, authMethod = authenticateBearer(r, config.BearerToken)
+				credential, authenticated = authenticateBearer(r, config.credentials())
+				authMethod = "bearer"
+			case AuthMethodHMAC:
+				var err error
+				credential, err = authenticateHMAC(r, verifier, config.credentials())
+				var tooLarge *http.MaxBytesError
+				if errors.As(err, &tooLarge) {
+					rejectBody(w, err)
+					return
+				}
+				authenticated, authMethod = err == nil, "hmac"
 
 			default:
 				log.Warn("Unknown authentication method", "method", string(config.Method))
@@ -69,54 +133,72 @@ func AuthMiddleware(config AuthConfig) func(http.HandlerFunc) http.HandlerFunc {
 
 			log.Debug("Authentication successful",
 				"method", authMethod,
+				"credential", credential,
 				"remoteAddr", r.RemoteAddr)
 
-			next(w, r)
+			r = r.WithContext(withCredential(r.Context(), credential))
+			next(w, withPrincipal(r, principalFor(r, config.Method, credential)))
 		}
 	}
 }
 
-// authenticateBasic performs HTTP Basic Authentication
-func authenticateBasic(r *http.Request, expectedUser, expectedPass string) (bool, string) {
-	if expectedUser == "" || expectedPass == "" {
+// authenticateBasic performs HTTP Basic Authentication and returns the label of the matching credential
+func authenticateBasic(r *http.Request, credentials []Credential) (string, bool) {
+	if !slices.ContainsFunc(credentials, func(c Credential) bool { return c.Username != "" }) {
 		log.Error("Basic auth credentials not configured")
-		return false, "basic"
+		return "", false
 	}
 
 	user, pass, ok := r.BasicAuth()
 	if !ok {
-		return false, "basic"
+		return "", false
 	}
 
 	// Use constant-time comparison to prevent timing attacks
-	userMatch := subtle.ConstantTimeCompare([]byte(user), []byte(expectedUser)) == 1
-	passMatch := subtle.ConstantTimeCompare([]byte(pass), []byte(expectedPass)) == 1
-
-	return userMatch && passMatch, "basic"
+	return matchCredential(credentials, func(c Credential) bool {
+		userMatch := secretEqual(user, c.Username)
+		passMatch := secretEqual(pass, c.Password)
+		return c.Username != "" && userMatch && passMatch
+	})
 }
 
-// authenticateBearer performs Bearer Token Authentication
-func authenticateBearer(r *http.Request, expectedToken string) (bool, string) {
-	if expectedToken == "" {
+// authenticateBearer performs Bearer Token Authentication and returns the label of the matching credential
+func authenticateBearer(r *http.Request, credentials []Credential) (string, bool) {
+	if !slices.ContainsFunc(credentials, func(c Credential) bool { return c.Token != "" }) {
 		log.Error("Bearer token not configured")
-		return false, "bearer"
+		return "", false
 	}
 
 	authHeader := r.Header.Get("Authorization")
 	if authHeader == "" {
-		return false, "bearer"
+		return "", false
 	}
 
 	// Check if header starts with "Bearer "
 	const bearerPrefix = "Bearer "
 	if !strings.HasPrefix(authHeader, bearerPrefix) {
-		return false, "bearer"
+		return "", false
 	}
 
 	token := strings.TrimPrefix(authHeader, bearerPrefix)
 
 	// Use constant-time comparison to prevent timing attacks
-	tokenMatch := subtle.ConstantTimeCompare([]byte(token), []byte(expectedToken)) == 1
+	return matchCredential(credentials, func(c Credential) bool {
+		return c.Token != "" && secretEqual(token, c.Token)
+	})
+}
 
-	return tokenMatch, "bearer"
+// principalFor names the principal of an authenticated request. Bearer tokens
+// are shared secrets, so they only identify the method and the label of the
+// credential, never the token itself.
+func principalFor(r *http.Request, method AuthMethod, credential string) string {
+	if method == AuthMethodBasic {
+		if user, _, ok := r.BasicAuth(); ok {
+			return "basic:" + user
+		}
+	}
+	if credential != "" && credential != DefaultCredentialLabel {
+		return string(method) + ":" + credential
+	}
+	return string(method)
 }
@@ -0,0 +1,266 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/OpenFero/openfero/pkg/logging"
+	"github.com/OpenFero/openfero/pkg/metadata"
+	"sigs.k8s.io/yaml"
+)
+
+// DefaultCredentialLabel is the label of the credential configured by the
+// authBasicUser/authBasicPass, authBearerToken or authHMACSecret(File) flags
+const DefaultCredentialLabel = "default"
+
+// DefaultCredentialsReloadInterval is how often a credentials file is checked for changes
+const DefaultCredentialsReloadInterval = 10 * time.Second
+
+// Credential is a named webhook credential. It holds either a username and a
+// password for basic auth, a token for bearer auth, or a secret requests are
+// signed with for HMAC auth. Secret values can be read from files, e.g. keys
+// of a mounted Secret.
+type Credential struct {
+	// Label identifies the credential, e.g. the Alertmanager it belongs to.
+	// It is recorded on the alerts received with it and on metrics.
+	Label        string `json:"label"`
+	Username     string `json:"username,omitempty"`
+	Password     string `json:"password,omitempty"`
+	PasswordFile string `json:"passwordFile,omitempty"`
+	Token        string `json:"token,omitempty"`
+	TokenFile    string `json:"tokenFile,omitempty"`
+	// HMACSecret is the shared secret of HMAC-SHA256 signatures
+	HMACSecret     string `json:"hmacSecret,omitempty"`
+	HMACSecretFile string `json:"hmacSecretFile,omitempty"`
+}
+
+// credentialsFile is the format of a credentials file
+type credentialsFile struct {
+	Credentials []Credential `json:"credentials"`
+}
+
+// CredentialStore holds the credentials of a credentials file and reloads
+// them when the file, or a file it references, changes. Kubernetes updates
+// mounted Secrets in place, so rotated credentials are picked up without a
+// restart.
+type CredentialStore struct {
+	path string
+
+	mu          sync.RWMutex
+	credentials []Credential
+	digest      [sha256.Size]byte
+}
+
+// LoadCredentials reads a credentials file like
+//
+//	credentials:
+//	  - label: alertmanager-eu
+//	    username: alertmanager
+//	    passwordFile: /etc/openfero/credentials/eu-password
+//	  - label: alertmanager-us
+//	    tokenFile: /etc/openfero/credentials/us-token
+//	  - label: grafana
+//	    hmacSecretFile: /etc/openfero/credentials/grafana-secret
+func LoadCredentials(path string) (*CredentialStore, error) {
+	store := &CredentialStore{path: path}
+	if _, err := store.Reload(); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// Reload reads the credentials file again and reports whether the
+// credentials changed. If the file is invalid, the previous credentials are
+// kept.
+func (c *CredentialStore) Reload() (bool, error) {
+	credentials, digest, err := readCredentials(c.path)
+	if err != nil {
+		return false, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if digest == c.digest {
+		return false, nil
+	}
+	c.credentials = credentials
+	c.digest = digest
+	return true, nil
+}
+
+// Watch reloads the credentials every interval until the context is canceled
+func (c *CredentialStore) Watch(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultCredentialsReloadInterval
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			changed, err := c.Reload()
+			if err != nil {
+				metadata.AuthCredentialReloadsTotal.WithLabelValues("error").Inc()
+				log.Error("Failed to reload credentials, keeping the previous ones", "path", c.path, "error", err)
+				continue
+			}
+			if changed {
+				metadata.AuthCredentialReloadsTotal.WithLabelValues("success").Inc()
+				log.Info("Reloaded credentials", "path", c.path, "count", len(c.List()))
+			}
+		}
+	}()
+}
+
+// List returns the current credentials
+func (c *CredentialStore) List() []Credential {
+	if c == nil {
+		return nil
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.credentials
+}
+
+// readCredentials reads and validates a credentials file and the files it
+// references. The digest covers all of them, so a rotated secret is noticed
+// even if the credentials file itself is unchanged.
+func readCredentials(path string) ([]Credential, [sha256.Size]byte, error) {
+	var digest [sha256.Size]byte
+	data, err := os.ReadFile(path) // #nosec G304 -- path is configured by the operator
+	if err != nil {
+		return nil, digest, fmt.Errorf("failed to read credentials file: %w", err)
+	}
+	var file credentialsFile
+	if err := yaml.UnmarshalStrict(data, &file); err != nil {
+		return nil, digest, fmt.Errorf("invalid credentials file %s: %w", path, err)
+	}
+
+	hash := sha256.New()
+	hash.Write(data)
+	labels := make(map[string]bool, len(file.Credentials))
+	for i := range file.Credentials {
+		credential := &file.Credentials[i]
+		if err := credential.resolve(); err != nil {
+			return nil, digest, err
+		}
+		if labels[credential.Label] {
+			return nil, digest, fmt.Errorf("duplicate credential label %q", credential.Label)
+		}
+		labels[credential.Label] = true
+		hash.Write([]byte("\x00" + credential.Password + "\x00" + credential.Token + "\x00" + credential.HMACSecret))
+	}
+	copy(digest[:], hash.Sum(nil))
+	return file.Credentials, digest, nil
+}
+
+// resolve reads the secret files of a credential and validates it
+func (c *Credential) resolve() error {
+	if c.Label == "" {
+		return errors.New("credential without a label")
+	}
+	if c.PasswordFile != "" {
+		password, err := os.ReadFile(c.PasswordFile) // #nosec G304 -- path is configured by the operator
+		if err != nil {
+			return fmt.Errorf("failed to read password of credential %s: %w", c.Label, err)
+		}
+		c.Password = strings.TrimSpace(string(password))
+	}
+	if c.TokenFile != "" {
+		token, err := os.ReadFile(c.TokenFile) // #nosec G304 -- path is configured by the operator
+		if err != nil {
+			return fmt.Errorf("failed to read token of credential %s: %w", c.Label, err)
+		}
+		c.Token = strings.TrimSpace(string(token))
+	}
+	if c.HMACSecretFile != "" {
+		secret, err := os.ReadFile(c.HMACSecretFile) // #nosec G304 -- path is configured by the operator
+		if err != nil {
+			return fmt.Errorf("failed to read HMAC secret of credential %s: %w", c.Label, err)
+		}
+		c.HMACSecret = strings.TrimSpace(string(secret))
+	}
+
+	basic := c.Username != "" || c.Password != ""
+	kinds := 0
+	for _, set := range []bool{basic, c.Token != "", c.HMACSecret != ""} {
+		if set {
+			kinds++
+		}
+	}
+	switch {
+	case kinds > 1:
+		return fmt.Errorf("credential %s has more than one of a username, a token and an HMAC secret", c.Label)
+	case basic && (c.Username == "" || c.Password == ""):
+		return fmt.Errorf("credential %s requires both a username and a password", c.Label)
+	case kinds == 0:
+		return fmt.Errorf("credential %s has neither a username, a token nor an HMAC secret", c.Label)
+	}
+	return nil
+}
+
+// credentials returns the credential configured by flags, if any, followed
+// by the ones of the credentials file
+func (config AuthConfig) credentials() []Credential {
+	var credentials []Credential
+	switch config.Method {
+	case AuthMethodBasic:
+		if config.BasicUser != "" && config.BasicPass != "" {
+			credentials = append(credentials, Credential{Label: DefaultCredentialLabel, Username: config.BasicUser, Password: config.BasicPass})
+		}
+	case AuthMethodBearer:
+		if config.BearerToken != "" {
+			credentials = append(credentials, Credential{Label: DefaultCredentialLabel, Token: config.BearerToken})
+		}
+	case AuthMethodHMAC:
+		if config.HMACSecret != "" {
+			credentials = append(credentials, Credential{Label: DefaultCredentialLabel, HMACSecret: config.HMACSecret})
+		}
+	}
+	return append(credentials, config.Credentials.List()...)
+}
+
+// credentialContextKey is the context key under which AuthMiddleware stores the label of the matching credential
+type credentialContextKey struct{}
+
+// CredentialFromContext returns the label of the credential a request was
+// authenticated with, or an empty string if it was not authenticated
+func CredentialFromContext(ctx context.Context) string {
+	credential, _ := ctx.Value(credentialContextKey{}).(string)
+	return credential
+}
+
+// withCredential attaches the label of the matching credential to a request
+func withCredential(ctx context.Context, credential string) context.Context {
+	return context.WithValue(ctx, credentialContextKey{}, credential)
+}
+
+// matchCredential compares all credentials in constant time and returns the
+// label of the first that matches
+func matchCredential(credentials []Credential, matches func(Credential) bool) (string, bool) {
+	label := ""
+	for _, credential := range credentials {
+		// Don't stop at the first match, so the time taken doesn't tell which credential matched
+		if matches(credential) && label == "" {
+			label = credential.Label
+		}
+	}
+	return label, label != ""
+}
+
+// secretEqual compares a secret in constant time
+func secretEqual(given, expected string) bool {
+	return subtle.ConstantTimeCompare([]byte(given), []byte(expected)) == 1
+}
@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/OpenFero/openfero/pkg/ingest"
+	log "github.com/OpenFero/openfero/pkg/logging"
+	"github.com/OpenFero/openfero/pkg/metadata"
+)
+
+// Defaults of the HMAC authentication settings
+const (
+	DefaultHMACSignatureHeader = "X-OpenFero-Signature"
+	DefaultHMACTimestampHeader = "X-OpenFero-Timestamp"
+	DefaultHMACNonceHeader     = "X-OpenFero-Nonce"
+	DefaultHMACTolerance       = 5 * time.Minute
+)
+
+// maxSignedBodyBytes bounds the body read to verify a signature. Webhook
+// bodies are limited to --webhookMaxBodyBytes before they are authenticated,
+// this applies if that limit is disabled or larger.
+const maxSignedBodyBytes = 16 << 20
+
+// Reasons for rejecting a signed request
+var (
+	errInvalidSignature = errors.New("invalid signature")
+	errStaleTimestamp   = errors.New("timestamp outside of the tolerance")
+	errReplayedNonce    = errors.New("nonce was already used")
+)
+
+// hmacVerifier verifies signed requests and remembers the nonces it accepted
+type hmacVerifier struct {
+	signatureHeader string
+	timestampHeader string
+	nonceHeader     string
+	tolerance       time.Duration
+	now             func() time.Time
+	nonces          *ingest.NonceLog
+}
+
+// newHMACVerifier creates a verifier, applying the defaults for unset headers and tolerance
+func newHMACVerifier(config AuthConfig) *hmacVerifier {
+	v := &hmacVerifier{
+		signatureHeader: config.HMACSignatureHeader,
+		timestampHeader: config.HMACTimestampHeader,
+		nonceHeader:     config.HMACNonceHeader,
+		tolerance:       config.HMACTolerance,
+		now:             time.Now,
+		nonces:          config.HMACNonces,
+	}
+	if v.nonces == nil {
+		v.nonces = ingest.NewNonceLog()
+	}
+	if v.signatureHeader == "" {
+		v.signatureHeader = DefaultHMACSignatureHeader
+	}
+	if v.timestampHeader == "" {
+		v.timestampHeader = DefaultHMACTimestampHeader
+	}
+	if v.nonceHeader == "" {
+		v.nonceHeader = DefaultHMACNonceHeader
+	}
+	if v.tolerance <= 0 {
+		v.tolerance = DefaultHMACTolerance
+	}
+	return v
+}
+
+// authenticateHMAC performs HMAC signature authentication and returns the
+// label of the credential the request was signed with. A body over the
+// webhook body limit fails with the *http.MaxBytesError.
+func authenticateHMAC(r *http.Request, verifier *hmacVerifier, credentials []Credential) (string, error) {
+	if !slices.ContainsFunc(credentials, func(c Credential) bool { return c.HMACSecret != "" }) {
+		log.Error("HMAC secret not configured")
+		return "", errInvalidSignature
+	}
+
+	credential, err := verifier.verify(r, credentials)
+	if err != nil {
+		log.Debug("HMAC verification failed", "error", err)
+		var tooLarge *http.MaxBytesError
+		switch {
+		case errors.As(err, &tooLarge):
+			// Counted when the request is rejected
+		case errors.Is(err, errStaleTimestamp):
+			metadata.WebhookRejectedTotal.WithLabelValues("stale_timestamp").Inc()
+		case errors.Is(err, errReplayedNonce):
+			metadata.WebhookRejectedTotal.WithLabelValues("replayed_nonce").Inc()
+		default:
+			metadata.WebhookRejectedTotal.WithLabelValues("invalid_signature").Inc()
+		}
+		return "", err
+	}
+	return credential, nil
+}
+
+// verify checks the signature of a request over its timestamp, nonce and raw
+// body, joined by dots, and returns the label of the credential whose secret
+// it was signed with. Signing the timestamp and nonce as well keeps them
+// from being replaced in a replayed request. The body is restored for the
+// next handler.
+func (v *hmacVerifier) verify(r *http.Request, credentials []Credential) (string, error) {
+	signature, err := decodeSignature(r.Header.Get(v.signatureHeader))
+	if err != nil {
+		return "", err
+	}
+	timestamp := r.Header.Get(v.timestampHeader)
+	nonce := r.Header.Get(v.nonceHeader)
+	if timestamp == "" || nonce == "" {
+		return "", errors.Join(errInvalidSignature, errors.New("missing timestamp or nonce"))
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxSignedBodyBytes+1))
+	if err != nil {
+		return "", errors.Join(errInvalidSignature, err)
+	}
+	if len(body) > maxSignedBodyBytes {
+		return "", errors.Join(errInvalidSignature, errors.New("body too large"))
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	credential, ok := matchCredential(credentials, func(c Credential) bool {
+		if c.HMACSecret == "" {
+			return false
+		}
+		mac := hmac.New(sha256.New, []byte(c.HMACSecret))
+		mac.Write([]byte(timestamp + "." + nonce + "."))
+		mac.Write(body)
+		return hmac.Equal(signature, mac.Sum(nil))
+	})
+	if !ok {
+		return "", errInvalidSignature
+	}
+
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return "", errors.Join(errStaleTimestamp, err)
+	}
+	now := v.now()
+	if age := now.Sub(time.Unix(seconds, 0)); age > v.tolerance || age < -v.tolerance {
+		return "", errStaleTimestamp
+	}
+
+	if err := v.useNonce(nonce, now); err != nil {
+		return "", err
+	}
+	return credential, nil
+}
+
+// useNonce records a nonce, failing if it was used before. Nonces are kept
+// for twice the tolerance, older requests are rejected by their timestamp.
+func (v *hmacVerifier) useNonce(nonce string, now time.Time) error {
+	if !v.nonces.Use(nonce, now.Add(2*v.tolerance), now) {
+		return errReplayedNonce
+	}
+	return nil
+}
+
+// decodeSignature decodes a hex or base64 signature, optionally prefixed with "sha256="
+func decodeSignature(value string) ([]byte, error) {
+	value = strings.TrimPrefix(strings.TrimSpace(value), "sha256=")
+	if value == "" {
+		return nil, errors.Join(errInvalidSignature, errors.New("missing signature"))
+	}
+	if signature, err := hex.DecodeString(value); err == nil {
+		return signature, nil
+	}
+	if signature, err := base64.StdEncoding.DecodeString(value); err == nil {
+		return signature, nil
+	}
+	return nil, errors.Join(errInvalidSignature, errors.New("signature is neither hex nor base64"))
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"crypto/x509"
+	"net/http"
+	"slices"
+
+	log "github.com/OpenFero/openfero/pkg/logging"
+	"github.com/OpenFero/openfero/pkg/metadata"
+)
+
+// ClientCertMiddleware creates a middleware that requires a client
+// certificate the TLS server verified against its client CAs. If names are
+// given, the common name or one of the DNS names of the certificate must be
+// among them. The common name is recorded as credential of the request,
+// unless the authentication method names one.
+func ClientCertMiddleware(names []string) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+				log.Warn("Client certificate required",
+					"remoteAddr", r.RemoteAddr,
+					"tls", r.TLS != nil)
+				metadata.WebhookRejectedTotal.WithLabelValues("client_certificate").Inc()
+				http.Error(w, "Client certificate required", http.StatusUnauthorized)
+				return
+			}
+
+			certificate := r.TLS.VerifiedChains[0][0]
+			if len(names) > 0 && !certificateNamed(certificate, names) {
+				log.Warn("Client certificate not allowed",
+					"commonName", certificate.Subject.CommonName,
+					"dnsNames", certificate.DNSNames,
+					"remoteAddr", r.RemoteAddr)
+				metadata.WebhookRejectedTotal.WithLabelValues("client_certificate").Inc()
+				http.Error(w, "Forbidden", http.StatusForbidden)
+				return
+			}
+
+			next(w, r.WithContext(withCredential(r.Context(), certificate.Subject.CommonName)))
+		}
+	}
+}
+
+// certificateNamed reports whether the common name or a DNS name of a certificate is one of names
+func certificateNamed(certificate *x509.Certificate, names []string) bool {
+	if slices.Contains(names, certificate.Subject.CommonName) {
+		return true
+	}
+	return slices.ContainsFunc(certificate.DNSNames, func(name string) bool { return slices.Contains(names, name) })
+}
@@ -0,0 +1,282 @@
+package handlers
+
+import (
+	"context"
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/go-jose/go-jose/v4"
+	"github.com/gorilla/websocket"
+
+	log "github.com/OpenFero/openfero/pkg/logging"
+	"github.com/OpenFero/openfero/pkg/metadata"
+)
+
+// Role is what an authenticated user may do with the API
+type Role string
+
+// Roles, each one includes the permissions of the ones before
+const (
+	// RoleNone is the role of users without any of the roles below
+	RoleNone Role = ""
+	// RoleViewer may read alerts, jobs and logs
+	RoleViewer Role = "viewer"
+	// RoleOperator may also cancel, retry, rerun and trigger jobs
+	RoleOperator Role = "operator"
+	// RoleAdmin may do everything
+	RoleAdmin Role = "admin"
+)
+
+// roleRanks orders the roles
+var roleRanks = map[Role]int{RoleNone: 0, RoleViewer: 1, RoleOperator: 2, RoleAdmin: 3}
+
+// Allows reports whether the role includes the permissions of required
+func (r Role) Allows(required Role) bool {
+	return roleRanks[r] >= roleRanks[required]
+}
+
+// Defaults of the OIDC settings
+const (
+	DefaultOIDCRolesClaim    = "roles"
+	DefaultOIDCUsernameClaim = "preferred_username"
+)
+
+// supportedSigningAlgs are the algorithms tokens may be signed with
+var supportedSigningAlgs = []string{
+	oidc.RS256, oidc.RS384, oidc.RS512,
+	oidc.ES256, oidc.ES384, oidc.ES512,
+	oidc.PS256, oidc.PS384, oidc.PS512,
+	oidc.EdDSA,
+}
+
+// OIDCConfig configures the validation of OIDC bearer tokens
+type OIDCConfig struct {
+	// IssuerURL must match the iss claim. Unless a JWKS is configured, the
+	// keys are discovered from the issuer.
+	IssuerURL string
+	// JWKSURL is fetched for the signing keys instead of using discovery
+	JWKSURL string
+	// JWKSFile is a local key set, e.g. for tests or air-gapped clusters
+	JWKSFile string
+	// Audience must be in the aud claim, if set
+	Audience string
+
+	// RolesClaim names the claim holding the roles or groups of a user. Nested
+	// claims are separated by dots, like realm_access.roles of Keycloak.
+	RolesClaim string
+	// ViewerRoles, OperatorRoles and AdminRoles are the values of the roles
+	// claim granting each role
+	ViewerRoles   []string
+	OperatorRoles []string
+	AdminRoles    []string
+	// UsernameClaim names the user in logs and audit records, falling back to sub
+	UsernameClaim string
+}
+
+// Identity is a user authenticated with an OIDC token
+type Identity struct {
+	Username string `json:"username"`
+	Role     Role   `json:"role"`
+}
+
+// OIDCAuthenticator validates OIDC bearer tokens and authorizes API requests by role
+type OIDCAuthenticator struct {
+	config   OIDCConfig
+	verifier *oidc.IDTokenVerifier
+}
+
+// NewOIDCAuthenticator creates an authenticator. With neither a JWKS URL nor
+// a JWKS file, the keys are discovered from the issuer, which must be
+// reachable.
+func NewOIDCAuthenticator(ctx context.Context, config OIDCConfig) (*OIDCAuthenticator, error) {
+	if config.IssuerURL == "" {
+		return nil, errors.New("OIDC requires an issuer URL")
+	}
+	if config.RolesClaim == "" {
+		config.RolesClaim = DefaultOIDCRolesClaim
+	}
+	if config.UsernameClaim == "" {
+		config.UsernameClaim = DefaultOIDCUsernameClaim
+	}
+
+	verifierConfig := &oidc.Config{
+		ClientID:             config.Audience,
+		SkipClientIDCheck:    config.Audience == "",
+		SupportedSigningAlgs: supportedSigningAlgs,
+	}
+
+	var verifier *oidc.IDTokenVerifier
+	switch {
+	case config.JWKSFile != "":
+		keySet, err := loadJWKS(config.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+		verifier = oidc.NewVerifier(config.IssuerURL, keySet, verifierConfig)
+	case config.JWKSURL != "":
+		verifier = oidc.NewVerifier(config.IssuerURL, oidc.NewRemoteKeySet(ctx, config.JWKSURL), verifierConfig)
+	default:
+		provider, err := oidc.NewProvider(ctx, config.IssuerURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to discover OIDC issuer: %w", err)
+		}
+		verifier = provider.Verifier(verifierConfig)
+	}
+
+	return &OIDCAuthenticator{config: config, verifier: verifier}, nil
+}
+
+// loadJWKS reads the public keys of a JSON Web Key Set file
+func loadJWKS(path string) (*oidc.StaticKeySet, error) {
+	data, err := os.ReadFile(path) // #nosec G304 -- path is configured by the operator
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWKS file: %w", err)
+	}
+	var jwks jose.JSONWebKeySet
+	if err := json.Unmarshal(data, &jwks); err != nil {
+		return nil, fmt.Errorf("invalid JWKS file %s: %w", path, err)
+	}
+
+	keySet := &oidc.StaticKeySet{}
+	for _, key := range jwks.Keys {
+		if !key.IsPublic() {
+			return nil, fmt.Errorf("JWKS file %s contains a private key", path)
+		}
+		keySet.PublicKeys = append(keySet.PublicKeys, crypto.PublicKey(key.Key))
+	}
+	if len(keySet.PublicKeys) == 0 {
+		return nil, fmt.Errorf("no keys found in JWKS file %s", path)
+	}
+	return keySet, nil
+}
+
+// Authenticate validates the bearer token of a request and returns who made
+// it. Browsers can't set headers on a WebSocket upgrade, so the token may be
+// passed in the access_token query parameter there.
+func (a *OIDCAuthenticator) Authenticate(r *http.Request) (*Identity, error) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok && websocket.IsWebSocketUpgrade(r) {
+		token = r.URL.Query().Get("access_token")
+	}
+	if token == "" {
+		return nil, errMissingToken
+	}
+
+	idToken, err := a.verifier.Verify(r.Context(), token)
+	if err != nil {
+		return nil, err
+	}
+	var claims map[string]any
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, err
+	}
+
+	username, _ := claims[a.config.UsernameClaim].(string)
+	if username == "" {
+		username = idToken.Subject
+	}
+	return &Identity{Username: username, Role: a.role(claimValues(claims, a.config.RolesClaim))}, nil
+}
+
+// errMissingToken is returned by Authenticate for requests without a token
+var errMissingToken = errors.New("missing bearer token")
+
+// role returns the highest role granted by the values of the roles claim
+func (a *OIDCAuthenticator) role(values []string) Role {
+	granted := func(roles []string) bool {
+		return slices.ContainsFunc(values, func(value string) bool { return slices.Contains(roles, value) })
+	}
+	switch {
+	case granted(a.config.AdminRoles):
+		return RoleAdmin
+	case granted(a.config.OperatorRoles):
+		return RoleOperator
+	case granted(a.config.ViewerRoles):
+		return RoleViewer
+	}
+	return RoleNone
+}
+
+// claimValues returns the values of a claim given by a dotted path, which may
+// be a string, a space separated string like scope, or a list of strings
+func claimValues(claims map[string]any, path string) []string {
+	var value any = claims
+	for name := range strings.SplitSeq(path, ".") {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+		value = object[name]
+	}
+
+	switch v := value.(type) {
+	case string:
+		return strings.Fields(v)
+	case []any:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// identityContextKey is the context key under which RequireRole stores the authenticated identity
+type identityContextKey struct{}
+
+// IdentityFromContext returns the user authenticated by RequireRole, or nil
+func IdentityFromContext(ctx context.Context) *Identity {
+	identity, _ := ctx.Value(identityContextKey{}).(*Identity)
+	return identity
+}
+
+// RequireRole creates a middleware that only lets requests with a valid
+// token of a user with at least the given role through. Requests without a
+// valid token are answered with 401, users lacking the role with 403.
+func (a *OIDCAuthenticator) RequireRole(role Role) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			identity, err := a.Authenticate(r)
+			if err != nil {
+				reason := "invalid_token"
+				if errors.Is(err, errMissingToken) {
+					reason = "missing_token"
+				} else {
+					log.Warn("OIDC authentication failed",
+						"path", r.URL.Path,
+						"remoteAddr", r.RemoteAddr,
+						"error", err)
+				}
+				metadata.APIAuthRejectedTotal.WithLabelValues(reason).Inc()
+				w.Header().Set("WWW-Authenticate", "Bearer realm=\"OpenFero\"")
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+
+			if !identity.Role.Allows(role) {
+				log.Warn("Authorization failed",
+					"user", identity.Username,
+					"role", string(identity.Role),
+					"requiredRole", string(role),
+					"method", r.Method,
+					"path", r.URL.Path)
+				metadata.APIAuthRejectedTotal.WithLabelValues("forbidden").Inc()
+				http.Error(w, "Forbidden", http.StatusForbidden)
+				return
+			}
+
+			r = r.WithContext(context.WithValue(r.Context(), identityContextKey{}, identity))
+			next(w, withPrincipal(r, "oidc:"+identity.Username))
+		}
+	}
+}
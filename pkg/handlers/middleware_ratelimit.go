@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	log "github.com/OpenFero/openfero/pkg/logging"
+	"github.com/OpenFero/openfero/pkg/metadata"
+)
+
+// What webhook requests are rate limited by
+const (
+	// RateLimitByRemote limits each remote address
+	RateLimitByRemote = "remote"
+	// RateLimitByCredential limits each credential, falling back to the remote
+	// address for requests without one
+	RateLimitByCredential = "credential"
+)
+
+// rateLimiterSweepInterval is how often limiters of idle sources are dropped
+const rateLimiterSweepInterval = time.Minute
+
+// RateLimitConfig configures the rate limiting of webhook requests
+type RateLimitConfig struct {
+	// Rate is the number of requests per second each source may make
+	Rate float64
+	// Burst is the number of requests a source may make at once above the rate
+	Burst int
+	// By is RateLimitByRemote or RateLimitByCredential
+	By string
+}
+
+// RateLimiter limits the requests of each source with a token bucket, so a
+// misconfigured or malicious sender can't cause a storm of Jobs or starve
+// other senders
+type RateLimiter struct {
+	config RateLimitConfig
+
+	mu        sync.Mutex
+	limiters  map[string]*rate.Limiter
+	lastSweep time.Time
+}
+
+// NewRateLimiter validates the configuration and creates a rate limiter
+func NewRateLimiter(config RateLimitConfig) (*RateLimiter, error) {
+	if config.Rate <= 0 {
+		return nil, fmt.Errorf("rate limit must be positive, got %g", config.Rate)
+	}
+	if config.Burst < 1 {
+		config.Burst = 1
+	}
+	switch config.By {
+	case "":
+		config.By = RateLimitByRemote
+	case RateLimitByRemote, RateLimitByCredential:
+	default:
+		return nil, fmt.Errorf("unsupported rate limit key %q, expected %s or %s", config.By, RateLimitByRemote, RateLimitByCredential)
+	}
+	return &RateLimiter{config: config, limiters: make(map[string]*rate.Limiter), lastSweep: time.Now()}, nil
+}
+
+// ByCredential reports whether requests are limited by credential. The
+// middleware must then run after authentication.
+func (l *RateLimiter) ByCredential() bool {
+	return l.config.By == RateLimitByCredential
+}
+
+// Middleware answers requests of sources that exceeded their rate with 429
+func (l *RateLimiter) Middleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		source := l.source(r)
+		if !l.allow(source, time.Now()) {
+			log.Warn("Rejecting webhook, rate limit exceeded",
+				"source", source,
+				"remoteAddr", r.RemoteAddr)
+			metadata.WebhookRejectedTotal.WithLabelValues("rate_limited").Inc()
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(1/l.config.Rate))))
+			http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// source returns the key a request is limited by
+func (l *RateLimiter) source(r *http.Request) string {
+	if l.ByCredential() {
+		if credential := CredentialFromContext(r.Context()); credential != "" {
+			return "credential:" + credential
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "remote:" + host
+}
+
+// allow takes a token from the bucket of a source
+func (l *RateLimiter) allow(source string, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Drop the buckets of sources that have been idle long enough to be full
+	// again, they behave like new ones
+	if now.Sub(l.lastSweep) >= rateLimiterSweepInterval {
+		for key, limiter := range l.limiters {
+			if limiter.TokensAt(now) >= float64(l.config.Burst) {
+				delete(l.limiters, key)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	limiter, ok := l.limiters[source]
+	if !ok {
+		limiter = rate.NewLimiter(rate.Limit(l.config.Rate), l.config.Burst)
+		l.limiters[source] = limiter
+	}
+	return limiter.AllowN(now, 1)
+}
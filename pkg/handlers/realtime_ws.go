@@ -50,6 +50,8 @@ type WSClient struct {
 	hub  *WSHub
 	conn *websocket.Conn
 	send chan []byte
+	// closed is set under hub.logs.mu once send is about to be closed
+	closed bool
 }
 
 // WSHub manages all WebSocket client connections
@@ -59,6 +61,7 @@ type WSHub struct {
 	register   chan *WSClient
 	unregister chan *WSClient
 	mu         sync.RWMutex
+	logs       wsLogStreams
 }
 
 // Global WebSocket hub instance
@@ -68,17 +71,23 @@ var wsOnce sync.Once
 // GetWSHub returns the singleton WebSocket hub instance
 func GetWSHub() *WSHub {
 	wsOnce.Do(func() {
-		wsHub = &WSHub{
-			clients:    make(map[*WSClient]struct{}),
-			broadcast:  make(chan []byte, 256),
-			register:   make(chan *WSClient),
-			unregister: make(chan *WSClient),
-		}
+		wsHub = newWSHub()
 		go wsHub.run()
 	})
 	return wsHub
 }
 
+// newWSHub creates an empty WebSocket hub
+func newWSHub() *WSHub {
+	return &WSHub{
+		clients:    make(map[*WSClient]struct{}),
+		broadcast:  make(chan []byte, 256),
+		register:   make(chan *WSClient),
+		unregister: make(chan *WSClient),
+		logs:       wsLogStreams{streams: make(map[JobRef]*logStream)},
+	}
+}
+
 // run processes WebSocket hub events
 func (h *WSHub) run() {
 	for {
@@ -93,23 +102,25 @@ func (h *WSHub) run() {
 			h.mu.Lock()
 			if _, ok := h.clients[client]; ok {
 				delete(h.clients, client)
+				h.unsubscribeAllLogs(client)
 				close(client.send)
 			}
 			h.mu.Unlock()
 			log.Debug("WebSocket client disconnected", "totalClients", len(h.clients))
 
 		case message := <-h.broadcast:
-			h.mu.RLock()
+			h.mu.Lock()
 			for client := range h.clients {
 				select {
 				case client.send <- message:
 				default:
 					// Client buffer full, close connection
+					h.unsubscribeAllLogs(client)
 					close(client.send)
 					delete(h.clients, client)
 				}
 			}
-			h.mu.RUnlock()
+			h.mu.Unlock()
 		}
 	}
 }
@@ -145,13 +156,14 @@ func (c *WSClient) readPump() {
 	})
 
 	for {
-		_, _, err := c.conn.ReadMessage()
+		_, message, err := c.conn.ReadMessage()
 		if err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 				log.Debug("WebSocket read error", "error", err)
 			}
 			break
 		}
+		c.hub.handleClientMessage(c, message)
 	}
 }
 
@@ -201,7 +213,8 @@ func (c *WSClient) writePump() {
 
 // WebSocketHandler handles WebSocket connections
 // @Summary WebSocket endpoint for real-time updates
-// @Description Establish a WebSocket connection for real-time alert and job status updates
+// @Description Establish a WebSocket connection for real-time alert and job status updates.
+// @Description Clients can send {"type":"subscribe_logs","data":{"namespace":"...","name":"..."}} to receive job_log messages for a Job, and unsubscribe_logs to stop them.
 // @Tags websocket
 // @Success 101 {string} string "Switching Protocols"
 // @Router /api/ws [get]
@@ -0,0 +1,223 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+
+	log "github.com/OpenFero/openfero/pkg/logging"
+)
+
+const (
+	// WebSocket message types clients send to (un)subscribe from job logs
+	wsTypeSubscribeLogs   = "subscribe_logs"
+	wsTypeUnsubscribeLogs = "unsubscribe_logs"
+
+	// WebSocket message types sent to log subscribers
+	wsTypeJobLog      = "job_log"
+	wsTypeJobLogEnd   = "job_log_end"
+	wsTypeJobLogError = "job_log_error"
+
+	// Longest log line forwarded to subscribers
+	maxLogLineSize = 1024 * 1024
+)
+
+// LogStreamFunc opens a followed log stream for a Job
+type LogStreamFunc func(ctx context.Context, namespace, name string) (io.ReadCloser, error)
+
+// JobRef identifies a Job
+type JobRef struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+// JobLogLine is a single log line of a Job sent to WebSocket subscribers
+type JobLogLine struct {
+	JobRef
+	Line string `json:"line"`
+}
+
+// JobLogError tells a subscriber that the logs of a Job could not be streamed
+type JobLogError struct {
+	JobRef
+	Error string `json:"error"`
+}
+
+// wsClientMessage is a message received from a WebSocket client
+type wsClientMessage struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// logStream is a single upstream log stream shared by all clients subscribed to the same Job
+type logStream struct {
+	cancel      context.CancelFunc
+	subscribers map[*WSClient]struct{}
+}
+
+// wsLogStreams multiplexes Job log streams to WebSocket clients
+type wsLogStreams struct {
+	streams  map[JobRef]*logStream
+	streamer LogStreamFunc
+	// mu also guards sends to subscribers: a client is removed under the write
+	// lock before its send channel is closed, so no log line is ever sent to a
+	// closed channel.
+	mu sync.RWMutex
+}
+
+// SetLogStreamer sets the function used to open Job log streams for subscribers
+func (h *WSHub) SetLogStreamer(streamer LogStreamFunc) {
+	h.logs.mu.Lock()
+	defer h.logs.mu.Unlock()
+	h.logs.streamer = streamer
+}
+
+// handleClientMessage processes a message received from a WebSocket client
+func (h *WSHub) handleClientMessage(client *WSClient, data []byte) {
+	var msg wsClientMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		log.Debug("Ignoring malformed WebSocket message", "error", err)
+		return
+	}
+
+	var ref JobRef
+	switch msg.Type {
+	case wsTypeSubscribeLogs, wsTypeUnsubscribeLogs:
+		if err := json.Unmarshal(msg.Data, &ref); err != nil || ref.Namespace == "" || ref.Name == "" {
+			log.Debug("Ignoring log subscription without job reference", "type", msg.Type)
+			return
+		}
+	default:
+		log.Debug("Ignoring unknown WebSocket message type", "type", msg.Type)
+		return
+	}
+
+	if msg.Type == wsTypeSubscribeLogs {
+		h.subscribeLogs(client, ref)
+	} else {
+		h.unsubscribeLogs(client, ref)
+	}
+}
+
+// subscribeLogs subscribes a client to the logs of a Job, starting the upstream stream if needed
+func (h *WSHub) subscribeLogs(client *WSClient, ref JobRef) {
+	h.logs.mu.Lock()
+	defer h.logs.mu.Unlock()
+
+	if client.closed {
+		return
+	}
+
+	if h.logs.streamer == nil {
+		sendToClient(client, wsTypeJobLogError, JobLogError{JobRef: ref, Error: "log streaming is not available"})
+		return
+	}
+
+	if stream, ok := h.logs.streams[ref]; ok {
+		stream.subscribers[client] = struct{}{}
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := &logStream{
+		cancel:      cancel,
+		subscribers: map[*WSClient]struct{}{client: {}},
+	}
+	h.logs.streams[ref] = stream
+
+	log.Debug("Starting job log stream", "namespace", ref.Namespace, "job", ref.Name)
+	go h.pumpLogs(ctx, ref, stream, h.logs.streamer)
+}
+
+// unsubscribeLogs removes a client from the subscribers of a Job's logs
+func (h *WSHub) unsubscribeLogs(client *WSClient, ref JobRef) {
+	h.logs.mu.Lock()
+	defer h.logs.mu.Unlock()
+	h.removeLogSubscriber(client, ref)
+}
+
+// unsubscribeAllLogs removes a client from all log streams. It must be called
+// before the client's send channel is closed.
+func (h *WSHub) unsubscribeAllLogs(client *WSClient) {
+	h.logs.mu.Lock()
+	defer h.logs.mu.Unlock()
+	client.closed = true
+	for ref := range h.logs.streams {
+		h.removeLogSubscriber(client, ref)
+	}
+}
+
+// removeLogSubscriber removes a subscriber and stops the stream once nobody listens. Callers hold logs.mu.
+func (h *WSHub) removeLogSubscriber(client *WSClient, ref JobRef) {
+	stream, ok := h.logs.streams[ref]
+	if !ok {
+		return
+	}
+	delete(stream.subscribers, client)
+	if len(stream.subscribers) == 0 {
+		stream.cancel()
+		delete(h.logs.streams, ref)
+		log.Debug("Stopped job log stream, no subscribers left", "namespace", ref.Namespace, "job", ref.Name)
+	}
+}
+
+// pumpLogs reads a Job's log stream line by line and fans the lines out to its subscribers
+func (h *WSHub) pumpLogs(ctx context.Context, ref JobRef, stream *logStream, streamer LogStreamFunc) {
+	defer func() {
+		h.logs.mu.Lock()
+		if h.logs.streams[ref] == stream {
+			delete(h.logs.streams, ref)
+		}
+		h.logs.mu.Unlock()
+	}()
+
+	reader, err := streamer(ctx, ref.Namespace, ref.Name)
+	if err != nil {
+		log.Debug("Failed to open job log stream", "namespace", ref.Namespace, "job", ref.Name, "error", err)
+		h.sendToLogSubscribers(stream, wsTypeJobLogError, JobLogError{JobRef: ref, Error: err.Error()})
+		return
+	}
+	defer func() {
+		if closeErr := reader.Close(); closeErr != nil {
+			log.Debug("Failed to close job log stream", "job", ref.Name, "error", closeErr)
+		}
+	}()
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLogLineSize)
+	for scanner.Scan() {
+		h.sendToLogSubscribers(stream, wsTypeJobLog, JobLogLine{JobRef: ref, Line: scanner.Text()})
+	}
+
+	if err := scanner.Err(); err != nil && ctx.Err() == nil {
+		h.sendToLogSubscribers(stream, wsTypeJobLogError, JobLogError{JobRef: ref, Error: err.Error()})
+		return
+	}
+	h.sendToLogSubscribers(stream, wsTypeJobLogEnd, ref)
+}
+
+// sendToLogSubscribers sends a message to all current subscribers of a stream
+func (h *WSHub) sendToLogSubscribers(stream *logStream, msgType string, data any) {
+	h.logs.mu.RLock()
+	defer h.logs.mu.RUnlock()
+	for client := range stream.subscribers {
+		sendToClient(client, msgType, data)
+	}
+}
+
+// sendToClient sends a message to a single client without blocking. Messages
+// to clients that cannot keep up are dropped rather than stalling the stream.
+func sendToClient(client *WSClient, msgType string, data any) {
+	jsonData, err := json.Marshal(WSMessage{Type: msgType, Data: data})
+	if err != nil {
+		log.Error("Failed to marshal WebSocket message", "error", err)
+		return
+	}
+	select {
+	case client.send <- jsonData:
+	default:
+		log.Debug("WebSocket client buffer full, dropping message", "type", msgType)
+	}
+}
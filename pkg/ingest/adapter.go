@@ -0,0 +1,109 @@
+package ingest
+
+import (
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/OpenFero/openfero/pkg/models"
+)
+
+// Adapter normalises the webhook payload of an alert source into a hook
+// message, so alerts from any source go through the same Operarius matching.
+type Adapter interface {
+	// Name is the path segment the adapter is served under, e.g. /alerts/grafana
+	Name() string
+	// Decode reads the webhook payload of a request. Adapters only need the
+	// body, unless the source passes data in headers like CloudEvents do.
+	Decode(r *http.Request) (models.HookMessage, error)
+}
+
+// Adapters holds the registered adapters by name
+type Adapters map[string]Adapter
+
+// Register adds an adapter, replacing one registered under the same name
+func (a Adapters) Register(adapter Adapter) {
+	a[adapter.Name()] = adapter
+}
+
+// Get returns the adapter registered under name
+func (a Adapters) Get(name string) (Adapter, bool) {
+	adapter, ok := a[name]
+	return adapter, ok
+}
+
+// Names returns the names of the registered adapters in order
+func (a Adapters) Names() []string {
+	return slices.Sorted(maps.Keys(a))
+}
+
+// normalize fills in the parts of a hook message Alertmanager always sends,
+// but other sources don't: per-alert and group status, common labels and a
+// group key. Adapters call it after mapping a payload.
+func normalize(hookMessage *models.HookMessage, source string) error {
+	if len(hookMessage.Alerts) == 0 {
+		return fmt.Errorf("%s payload contains no alerts", source)
+	}
+
+	firing := false
+	for i := range hookMessage.Alerts {
+		alert := &hookMessage.Alerts[i]
+		if alert.Labels["alertname"] == "" {
+			return fmt.Errorf("%s alert %d has no alertname", source, i)
+		}
+		alert.Status = alert.StatusOr(hookMessage.Status)
+		switch alert.Status {
+		case "firing":
+			firing = true
+		case "resolved":
+		default:
+			return fmt.Errorf("%s alert %d has status %q, expected firing or resolved", source, i, alert.Status)
+		}
+	}
+	if hookMessage.Status == "" {
+		hookMessage.Status = "resolved"
+		if firing {
+			hookMessage.Status = "firing"
+		}
+	}
+
+	if hookMessage.CommonLabels == nil {
+		hookMessage.CommonLabels = commonValues(hookMessage.Alerts, func(a models.Alert) map[string]string { return a.Labels })
+	}
+	if hookMessage.CommonAnnotations == nil {
+		hookMessage.CommonAnnotations = commonValues(hookMessage.Alerts, func(a models.Alert) map[string]string { return a.Annotations })
+	}
+	if hookMessage.GroupKey == "" {
+		hookMessage.GroupKey = source + ":" + labelString(hookMessage.CommonLabels)
+	}
+	return nil
+}
+
+// commonValues returns the key-value pairs all alerts share
+func commonValues(alerts []models.Alert, values func(models.Alert) map[string]string) map[string]string {
+	common := maps.Clone(values(alerts[0]))
+	if common == nil {
+		common = map[string]string{}
+	}
+	for _, alert := range alerts[1:] {
+		other := values(alert)
+		for key, value := range common {
+			if other[key] != value {
+				delete(common, key)
+			}
+		}
+	}
+	return common
+}
+
+// labelString renders labels like Alertmanager renders group labels in a group key
+func labelString(labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for key, value := range labels {
+		pairs = append(pairs, fmt.Sprintf("%s=%q", key, value))
+	}
+	slices.Sort(pairs)
+	return "{" + strings.Join(pairs, ",") + "}"
+}
@@ -0,0 +1,215 @@
+package ingest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGrafanaAdapter(t *testing.T) {
+	t.Run("Grafana managed alerts", func(t *testing.T) {
+		hookMessage, err := GrafanaAdapter{}.Decode(newRequest(`{
+			"receiver": "openfero",
+			"status": "firing",
+			"orgId": 1,
+			"groupKey": "{}:{alertname=\"HighLatency\"}",
+			"alerts": [
+				{
+					"status": "firing",
+					"labels": {"alertname": "HighLatency", "service": "api"},
+					"annotations": {"summary": "Latency is high"},
+					"fingerprint": "1a2b3c4d5e6f7a8b",
+					"dashboardURL": "http://grafana/d/abc",
+					"valueString": "[ var='A' value=1.5 ]"
+				},
+				{
+					"status": "resolved",
+					"labels": {"alertname": "HighLatency", "service": "web"}
+				}
+			],
+			"title": "[FIRING:1] HighLatency",
+			"state": "alerting"
+		}`))
+		require.NoError(t, err)
+
+		assert.Equal(t, "firing", hookMessage.Status)
+		assert.Equal(t, `{}:{alertname="HighLatency"}`, hookMessage.GroupKey)
+		require.Len(t, hookMessage.Alerts, 2)
+		alert := hookMessage.Alerts[0]
+		assert.Equal(t, "1a2b3c4d5e6f7a8b", alert.Fingerprint)
+		assert.Equal(t, "Latency is high", alert.Annotations["summary"])
+		assert.Equal(t, "http://grafana/d/abc", alert.Annotations["dashboardURL"])
+		assert.Equal(t, "[ var='A' value=1.5 ]", alert.Annotations["valueString"])
+		assert.Equal(t, "resolved", hookMessage.Alerts[1].Status)
+		assert.Equal(t, map[string]string{"alertname": "HighLatency"}, hookMessage.CommonLabels)
+	})
+
+	t.Run("legacy dashboard alerting", func(t *testing.T) {
+		hookMessage, err := GrafanaAdapter{}.Decode(newRequest(`{
+			"title": "[Alerting] Disk full",
+			"ruleId": 7,
+			"ruleName": "DiskFull",
+			"ruleUrl": "http://grafana/d/disk?panelId=2",
+			"state": "alerting",
+			"message": "Disk usage above 90%",
+			"tags": {"instance": "node-1"}
+		}`))
+		require.NoError(t, err)
+
+		assert.Equal(t, "firing", hookMessage.Status)
+		assert.Equal(t, "grafana:rule/7", hookMessage.GroupKey)
+		require.Len(t, hookMessage.Alerts, 1)
+		alert := hookMessage.Alerts[0]
+		assert.Equal(t, map[string]string{"alertname": "DiskFull", "instance": "node-1"}, alert.Labels)
+		assert.Equal(t, "Disk usage above 90%", alert.Annotations["description"])
+		assert.Equal(t, "http://grafana/d/disk?panelId=2", alert.GeneratorURL)
+	})
+
+	t.Run("invalid payloads", func(t *testing.T) {
+		for _, body := range []string{
+			`not json`,
+			`{"status":"firing","alerts":[]}`,
+			`{"status":"firing","alerts":[{"labels":{"service":"api"}}]}`,
+		} {
+			_, err := GrafanaAdapter{}.Decode(newRequest(body))
+			assert.Error(t, err, body)
+		}
+	})
+}
+
+func TestGenericAdapter(t *testing.T) {
+	adapter, err := NewGenericAdapter(GenericMapping{
+		Alerts:   "data.incidents",
+		GroupKey: "{{ .source }}",
+		Alert: GenericAlertMapping{
+			Labels: map[string]string{
+				"alertname": "{{ .check }}",
+				"host":      "{{ .host.name }}",
+				"severity":  "{{ .priority }}",
+			},
+			Annotations: map[string]string{"summary": "{{ .text }}"},
+			Status:      `{{ if eq .state "open" }}firing{{ else }}resolved{{ end }}`,
+			Fingerprint: "{{ .id }}",
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "generic", adapter.Name())
+
+	hookMessage, err := adapter.Decode(newRequest(`{
+		"source": "monitoring",
+		"data": {"incidents": [
+			{"id": 42, "check": "DiskFull", "host": {"name": "node-1"}, "state": "open", "text": "Disk full"},
+			{"id": 43, "check": "DiskFull", "host": {"name": "node-2"}, "state": "closed"}
+		]}
+	}`))
+	require.NoError(t, err)
+
+	assert.Equal(t, "monitoring", hookMessage.GroupKey)
+	assert.Equal(t, "firing", hookMessage.Status, "the message fires if any alert fires")
+	require.Len(t, hookMessage.Alerts, 2)
+	first := hookMessage.Alerts[0]
+	assert.Equal(t, map[string]string{"alertname": "DiskFull", "host": "node-1"}, first.Labels, "missing fields are left out")
+	assert.Equal(t, "Disk full", first.Annotations["summary"])
+	assert.Equal(t, "firing", first.Status)
+	assert.Equal(t, "42", first.Fingerprint)
+	assert.Equal(t, "resolved", hookMessage.Alerts[1].Status)
+	assert.Empty(t, hookMessage.Alerts[1].Annotations)
+
+	_, err = adapter.Decode(newRequest(`{"data": {}}`))
+	assert.ErrorContains(t, err, "data.incidents")
+	_, err = adapter.Decode(newRequest(`{"data": {"incidents": [{"state": "open", "host": {}}]}}`))
+	assert.ErrorContains(t, err, "alertname")
+	_, err = adapter.Decode(newRequest(`{"data": {"incidents": [{"check": "DiskFull", "state": "open"}]}}`))
+	assert.ErrorContains(t, err, "host", "fields of missing objects can't be rendered")
+}
+
+func TestNewGenericAdapter_InvalidMapping(t *testing.T) {
+	_, err := NewGenericAdapter(GenericMapping{})
+	assert.ErrorContains(t, err, "alertname")
+
+	_, err = NewGenericAdapter(GenericMapping{Alert: GenericAlertMapping{
+		Labels: map[string]string{"alertname": "{{ .name"},
+	}})
+	assert.ErrorContains(t, err, "alertname")
+}
+
+// newRequest returns a webhook request with the given body
+func newRequest(body string) *http.Request {
+	return httptest.NewRequest(http.MethodPost, "/alerts", strings.NewReader(body))
+}
+
+func TestCloudEventsAdapter(t *testing.T) {
+	binary := func(eventType, body string, extensions map[string]string) *http.Request {
+		req := newRequest(body)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("ce-id", "42")
+		req.Header.Set("ce-source", "monitoring")
+		req.Header.Set("ce-specversion", "1.0")
+		req.Header.Set("ce-type", eventType)
+		for name, value := range extensions {
+			req.Header.Set("ce-"+name, value)
+		}
+		return req
+	}
+
+	t.Run("arbitrary event", func(t *testing.T) {
+		req := binary("com.example.disk.full", `{"disk":"sda"}`, map[string]string{"alertname": "DiskFull", "severity": "critical"})
+		req.Header.Set("ce-subject", "node-1")
+		hookMessage, err := CloudEventsAdapter{}.Decode(req)
+		require.NoError(t, err)
+
+		assert.Equal(t, "firing", hookMessage.Status)
+		require.Len(t, hookMessage.Alerts, 1)
+		alert := hookMessage.Alerts[0]
+		assert.Equal(t, map[string]string{
+			"alertname": "DiskFull",
+			"severity":  "critical",
+			"source":    "monitoring",
+			"subject":   "node-1",
+		}, alert.Labels)
+		assert.JSONEq(t, `{"disk":"sda"}`, alert.Annotations["data"])
+		assert.NotEmpty(t, hookMessage.DeliveryID)
+		assert.NotEmpty(t, hookMessage.GroupKey)
+
+		again, err := CloudEventsAdapter{}.Decode(binary("com.example.disk.full", `{}`, nil))
+		require.NoError(t, err)
+		assert.Equal(t, hookMessage.DeliveryID, again.DeliveryID, "a redelivered event keeps its delivery ID")
+		assert.Equal(t, "com.example.disk.full", again.Alerts[0].Labels["alertname"])
+	})
+
+	t.Run("resolved event", func(t *testing.T) {
+		hookMessage, err := CloudEventsAdapter{}.Decode(binary("com.example.disk.full", "", map[string]string{"alertstatus": "resolved"}))
+		require.NoError(t, err)
+		assert.Equal(t, "resolved", hookMessage.Status)
+		assert.NotContains(t, hookMessage.Alerts[0].Labels, "alertstatus")
+	})
+
+	t.Run("Alertmanager message as data", func(t *testing.T) {
+		req := newRequest(`{
+			"specversion": "1.0", "id": "42", "source": "alertmanager", "type": "io.prometheus.alertmanager.webhook",
+			"data": {"status": "firing", "groupKey": "group-1", "alerts": [{"labels": {"alertname": "TestAlert"}}]}
+		}`)
+		req.Header.Set("Content-Type", "application/cloudevents+json")
+		hookMessage, err := CloudEventsAdapter{}.Decode(req)
+		require.NoError(t, err)
+		assert.Equal(t, "group-1", hookMessage.GroupKey)
+		assert.Equal(t, "TestAlert", hookMessage.Alerts[0].Labels["alertname"])
+	})
+
+	t.Run("alert as data", func(t *testing.T) {
+		hookMessage, err := CloudEventsAdapter{}.Decode(binary("com.example.alert",
+			`{"status": "resolved", "labels": {"alertname": "TestAlert"}, "annotations": {"summary": "ok"}}`, nil))
+		require.NoError(t, err)
+		assert.Equal(t, "resolved", hookMessage.Status)
+		assert.Equal(t, "ok", hookMessage.Alerts[0].Annotations["summary"])
+	})
+
+	t.Run("invalid event", func(t *testing.T) {
+		_, err := CloudEventsAdapter{}.Decode(newRequest(`{}`))
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,113 @@
+package ingest
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"time"
+
+	"github.com/OpenFero/openfero/pkg/cloudevents"
+	"github.com/OpenFero/openfero/pkg/models"
+	"github.com/OpenFero/openfero/pkg/utils"
+)
+
+// CloudEventsAdapter accepts CloudEvents in binary or structured mode.
+//
+// Events carrying an Alertmanager webhook message or a single alert as data
+// are taken as they are. Any other event becomes a single alert named after
+// the event type, or the alertname extension, labelled with the event source,
+// subject and extensions. The alertstatus extension sets its status, firing
+// by default.
+type CloudEventsAdapter struct{}
+
+// Name implements Adapter
+func (CloudEventsAdapter) Name() string {
+	return "cloudevents"
+}
+
+// Decode implements Adapter
+func (a CloudEventsAdapter) Decode(r *http.Request) (models.HookMessage, error) {
+	event, err := cloudevents.FromRequest(r)
+	if err != nil {
+		return models.HookMessage{}, err
+	}
+
+	hookMessage, err := eventHookMessage(event)
+	if err != nil {
+		return models.HookMessage{}, err
+	}
+	// The source and ID identify an event, a redelivered event is processed only once
+	hookMessage.DeliveryID = utils.HashGroupKey(event.Source + "\n" + event.ID)
+
+	if err := normalize(&hookMessage, a.Name()); err != nil {
+		return models.HookMessage{}, err
+	}
+	return hookMessage, nil
+}
+
+// eventHookMessage maps the data of an event to a hook message
+func eventHookMessage(event cloudevents.Event) (models.HookMessage, error) {
+	var probe struct {
+		Alerts json.RawMessage   `json:"alerts"`
+		Labels map[string]string `json:"labels"`
+	}
+	isObject := len(event.Data) > 0 && event.Data[0] == '{'
+	if isObject {
+		if err := json.Unmarshal(event.Data, &probe); err != nil {
+			return models.HookMessage{}, fmt.Errorf("invalid event data: %w", err)
+		}
+	}
+
+	switch {
+	case probe.Alerts != nil:
+		var hookMessage models.HookMessage
+		if err := json.Unmarshal(event.Data, &hookMessage); err != nil {
+			return models.HookMessage{}, fmt.Errorf("invalid Alertmanager message in event data: %w", err)
+		}
+		return hookMessage, nil
+	case probe.Labels != nil:
+		var alert models.Alert
+		if err := json.Unmarshal(event.Data, &alert); err != nil {
+			return models.HookMessage{}, fmt.Errorf("invalid alert in event data: %w", err)
+		}
+		if alert.StartsAt == "" && !event.Time.IsZero() {
+			alert.StartsAt = event.Time.Format(time.RFC3339)
+		}
+		return models.HookMessage{Alerts: []models.Alert{alert}}, nil
+	}
+
+	return models.HookMessage{Alerts: []models.Alert{eventAlert(event)}}, nil
+}
+
+// eventAlert describes an arbitrary event as alert
+func eventAlert(event cloudevents.Event) models.Alert {
+	labels := maps.Clone(event.Extensions)
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	delete(labels, "alertstatus")
+	if labels["alertname"] == "" {
+		labels["alertname"] = event.Type
+	}
+	labels["source"] = event.Source
+	if event.Subject != "" {
+		labels["subject"] = event.Subject
+	}
+
+	alert := models.Alert{
+		Labels: labels,
+		Status: event.Extensions["alertstatus"],
+	}
+	if alert.Status == "" {
+		alert.Status = "firing"
+	}
+	if !event.Time.IsZero() {
+		alert.StartsAt = event.Time.Format(time.RFC3339)
+	}
+	// Keep the data available to job templates
+	if len(event.Data) > 0 {
+		alert.Annotations = map[string]string{"data": string(event.Data)}
+	}
+	return alert
+}
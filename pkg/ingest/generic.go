@@ -0,0 +1,219 @@
+package ingest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"text/template"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/OpenFero/openfero/pkg/models"
+)
+
+// GenericMapping describes how a JSON payload is mapped to a hook message.
+// All fields except Alerts are Go templates. Message fields are rendered with
+// the payload, alert fields with the alert object. Fields missing from the
+// payload render empty, fields of a missing object fail unless guarded with
+// {{ with }}. Empty labels and annotations are left out.
+type GenericMapping struct {
+	// Alerts is the dot separated path of the array holding the alerts, e.g.
+	// "data.incidents". If empty, the payload is a single alert.
+	Alerts string `json:"alerts,omitempty"`
+	// Status of the message, derived from the alerts if empty
+	Status string `json:"status,omitempty"`
+	// GroupKey of the message, derived from the common labels if empty
+	GroupKey string `json:"groupKey,omitempty"`
+	// Alert maps an alert object
+	Alert GenericAlertMapping `json:"alert"`
+}
+
+// GenericAlertMapping maps an alert object of a JSON payload
+type GenericAlertMapping struct {
+	// Labels of the alert, alertname is required
+	Labels map[string]string `json:"labels"`
+	// Annotations of the alert
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// Status of the alert, firing or resolved. Defaults to the message status.
+	Status       string `json:"status,omitempty"`
+	StartsAt     string `json:"startsAt,omitempty"`
+	EndsAt       string `json:"endsAt,omitempty"`
+	GeneratorURL string `json:"generatorURL,omitempty"`
+	Fingerprint  string `json:"fingerprint,omitempty"`
+}
+
+// GenericAdapter accepts arbitrary JSON webhooks and maps them with a GenericMapping
+type GenericAdapter struct {
+	alertsPath  []string
+	message     map[string]*template.Template
+	alert       map[string]*template.Template
+	labels      map[string]*template.Template
+	annotations map[string]*template.Template
+}
+
+// LoadGenericMapping reads a GenericMapping from a YAML or JSON file
+func LoadGenericMapping(path string) (GenericMapping, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return GenericMapping{}, fmt.Errorf("failed to read generic webhook mapping: %w", err)
+	}
+	var mapping GenericMapping
+	if err := yaml.UnmarshalStrict(data, &mapping); err != nil {
+		return GenericMapping{}, fmt.Errorf("invalid generic webhook mapping %s: %w", path, err)
+	}
+	return mapping, nil
+}
+
+// NewGenericAdapter creates an adapter for the given mapping. It fails if a
+// template doesn't parse or no alertname label is mapped.
+func NewGenericAdapter(mapping GenericMapping) (*GenericAdapter, error) {
+	if mapping.Alert.Labels["alertname"] == "" {
+		return nil, errors.New("generic webhook mapping must map the alertname label")
+	}
+
+	a := &GenericAdapter{}
+	if mapping.Alerts != "" {
+		a.alertsPath = strings.Split(mapping.Alerts, ".")
+	}
+
+	var err error
+	if a.message, err = parseTemplates(map[string]string{
+		"status":   mapping.Status,
+		"groupKey": mapping.GroupKey,
+	}); err != nil {
+		return nil, err
+	}
+	if a.alert, err = parseTemplates(map[string]string{
+		"status":       mapping.Alert.Status,
+		"startsAt":     mapping.Alert.StartsAt,
+		"endsAt":       mapping.Alert.EndsAt,
+		"generatorURL": mapping.Alert.GeneratorURL,
+		"fingerprint":  mapping.Alert.Fingerprint,
+	}); err != nil {
+		return nil, err
+	}
+	if a.labels, err = parseTemplates(mapping.Alert.Labels); err != nil {
+		return nil, err
+	}
+	if a.annotations, err = parseTemplates(mapping.Alert.Annotations); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// Name implements Adapter
+func (a *GenericAdapter) Name() string {
+	return "generic"
+}
+
+// Decode implements Adapter
+func (a *GenericAdapter) Decode(r *http.Request) (models.HookMessage, error) {
+	dec := json.NewDecoder(r.Body)
+	dec.UseNumber()
+	var payload any
+	if err := dec.Decode(&payload); err != nil {
+		return models.HookMessage{}, fmt.Errorf("invalid JSON payload: %w", err)
+	}
+
+	items, err := a.alertObjects(payload)
+	if err != nil {
+		return models.HookMessage{}, err
+	}
+
+	message, err := render(a.message, payload)
+	if err != nil {
+		return models.HookMessage{}, err
+	}
+	hookMessage := models.HookMessage{
+		Version:  "generic",
+		Status:   message["status"],
+		GroupKey: message["groupKey"],
+		Alerts:   make([]models.Alert, 0, len(items)),
+	}
+
+	for _, item := range items {
+		fields, err := render(a.alert, item)
+		if err != nil {
+			return models.HookMessage{}, err
+		}
+		labels, err := render(a.labels, item)
+		if err != nil {
+			return models.HookMessage{}, err
+		}
+		annotations, err := render(a.annotations, item)
+		if err != nil {
+			return models.HookMessage{}, err
+		}
+		hookMessage.Alerts = append(hookMessage.Alerts, models.Alert{
+			Labels:       labels,
+			Annotations:  annotations,
+			Status:       fields["status"],
+			StartsAt:     fields["startsAt"],
+			EndsAt:       fields["endsAt"],
+			GeneratorURL: fields["generatorURL"],
+			Fingerprint:  fields["fingerprint"],
+		})
+	}
+
+	if err := normalize(&hookMessage, a.Name()); err != nil {
+		return models.HookMessage{}, err
+	}
+	return hookMessage, nil
+}
+
+// alertObjects returns the alert objects of a payload
+func (a *GenericAdapter) alertObjects(payload any) ([]any, error) {
+	if a.alertsPath == nil {
+		return []any{payload}, nil
+	}
+
+	value := payload
+	for _, key := range a.alertsPath {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("payload has no object at %q", key)
+		}
+		value = object[key]
+	}
+	items, ok := value.([]any)
+	if !ok {
+		return nil, fmt.Errorf("payload has no array at %q", strings.Join(a.alertsPath, "."))
+	}
+	return items, nil
+}
+
+// parseTemplates parses the non-empty templates of a mapping
+func parseTemplates(sources map[string]string) (map[string]*template.Template, error) {
+	templates := make(map[string]*template.Template, len(sources))
+	for name, source := range sources {
+		if source == "" {
+			continue
+		}
+		tmpl, err := template.New(name).Option("missingkey=zero").Parse(source)
+		if err != nil {
+			return nil, fmt.Errorf("invalid template for %s: %w", name, err)
+		}
+		templates[name] = tmpl
+	}
+	return templates, nil
+}
+
+// render executes templates with data. Empty results are left out.
+func render(templates map[string]*template.Template, data any) (map[string]string, error) {
+	values := make(map[string]string, len(templates))
+	for name, tmpl := range templates {
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return nil, fmt.Errorf("failed to map %s: %w", name, err)
+		}
+		// Missing keys of JSON objects render as "<no value>"
+		if value := strings.TrimSpace(strings.ReplaceAll(buf.String(), "<no value>", "")); value != "" {
+			values[name] = value
+		}
+	}
+	return values, nil
+}
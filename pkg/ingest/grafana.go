@@ -0,0 +1,126 @@
+package ingest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/OpenFero/openfero/pkg/models"
+)
+
+// GrafanaAdapter accepts webhooks of Grafana Alerting. Grafana managed alerts
+// use the Alertmanager payload with a few additions, which are kept as
+// annotations. Payloads of the legacy dashboard alerting are converted as well.
+type GrafanaAdapter struct{}
+
+// grafanaAlert is an alert of a Grafana Alerting webhook
+type grafanaAlert struct {
+	models.Alert
+	SilenceURL   string `json:"silenceURL"`
+	DashboardURL string `json:"dashboardURL"`
+	PanelURL     string `json:"panelURL"`
+	ValueString  string `json:"valueString"`
+}
+
+// grafanaPayload is a Grafana Alerting webhook, or a legacy alert notification
+type grafanaPayload struct {
+	models.HookMessage
+	Alerts []grafanaAlert `json:"alerts"`
+
+	// Legacy dashboard alerting
+	Title    string            `json:"title"`
+	RuleID   json.Number       `json:"ruleId"`
+	RuleName string            `json:"ruleName"`
+	RuleURL  string            `json:"ruleUrl"`
+	State    string            `json:"state"`
+	Message  string            `json:"message"`
+	Tags     map[string]string `json:"tags"`
+}
+
+// Name implements Adapter
+func (GrafanaAdapter) Name() string {
+	return "grafana"
+}
+
+// Decode implements Adapter
+func (a GrafanaAdapter) Decode(r *http.Request) (models.HookMessage, error) {
+	var payload grafanaPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		return models.HookMessage{}, fmt.Errorf("invalid Grafana payload: %w", err)
+	}
+
+	hookMessage := payload.HookMessage
+	if payload.Alerts == nil && payload.RuleName != "" {
+		hookMessage = payload.legacyHookMessage()
+	} else {
+		hookMessage.Alerts = make([]models.Alert, 0, len(payload.Alerts))
+		for _, alert := range payload.Alerts {
+			hookMessage.Alerts = append(hookMessage.Alerts, alert.toAlert())
+		}
+	}
+
+	if err := normalize(&hookMessage, a.Name()); err != nil {
+		return models.HookMessage{}, err
+	}
+	return hookMessage, nil
+}
+
+// toAlert keeps the links and values Grafana adds to an alert as annotations,
+// unless the rule defines annotations of the same name
+func (a grafanaAlert) toAlert() models.Alert {
+	alert := a.Alert
+	extra := map[string]string{
+		"silenceURL":   a.SilenceURL,
+		"dashboardURL": a.DashboardURL,
+		"panelURL":     a.PanelURL,
+		"valueString":  a.ValueString,
+	}
+	for key, value := range extra {
+		if value == "" {
+			continue
+		}
+		if alert.Annotations == nil {
+			alert.Annotations = make(map[string]string)
+		}
+		if _, ok := alert.Annotations[key]; !ok {
+			alert.Annotations[key] = value
+		}
+	}
+	return alert
+}
+
+// legacyHookMessage converts a notification of the legacy dashboard alerting,
+// which describes a single rule. Its tags become the labels of the alert.
+func (p grafanaPayload) legacyHookMessage() models.HookMessage {
+	labels := make(map[string]string, len(p.Tags)+1)
+	for key, value := range p.Tags {
+		labels[key] = value
+	}
+	labels["alertname"] = p.RuleName
+
+	annotations := make(map[string]string)
+	if p.Title != "" {
+		annotations["summary"] = p.Title
+	}
+	if p.Message != "" {
+		annotations["description"] = p.Message
+	}
+
+	status := "resolved"
+	if strings.EqualFold(p.State, "alerting") {
+		status = "firing"
+	}
+
+	return models.HookMessage{
+		Version:  "legacy",
+		GroupKey: "grafana:rule/" + p.RuleID.String(),
+		Status:   status,
+		Alerts: []models.Alert{{
+			Labels:       labels,
+			Annotations:  annotations,
+			Status:       status,
+			GeneratorURL: p.RuleURL,
+		}},
+	}
+}
@@ -0,0 +1,139 @@
+package ingest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/OpenFero/openfero/pkg/models"
+)
+
+// IdempotencyKeyHeader names a delivery, so the sender's retries of it are
+// recognized even if their payload differs
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// DefaultIdempotencyTTL is how long the keys of accepted deliveries are remembered
+const DefaultIdempotencyTTL = 5 * time.Minute
+
+// deliveryLogSweepInterval is how often expired keys are dropped
+const deliveryLogSweepInterval = time.Minute
+
+// IdempotencyKey computes the key of a delivery that wasn't sent with one.
+// Alertmanager retries a notification with the same group, status and
+// alerts, so those identify it. Adapters that know the ID of a delivery set
+// it on the message, it is used instead.
+func IdempotencyKey(hookMessage models.HookMessage) string {
+	if hookMessage.DeliveryID != "" {
+		return "delivery:" + hookMessage.DeliveryID
+	}
+
+	alerts := make([]string, 0, len(hookMessage.Alerts))
+	for _, alert := range hookMessage.Alerts {
+		alerts = append(alerts, alert.StableFingerprint()+"\x00"+alert.StatusOr(hookMessage.Status)+"\x00"+alert.StartsAt)
+	}
+	slices.Sort(alerts)
+
+	hash := sha256.New()
+	hash.Write([]byte(hookMessage.GroupKey + "\x00" + hookMessage.Status))
+	for _, alert := range alerts {
+		hash.Write([]byte("\x00" + alert))
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// SeenDelivery is an accepted delivery remembered by a DeliveryLog
+type SeenDelivery struct {
+	Key string `json:"key"`
+	// DeliveryID is the ID the delivery was accepted with, if it had one
+	DeliveryID string    `json:"deliveryId,omitempty"`
+	Expires    time.Time `json:"expires"`
+}
+
+// DeliveryLog remembers the idempotency keys of accepted deliveries for a
+// while, so re-deliveries are acknowledged without processing them again.
+// With several replicas, the log can be shared, e.g. via memberlist; sharing
+// is eventually consistent, so Jobs of deliveries with an Idempotency-Key are
+// also named after it.
+type DeliveryLog struct {
+	ttl   time.Duration
+	share func(SeenDelivery)
+
+	mu        sync.Mutex
+	seen      map[string]SeenDelivery
+	lastSweep time.Time
+}
+
+// NewDeliveryLog creates a log remembering keys for ttl
+func NewDeliveryLog(ttl time.Duration) *DeliveryLog {
+	if ttl <= 0 {
+		ttl = DefaultIdempotencyTTL
+	}
+	return &DeliveryLog{ttl: ttl, seen: make(map[string]SeenDelivery), lastSweep: time.Now()}
+}
+
+// Share sets a function receiving every accepted delivery, e.g. to broadcast
+// it to the other replicas. It must be set before the log is used.
+func (l *DeliveryLog) Share(share func(SeenDelivery)) {
+	l.share = share
+}
+
+// Claim reserves a key for a delivery. If the key was seen before, it
+// returns false and the ID of the earlier delivery.
+func (l *DeliveryLog) Claim(key string) (string, bool) {
+	now := time.Now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastSweep) >= deliveryLogSweepInterval {
+		for seenKey, seen := range l.seen {
+			if !now.Before(seen.Expires) {
+				delete(l.seen, seenKey)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	if seen, ok := l.seen[key]; ok && now.Before(seen.Expires) {
+		return seen.DeliveryID, false
+	}
+	l.seen[key] = SeenDelivery{Key: key, Expires: now.Add(l.ttl)}
+	return "", true
+}
+
+// Accept records the ID of a claimed delivery once it was accepted and
+// shares it
+func (l *DeliveryLog) Accept(key, deliveryID string) {
+	l.mu.Lock()
+	seen, ok := l.seen[key]
+	if ok {
+		seen.DeliveryID = deliveryID
+		l.seen[key] = seen
+	}
+	l.mu.Unlock()
+
+	if ok && l.share != nil {
+		l.share(seen)
+	}
+}
+
+// Forget releases a claimed key whose delivery was rejected, so a retry of
+// it is processed
+func (l *DeliveryLog) Forget(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.seen, key)
+}
+
+// Add records a delivery another replica accepted
+func (l *DeliveryLog) Add(seen SeenDelivery) {
+	if seen.Key == "" || !time.Now().Before(seen.Expires) {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if existing, ok := l.seen[seen.Key]; !ok || existing.Expires.Before(seen.Expires) {
+		l.seen[seen.Key] = seen
+	}
+}
@@ -0,0 +1,68 @@
+package ingest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/OpenFero/openfero/pkg/models"
+)
+
+func TestIdempotencyKey(t *testing.T) {
+	message := func(status string, alerts ...models.Alert) models.HookMessage {
+		return models.HookMessage{GroupKey: "{}:{alertname=\"TestAlert\"}", Status: status, Alerts: alerts}
+	}
+	web0 := models.Alert{Labels: map[string]string{"alertname": "TestAlert", "pod": "web-0"}, StartsAt: "2026-01-01T00:00:00Z"}
+	web1 := models.Alert{Labels: map[string]string{"alertname": "TestAlert", "pod": "web-1"}, StartsAt: "2026-01-01T00:01:00Z"}
+
+	key := IdempotencyKey(message("firing", web0, web1))
+	assert.Equal(t, key, IdempotencyKey(message("firing", web1, web0)), "the order of the alerts doesn't matter")
+	assert.NotEqual(t, key, IdempotencyKey(message("resolved", web0, web1)))
+	assert.NotEqual(t, key, IdempotencyKey(message("firing", web0)))
+
+	restarted := web1
+	restarted.StartsAt = "2026-01-01T01:00:00Z"
+	assert.NotEqual(t, key, IdempotencyKey(message("firing", web0, restarted)), "an alert firing again is a new delivery")
+
+	identified := message("firing", web0)
+	identified.DeliveryID = "g1a2b3c"
+	assert.Equal(t, "delivery:g1a2b3c", IdempotencyKey(identified))
+}
+
+func TestDeliveryLog(t *testing.T) {
+	var shared []SeenDelivery
+	deliveries := NewDeliveryLog(time.Minute)
+	deliveries.Share(func(seen SeenDelivery) { shared = append(shared, seen) })
+
+	_, ok := deliveries.Claim("a")
+	assert.True(t, ok)
+	id, ok := deliveries.Claim("a")
+	assert.False(t, ok, "a claimed key is a duplicate while its delivery is accepted")
+	assert.Empty(t, id)
+	assert.Empty(t, shared, "claims are only shared once accepted")
+
+	deliveries.Accept("a", "delivery-a")
+	id, ok = deliveries.Claim("a")
+	assert.False(t, ok)
+	assert.Equal(t, "delivery-a", id)
+	if assert.Len(t, shared, 1) {
+		assert.Equal(t, "a", shared[0].Key)
+		assert.Equal(t, "delivery-a", shared[0].DeliveryID)
+	}
+
+	_, ok = deliveries.Claim("b")
+	assert.True(t, ok)
+	deliveries.Forget("b")
+	_, ok = deliveries.Claim("b")
+	assert.True(t, ok, "a rejected delivery can be retried")
+
+	deliveries.Add(SeenDelivery{Key: "c", DeliveryID: "delivery-c", Expires: time.Now().Add(time.Minute)})
+	id, ok = deliveries.Claim("c")
+	assert.False(t, ok, "deliveries of other replicas are duplicates too")
+	assert.Equal(t, "delivery-c", id)
+
+	deliveries.Add(SeenDelivery{Key: "d", Expires: time.Now().Add(-time.Second)})
+	_, ok = deliveries.Claim("d")
+	assert.True(t, ok, "expired deliveries are forgotten")
+}
@@ -0,0 +1,73 @@
+package ingest
+
+import (
+	"sync"
+	"time"
+)
+
+// SeenNonce is a nonce of a signed request remembered by a NonceLog
+type SeenNonce struct {
+	Nonce   string    `json:"nonce"`
+	Expires time.Time `json:"expires"`
+}
+
+// NonceLog remembers the nonces of signed webhook requests until their
+// timestamps are outside the tolerance, so a captured request can't be
+// replayed. With several replicas, the log can be shared, e.g. via
+// memberlist; sharing is eventually consistent, a request replayed to
+// another replica before the nonce reached it is accepted once more.
+type NonceLog struct {
+	share func(SeenNonce)
+
+	mu        sync.Mutex
+	seen      map[string]time.Time
+	lastSweep time.Time
+}
+
+// NewNonceLog creates an empty nonce log
+func NewNonceLog() *NonceLog {
+	return &NonceLog{seen: make(map[string]time.Time)}
+}
+
+// Share sets a function receiving every used nonce, e.g. to broadcast it to
+// the other replicas. It must be set before the log is used.
+func (l *NonceLog) Share(share func(SeenNonce)) {
+	l.share = share
+}
+
+// Use records a nonce until expires, returning false if it was used before
+func (l *NonceLog) Use(nonce string, expires, now time.Time) bool {
+	l.mu.Lock()
+	if now.Sub(l.lastSweep) >= deliveryLogSweepInterval {
+		for seenNonce, seenExpires := range l.seen {
+			if now.After(seenExpires) {
+				delete(l.seen, seenNonce)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	if seenExpires, ok := l.seen[nonce]; ok && !now.After(seenExpires) {
+		l.mu.Unlock()
+		return false
+	}
+	l.seen[nonce] = expires
+	l.mu.Unlock()
+
+	if l.share != nil {
+		l.share(SeenNonce{Nonce: nonce, Expires: expires})
+	}
+	return true
+}
+
+// Add records a nonce another replica used
+func (l *NonceLog) Add(seen SeenNonce) {
+	if seen.Nonce == "" || !time.Now().Before(seen.Expires) {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if expires, ok := l.seen[seen.Nonce]; !ok || expires.Before(seen.Expires) {
+		l.seen[seen.Nonce] = seen.Expires
+	}
+}
@@ -0,0 +1,28 @@
+package ingest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNonceLog(t *testing.T) {
+	var shared []SeenNonce
+	nonces := NewNonceLog()
+	nonces.Share(func(seen SeenNonce) { shared = append(shared, seen) })
+	now := time.Now()
+
+	assert.True(t, nonces.Use("a", now.Add(time.Minute), now))
+	assert.False(t, nonces.Use("a", now.Add(time.Minute), now), "a nonce can only be used once")
+	if assert.Len(t, shared, 1, "only accepted nonces are shared") {
+		assert.Equal(t, "a", shared[0].Nonce)
+	}
+	assert.True(t, nonces.Use("a", now.Add(3*time.Minute), now.Add(2*time.Minute)), "expired nonces are forgotten")
+
+	nonces.Add(SeenNonce{Nonce: "b", Expires: now.Add(time.Minute)})
+	assert.False(t, nonces.Use("b", now.Add(time.Minute), now), "nonces used on other replicas are replays too")
+
+	nonces.Add(SeenNonce{Nonce: "c", Expires: now.Add(-time.Second)})
+	assert.True(t, nonces.Use("c", now.Add(time.Minute), now), "expired nonces of other replicas are ignored")
+}
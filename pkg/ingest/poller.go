@@ -0,0 +1,335 @@
+package ingest
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/OpenFero/openfero/pkg/alertstore"
+	log "github.com/OpenFero/openfero/pkg/logging"
+	"github.com/OpenFero/openfero/pkg/metadata"
+	"github.com/OpenFero/openfero/pkg/models"
+	"github.com/OpenFero/openfero/pkg/utils"
+)
+
+// APIs a Poller can read alerts from
+const (
+	// PollAlertmanager reads /api/v2/alerts of Alertmanager
+	PollAlertmanager = "alertmanager"
+	// PollPrometheus reads /api/v1/alerts of Prometheus
+	PollPrometheus = "prometheus"
+)
+
+// maxPollResponseBytes bounds the size of an alerts response
+const maxPollResponseBytes = 32 << 20
+
+// PollerConfig configures a Poller
+type PollerConfig struct {
+	// URL is the base URL of Alertmanager or Prometheus
+	URL string
+	// API is PollAlertmanager or PollPrometheus
+	API string
+	// Interval between two polls
+	Interval time.Duration
+	// Matchers filter the alerts, only matching alerts are processed
+	Matchers []*alertstore.Matcher
+	// IncludeSuppressed also processes alerts Alertmanager silenced or inhibited
+	IncludeSuppressed bool
+
+	// BearerTokenFile is read on every poll, so rotated tokens are picked up
+	BearerTokenFile string
+	// Username and PasswordFile configure basic auth
+	Username     string
+	PasswordFile string
+	// CAFile verifies the server certificate
+	CAFile string
+	// InsecureSkipVerify disables the verification of the server certificate
+	InsecureSkipVerify bool
+}
+
+// Poller reads the active alerts from Alertmanager or Prometheus, for
+// clusters where they can't reach the webhook of OpenFero.
+//
+// Each poll is diffed against the previous one: alerts that appeared are
+// handled as firing, alerts that disappeared as resolved. Every transition is
+// a hook message of its own, identified by the alert and when it started, so
+// an alert that is still firing after a restart doesn't run its Job again.
+type Poller struct {
+	cfg      PollerConfig
+	client   *http.Client
+	endpoint string
+	handler  func(ctx context.Context, hookMessage models.HookMessage)
+	now      func() time.Time
+
+	// previous holds the alerts of the last successful poll by fingerprint
+	previous map[string]models.Alert
+}
+
+// NewPoller creates a Poller handing the alert transitions to handler
+func NewPoller(cfg PollerConfig, handler func(ctx context.Context, hookMessage models.HookMessage)) (*Poller, error) {
+	base, err := url.Parse(cfg.URL)
+	if err != nil || base.Scheme == "" || base.Host == "" {
+		return nil, fmt.Errorf("invalid poll URL %q", cfg.URL)
+	}
+
+	var path string
+	query := url.Values{}
+	switch cfg.API {
+	case PollAlertmanager, "":
+		cfg.API = PollAlertmanager
+		path = "/api/v2/alerts"
+		query.Set("active", "true")
+		if !cfg.IncludeSuppressed {
+			query.Set("silenced", "false")
+			query.Set("inhibited", "false")
+		}
+		// Let Alertmanager filter as well, the matchers are applied again locally
+		for _, m := range cfg.Matchers {
+			query.Add("filter", m.String())
+		}
+	case PollPrometheus:
+		path = "/api/v1/alerts"
+	default:
+		return nil, fmt.Errorf("invalid poll API %q, expected %s or %s", cfg.API, PollAlertmanager, PollPrometheus)
+	}
+	endpoint := base.JoinPath(path)
+	endpoint.RawQuery = query.Encode()
+
+	if cfg.Interval <= 0 {
+		cfg.Interval = 30 * time.Second
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: cfg.InsecureSkipVerify, // #nosec G402 -- explicitly requested by the operator
+	}
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read poll CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in poll CA file %s", cfg.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	return &Poller{
+		cfg:      cfg,
+		client:   &http.Client{Transport: transport, Timeout: cfg.Interval},
+		endpoint: endpoint.String(),
+		handler:  handler,
+		now:      time.Now,
+	}, nil
+}
+
+// Start polls every interval until the context is canceled
+func (p *Poller) Start(ctx context.Context) {
+	log.Info("Polling alerts", "api", p.cfg.API, "url", p.cfg.URL, "interval", p.cfg.Interval)
+	go func() {
+		ticker := time.NewTicker(p.cfg.Interval)
+		defer ticker.Stop()
+		for {
+			if err := p.Poll(ctx); err != nil && ctx.Err() == nil {
+				log.Warn("Failed to poll alerts", "api", p.cfg.API, "url", p.cfg.URL, "error", err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Poll reads the active alerts once and handles the transitions since the
+// last poll. A failed poll changes nothing, alerts are not resolved because
+// the API is unavailable.
+func (p *Poller) Poll(ctx context.Context) error {
+	alerts, err := p.fetch(ctx)
+	if err != nil {
+		metadata.AlertPollsTotal.WithLabelValues(p.cfg.API, "error").Inc()
+		return err
+	}
+	metadata.AlertPollsTotal.WithLabelValues(p.cfg.API, "success").Inc()
+
+	current := make(map[string]models.Alert, len(alerts))
+	for _, alert := range alerts {
+		if alert.Labels["alertname"] == "" || !alertstore.MatchLabels(p.cfg.Matchers, alert.Labels) {
+			continue
+		}
+		alert.Fingerprint = alert.StableFingerprint()
+		alert.Status = "firing"
+		current[alert.Fingerprint] = alert
+
+		if _, ok := p.previous[alert.Fingerprint]; !ok {
+			p.submit(ctx, alert)
+		}
+	}
+
+	now := p.now().UTC().Format(time.RFC3339)
+	for fingerprint, alert := range p.previous {
+		if _, ok := current[fingerprint]; ok {
+			continue
+		}
+		alert.Status = "resolved"
+		alert.EndsAt = now
+		p.submit(ctx, alert)
+	}
+
+	p.previous = current
+	return nil
+}
+
+// submit hands a transition of an alert to the handler
+func (p *Poller) submit(ctx context.Context, alert models.Alert) {
+	source := "poll-" + p.cfg.API
+	hookMessage := models.HookMessage{
+		Version:  "4",
+		Receiver: source,
+		Status:   alert.Status,
+		Alerts:   []models.Alert{alert},
+		// A firing alert is processed once per start, also across restarts
+		DeliveryID: utils.HashGroupKey(source + "\n" + alert.Fingerprint + "\n" + alert.StartsAt + "\n" + alert.Status),
+	}
+	if err := normalize(&hookMessage, source); err != nil {
+		log.Warn("Skipping polled alert", "error", err)
+		return
+	}
+	p.handler(ctx, hookMessage)
+}
+
+// fetch reads the active alerts
+func (p *Poller) fetch(ctx context.Context) ([]models.Alert, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if err := p.authorize(req); err != nil {
+		return nil, err
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxPollResponseBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read alerts: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	if p.cfg.API == PollPrometheus {
+		return decodePrometheusAlerts(body)
+	}
+	return decodeAlertmanagerAlerts(body)
+}
+
+// authorize adds the configured credentials to a request
+func (p *Poller) authorize(req *http.Request) error {
+	if p.cfg.BearerTokenFile != "" {
+		token, err := os.ReadFile(p.cfg.BearerTokenFile)
+		if err != nil {
+			return fmt.Errorf("failed to read bearer token: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	}
+	if p.cfg.Username != "" {
+		var password []byte
+		if p.cfg.PasswordFile != "" {
+			var err error
+			if password, err = os.ReadFile(p.cfg.PasswordFile); err != nil {
+				return fmt.Errorf("failed to read password: %w", err)
+			}
+		}
+		req.SetBasicAuth(p.cfg.Username, strings.TrimSpace(string(password)))
+	}
+	return nil
+}
+
+// decodeAlertmanagerAlerts decodes the response of Alertmanager's /api/v2/alerts
+func decodeAlertmanagerAlerts(body []byte) ([]models.Alert, error) {
+	var gettable []struct {
+		Labels       map[string]string `json:"labels"`
+		Annotations  map[string]string `json:"annotations"`
+		StartsAt     string            `json:"startsAt"`
+		GeneratorURL string            `json:"generatorURL"`
+		Fingerprint  string            `json:"fingerprint"`
+	}
+	if err := json.Unmarshal(body, &gettable); err != nil {
+		return nil, fmt.Errorf("invalid Alertmanager alerts: %w", err)
+	}
+
+	alerts := make([]models.Alert, 0, len(gettable))
+	for _, a := range gettable {
+		alerts = append(alerts, models.Alert{
+			Labels:       a.Labels,
+			Annotations:  a.Annotations,
+			StartsAt:     a.StartsAt,
+			GeneratorURL: a.GeneratorURL,
+			Fingerprint:  a.Fingerprint,
+		})
+	}
+	return alerts, nil
+}
+
+// decodePrometheusAlerts decodes the response of Prometheus' /api/v1/alerts.
+// Pending alerts are left out, they don't fire yet.
+func decodePrometheusAlerts(body []byte) ([]models.Alert, error) {
+	var response struct {
+		Status string `json:"status"`
+		Error  string `json:"error"`
+		Data   struct {
+			Alerts []struct {
+				Labels      map[string]string `json:"labels"`
+				Annotations map[string]string `json:"annotations"`
+				State       string            `json:"state"`
+				ActiveAt    string            `json:"activeAt"`
+				Value       string            `json:"value"`
+			} `json:"alerts"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("invalid Prometheus alerts: %w", err)
+	}
+	if response.Status != "success" {
+		return nil, fmt.Errorf("alerts query failed: %s", response.Error)
+	}
+
+	alerts := make([]models.Alert, 0, len(response.Data.Alerts))
+	for _, a := range response.Data.Alerts {
+		if a.State != "firing" {
+			continue
+		}
+		alert := models.Alert{
+			Labels:      a.Labels,
+			Annotations: a.Annotations,
+			StartsAt:    a.ActiveAt,
+		}
+		// Keep the value of the expression available to job templates
+		if _, ok := alert.Annotations["value"]; !ok && a.Value != "" {
+			if alert.Annotations == nil {
+				alert.Annotations = map[string]string{}
+			}
+			alert.Annotations["value"] = a.Value
+		}
+		alerts = append(alerts, alert)
+	}
+	return alerts, nil
+}
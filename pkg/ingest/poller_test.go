@@ -0,0 +1,170 @@
+package ingest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/OpenFero/openfero/pkg/alertstore"
+	"github.com/OpenFero/openfero/pkg/models"
+)
+
+// alertsAPI is an httptest stand-in for the alerts API of Alertmanager or Prometheus
+type alertsAPI struct {
+	mu       sync.Mutex
+	body     string
+	status   int
+	requests []*http.Request
+}
+
+func (a *alertsAPI) set(status int, body string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.status, a.body = status, body
+}
+
+func (a *alertsAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.requests = append(a.requests, r)
+	w.WriteHeader(a.status)
+	_, _ = w.Write([]byte(a.body))
+}
+
+func (a *alertsAPI) lastRequest() *http.Request {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.requests[len(a.requests)-1]
+}
+
+func newTestPoller(t *testing.T, cfg PollerConfig) (*Poller, *alertsAPI, *[]models.HookMessage) {
+	t.Helper()
+	api := &alertsAPI{status: http.StatusOK, body: "[]"}
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+
+	var messages []models.HookMessage
+	cfg.URL = server.URL + "/alertmanager"
+	poller, err := NewPoller(cfg, func(_ context.Context, hookMessage models.HookMessage) {
+		messages = append(messages, hookMessage)
+	})
+	require.NoError(t, err)
+	return poller, api, &messages
+}
+
+func TestPoller_Alertmanager(t *testing.T) {
+	dir := t.TempDir()
+	tokenFile := filepath.Join(dir, "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("s3cret\n"), 0o600))
+	matchers, err := alertstore.ParseMatchers(`severity=~"warning|critical"`)
+	require.NoError(t, err)
+
+	poller, api, messages := newTestPoller(t, PollerConfig{
+		API:             PollAlertmanager,
+		Matchers:        matchers,
+		BearerTokenFile: tokenFile,
+	})
+	ctx := context.Background()
+
+	api.set(http.StatusOK, `[
+		{"labels": {"alertname": "DiskFull", "severity": "critical", "instance": "node-1"}, "annotations": {"summary": "Disk full"},
+		 "startsAt": "2024-05-01T10:00:00Z", "fingerprint": "aaaa000000000001", "status": {"state": "active"}},
+		{"labels": {"alertname": "Watchdog", "severity": "none"}, "startsAt": "2024-05-01T09:00:00Z", "fingerprint": "aaaa000000000002"}
+	]`)
+	require.NoError(t, poller.Poll(ctx))
+
+	request := api.lastRequest()
+	assert.Equal(t, "/alertmanager/api/v2/alerts", request.URL.Path)
+	assert.Equal(t, "Bearer s3cret", request.Header.Get("Authorization"))
+	assert.Equal(t, "false", request.URL.Query().Get("silenced"))
+	assert.Equal(t, []string{`severity=~"warning|critical"`}, request.URL.Query()["filter"])
+
+	require.Len(t, *messages, 1, "the filtered out alert is skipped")
+	firing := (*messages)[0]
+	assert.Equal(t, "firing", firing.Status)
+	assert.Equal(t, "DiskFull", firing.Alerts[0].Labels["alertname"])
+	assert.Equal(t, "aaaa000000000001", firing.Alerts[0].Fingerprint)
+	assert.Equal(t, "Disk full", firing.CommonAnnotations["summary"])
+	assert.NotEmpty(t, firing.GroupKey)
+	assert.NotEmpty(t, firing.DeliveryID)
+
+	// An unchanged alert isn't handled again
+	require.NoError(t, poller.Poll(ctx))
+	assert.Len(t, *messages, 1)
+
+	// A failing API doesn't resolve anything
+	api.set(http.StatusServiceUnavailable, "unavailable")
+	assert.ErrorContains(t, poller.Poll(ctx), "503")
+	assert.Len(t, *messages, 1)
+
+	api.set(http.StatusOK, `[]`)
+	require.NoError(t, poller.Poll(ctx))
+	require.Len(t, *messages, 2)
+	resolved := (*messages)[1]
+	assert.Equal(t, "resolved", resolved.Status)
+	assert.Equal(t, firing.GroupKey, resolved.GroupKey)
+	assert.NotEmpty(t, resolved.Alerts[0].EndsAt)
+	assert.NotEqual(t, firing.DeliveryID, resolved.DeliveryID)
+}
+
+func TestPoller_Prometheus(t *testing.T) {
+	poller, api, messages := newTestPoller(t, PollerConfig{
+		API:      PollPrometheus,
+		Username: "openfero",
+	})
+
+	api.set(http.StatusOK, `{"status": "success", "data": {"alerts": [
+		{"labels": {"alertname": "HighLatency", "service": "api"}, "annotations": {}, "state": "firing",
+		 "activeAt": "2024-05-01T10:00:00Z", "value": "1.5e+00"},
+		{"labels": {"alertname": "HighErrorRate"}, "state": "pending", "activeAt": "2024-05-01T10:01:00Z"}
+	]}}`)
+	require.NoError(t, poller.Poll(context.Background()))
+
+	request := api.lastRequest()
+	assert.Equal(t, "/alertmanager/api/v1/alerts", request.URL.Path)
+	username, _, ok := request.BasicAuth()
+	assert.True(t, ok)
+	assert.Equal(t, "openfero", username)
+
+	require.Len(t, *messages, 1, "pending alerts don't fire yet")
+	alert := (*messages)[0].Alerts[0]
+	assert.Equal(t, "HighLatency", alert.Labels["alertname"])
+	assert.Equal(t, "1.5e+00", alert.Annotations["value"])
+	assert.Equal(t, "2024-05-01T10:00:00Z", alert.StartsAt)
+	assert.NotEmpty(t, alert.Fingerprint, "computed from the labels")
+
+	api.set(http.StatusOK, `{"status": "error", "error": "query timed out"}`)
+	assert.ErrorContains(t, poller.Poll(context.Background()), "query timed out")
+}
+
+func TestPoller_SameDeliveryAfterRestart(t *testing.T) {
+	body := `[{"labels": {"alertname": "DiskFull"}, "startsAt": "2024-05-01T10:00:00Z"}]`
+	first, api, firstMessages := newTestPoller(t, PollerConfig{})
+	api.set(http.StatusOK, body)
+	require.NoError(t, first.Poll(context.Background()))
+
+	second, api, secondMessages := newTestPoller(t, PollerConfig{})
+	api.set(http.StatusOK, body)
+	require.NoError(t, second.Poll(context.Background()))
+
+	require.Len(t, *firstMessages, 1)
+	require.Len(t, *secondMessages, 1)
+	assert.Equal(t, (*firstMessages)[0].DeliveryID, (*secondMessages)[0].DeliveryID)
+}
+
+func TestNewPoller_Invalid(t *testing.T) {
+	handler := func(context.Context, models.HookMessage) {}
+	_, err := NewPoller(PollerConfig{URL: "alertmanager:9093"}, handler)
+	assert.Error(t, err)
+	_, err = NewPoller(PollerConfig{URL: "http://alertmanager:9093", API: "thanos"}, handler)
+	assert.Error(t, err)
+	_, err = NewPoller(PollerConfig{URL: "http://alertmanager:9093", CAFile: "/does/not/exist"}, handler)
+	assert.Error(t, err)
+}
@@ -0,0 +1,282 @@
+// Package ingest decouples accepting webhook messages from processing them.
+package ingest
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	log "github.com/OpenFero/openfero/pkg/logging"
+	"github.com/OpenFero/openfero/pkg/metadata"
+	"github.com/OpenFero/openfero/pkg/models"
+)
+
+var (
+	// ErrQueueFull is returned by Enqueue when no capacity is left
+	ErrQueueFull = errors.New("webhook queue is full")
+	// ErrQueueClosed is returned by Enqueue once the queue is shutting down
+	ErrQueueClosed = errors.New("webhook queue is closed")
+)
+
+// replayRetryInterval is how often Replay retries while the queue is full
+const replayRetryInterval = 10 * time.Millisecond
+
+// Handler processes a single accepted hook message. If it returns an error,
+// the message is not acknowledged in the write-ahead log and is replayed on
+// the next start.
+type Handler func(ctx context.Context, hookMessage models.HookMessage) error
+
+// item is a queued hook message
+type item struct {
+	record   Record
+	enqueued time.Time
+}
+
+// Queue is a bounded queue of hook messages processed by a pool of workers
+type Queue struct {
+	items   chan item
+	handler Handler
+	workers int
+	wal     *WAL
+
+	mu     sync.Mutex
+	closed bool
+	// reserved counts the slots held by Enqueue calls writing to the log
+	reserved int
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+// NewQueue creates a queue holding up to size messages that are processed by
+// the given number of workers once the queue is started
+func NewQueue(size, workers int, handler Handler) *Queue {
+	if size < 1 {
+		size = 1
+	}
+	if workers < 1 {
+		workers = 1
+	}
+	metadata.WebhookQueueCapacity.Set(float64(size))
+	return &Queue{
+		items:   make(chan item, size),
+		handler: handler,
+		workers: workers,
+	}
+}
+
+// SetWAL makes the queue record accepted messages in a write-ahead log
+// before acknowledging them. It must be called before the queue is started.
+func (q *Queue) SetWAL(wal *WAL) {
+	q.wal = wal
+}
+
+// Start starts the workers. Messages are processed with a context derived
+// from ctx, which is cancelled when Shutdown gives up waiting.
+func (q *Queue) Start(ctx context.Context) {
+	ctx, q.cancel = context.WithCancel(ctx)
+	for range q.workers {
+		q.wg.Add(1)
+		go q.work(ctx)
+	}
+	log.Info("Webhook queue started", "workers", q.workers, "capacity", cap(q.items), "wal", q.wal != nil)
+}
+
+// Replay queues the messages the write-ahead log holds from a previous run.
+// It blocks until all of them are queued and should be called after Start.
+// While the backlog fills the queue, Enqueue rejects new messages as full
+// and Shutdown stops the replay; the rest stays in the log.
+func (q *Queue) Replay(ctx context.Context) error {
+	if q.wal == nil {
+		return nil
+	}
+
+	records := q.wal.Pending()
+	if len(records) == 0 {
+		return nil
+	}
+	log.Info("Replaying unprocessed webhook messages", "count", len(records))
+
+	for _, record := range records {
+		for {
+			queued, err := q.tryReplay(record)
+			if err != nil {
+				return err
+			}
+			if queued {
+				break
+			}
+			// Wait for the workers to make room without holding the lock
+			select {
+			case <-time.After(replayRetryInterval):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+	return nil
+}
+
+// tryReplay queues a replayed record if there is room. The send must not
+// block, Enqueue and Shutdown wait for the lock.
+func (q *Queue) tryReplay(record Record) (bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return false, ErrQueueClosed
+	}
+	if len(q.items)+q.reserved == cap(q.items) {
+		return false, nil
+	}
+	select {
+	case q.items <- item{record: record, enqueued: time.Now()}:
+		metadata.WebhookReplayedTotal.Inc()
+		metadata.WebhookQueueDepth.Set(float64(len(q.items)))
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// Enqueue adds a hook message without blocking and returns its delivery ID.
+// If a write-ahead log is set, the message is durably recorded before
+// Enqueue returns.
+func (q *Queue) Enqueue(hookMessage models.HookMessage) (string, error) {
+	record := Record{
+		ID:          hookMessage.DeliveryID,
+		AcceptedAt:  time.Now(),
+		HookMessage: hookMessage,
+		Credential:  hookMessage.Credential,
+	}
+	if record.ID == "" {
+		id, err := newDeliveryID()
+		if err != nil {
+			return "", err
+		}
+		record.ID = id
+	}
+
+	if err := q.reserve(); err != nil {
+		return "", err
+	}
+	// The log is synced outside the lock, so concurrent requests don't wait
+	// for each other's fsync
+	if q.wal != nil {
+		if err := q.wal.Append(record); err != nil {
+			q.mu.Lock()
+			q.reserved--
+			q.mu.Unlock()
+			return "", err
+		}
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.reserved--
+	if q.closed {
+		// Shut down while the log was synced, the message is replayed on the
+		// next start
+		if q.wal != nil {
+			return record.ID, nil
+		}
+		return "", ErrQueueClosed
+	}
+	q.items <- item{record: record, enqueued: record.AcceptedAt}
+	metadata.WebhookQueueDepth.Set(float64(len(q.items)))
+	return record.ID, nil
+}
+
+// reserve holds a slot of the queue for a message. Only Enqueue and Replay
+// add items and both take the reserved slots into account, so the message
+// can be sent without blocking once it is reserved.
+func (q *Queue) reserve() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return ErrQueueClosed
+	}
+	if len(q.items)+q.reserved == cap(q.items) {
+		return ErrQueueFull
+	}
+	q.reserved++
+	return nil
+}
+
+// Len returns the number of queued messages
+func (q *Queue) Len() int {
+	return len(q.items)
+}
+
+// Shutdown stops accepting messages and waits until the queued messages are
+// processed. If ctx expires first, in-flight processing is cancelled; with a
+// write-ahead log the unprocessed messages are replayed on the next start.
+func (q *Queue) Shutdown(ctx context.Context) error {
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.items)
+	}
+	q.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		if q.cancel != nil {
+			q.cancel()
+		}
+		log.Warn("Webhook queue shutdown timed out", "pending", len(q.items))
+		return ctx.Err()
+	}
+}
+
+// work processes queued messages until the queue is closed and drained
+func (q *Queue) work(ctx context.Context) {
+	defer q.wg.Done()
+	for queued := range q.items {
+		if ctx.Err() != nil {
+			// Shutting down, leave the message in the log for the next start
+			continue
+		}
+		metadata.WebhookQueueDepth.Set(float64(len(q.items)))
+		metadata.WebhookQueueWaitSeconds.Observe(time.Since(queued.enqueued).Seconds())
+
+		hookMessage := queued.record.HookMessage
+		hookMessage.DeliveryID = queued.record.ID
+		hookMessage.ReceivedAt = queued.record.AcceptedAt
+		hookMessage.Credential = queued.record.Credential
+
+		start := time.Now()
+		err := q.handler(ctx, hookMessage)
+		metadata.WebhookProcessingSeconds.Observe(time.Since(start).Seconds())
+		if err != nil {
+			// Keep the message in the log, it is retried on the next start
+			log.Warn("Failed to process webhook message", "deliveryId", queued.record.ID, "wal", q.wal != nil, "error", err)
+			continue
+		}
+
+		if q.wal != nil && ctx.Err() == nil {
+			if err := q.wal.Ack(queued.record.ID); err != nil {
+				log.Error("Failed to acknowledge webhook message in WAL", "deliveryId", queued.record.ID, "error", err)
+			}
+		}
+	}
+}
+
+// newDeliveryID returns a random identifier for an accepted delivery
+func newDeliveryID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate delivery ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
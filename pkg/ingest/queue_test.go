@@ -0,0 +1,85 @@
+package ingest
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/OpenFero/openfero/pkg/models"
+)
+
+func TestQueue_ProcessesMessages(t *testing.T) {
+	var mu sync.Mutex
+	var processed []string
+	queue := NewQueue(10, 2, func(_ context.Context, hookMessage models.HookMessage) error {
+		mu.Lock()
+		defer mu.Unlock()
+		processed = append(processed, hookMessage.GroupKey)
+		return nil
+	})
+	queue.Start(context.Background())
+
+	ids := make(map[string]bool)
+	for _, groupKey := range []string{"a", "b", "c"} {
+		id, err := queue.Enqueue(models.HookMessage{GroupKey: groupKey})
+		require.NoError(t, err)
+		ids[id] = true
+	}
+	require.NoError(t, queue.Shutdown(context.Background()))
+
+	assert.ElementsMatch(t, []string{"a", "b", "c"}, processed)
+	assert.Len(t, ids, 3, "every delivery gets its own ID")
+	_, err := queue.Enqueue(models.HookMessage{})
+	assert.ErrorIs(t, err, ErrQueueClosed)
+}
+
+func TestQueue_Backpressure(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{}, 1)
+	queue := NewQueue(1, 1, func(context.Context, models.HookMessage) error {
+		started <- struct{}{}
+		<-release
+		return nil
+	})
+	queue.Start(context.Background())
+
+	// The worker blocks on the first message, the second fills the queue
+	_, err := queue.Enqueue(models.HookMessage{GroupKey: "in-flight"})
+	require.NoError(t, err)
+	<-started
+	_, err = queue.Enqueue(models.HookMessage{GroupKey: "queued"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, queue.Len())
+	_, err = queue.Enqueue(models.HookMessage{GroupKey: "rejected"})
+	assert.ErrorIs(t, err, ErrQueueFull)
+
+	close(release)
+	require.NoError(t, queue.Shutdown(context.Background()))
+	assert.Zero(t, queue.Len())
+}
+
+func TestQueue_ShutdownTimeoutCancelsProcessing(t *testing.T) {
+	cancelled := make(chan struct{})
+	queue := NewQueue(1, 1, func(ctx context.Context, _ models.HookMessage) error {
+		<-ctx.Done()
+		close(cancelled)
+		return ctx.Err()
+	})
+	queue.Start(context.Background())
+	_, err := queue.Enqueue(models.HookMessage{})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, queue.Shutdown(ctx), context.DeadlineExceeded)
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("processing was not cancelled")
+	}
+}
@@ -0,0 +1,245 @@
+package ingest
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	log "github.com/OpenFero/openfero/pkg/logging"
+	"github.com/OpenFero/openfero/pkg/models"
+)
+
+const (
+	walOpAppend = "append"
+	walOpAck    = "ack"
+
+	// walCompactEvery is the number of acknowledged records after which the
+	// log is rewritten with only the pending records
+	walCompactEvery = 1024
+
+	// maxWALLineSize bounds a single log record
+	maxWALLineSize = 16 * 1024 * 1024
+)
+
+// Record is an accepted hook message in the write-ahead log
+type Record struct {
+	ID          string             `json:"id"`
+	AcceptedAt  time.Time          `json:"acceptedAt"`
+	HookMessage models.HookMessage `json:"message"`
+	Credential  string             `json:"credential,omitempty"`
+}
+
+// walEntry is a single line of the log file
+type walEntry struct {
+	Op     string  `json:"op"`
+	ID     string  `json:"id,omitempty"`
+	Record *Record `json:"record,omitempty"`
+}
+
+// WAL is a write-ahead log of accepted hook messages in a local file. Each
+// message is synced to disk before it is acknowledged to the sender and
+// marked as done once it has been processed, so messages that were accepted
+// but not processed before a restart can be replayed.
+type WAL struct {
+	mu      sync.Mutex
+	path    string
+	file    *os.File
+	pending map[string]Record
+	acked   int
+}
+
+// OpenWAL opens or creates the log at path. Records that were appended but
+// never acknowledged are kept as pending.
+func OpenWAL(path string) (*WAL, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create WAL directory: %w", err)
+	}
+
+	pending, err := readWAL(path)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &WAL{path: path, pending: pending}
+	// Start from a compacted log, this also drops a partially written last line
+	if err := w.rewrite(); err != nil {
+		return nil, err
+	}
+	if len(pending) > 0 {
+		log.Info("Found unprocessed webhook messages in WAL", "path", path, "pending", len(pending))
+	}
+	return w, nil
+}
+
+// Pending returns the records that have not been acknowledged, oldest first
+func (w *WAL) Pending() []Record {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.sortedPending()
+}
+
+// Append durably records an accepted hook message
+func (w *WAL) Append(record Record) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := w.write(walEntry{Op: walOpAppend, Record: &record}); err != nil {
+		return err
+	}
+	if err := w.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync WAL: %w", err)
+	}
+	w.pending[record.ID] = record
+	return nil
+}
+
+// Ack marks a record as processed. It is not synced, replaying a processed
+// message is harmless since processing is idempotent.
+func (w *WAL) Ack(id string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, ok := w.pending[id]; !ok {
+		return nil
+	}
+	delete(w.pending, id)
+	w.acked++
+
+	if len(w.pending) == 0 || w.acked >= walCompactEvery {
+		return w.rewrite()
+	}
+	return w.write(walEntry{Op: walOpAck, ID: id})
+}
+
+// Close closes the log file
+func (w *WAL) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+// write appends a line to the log file
+func (w *WAL) write(entry walEntry) error {
+	if w.file == nil {
+		return errors.New("WAL is closed")
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode WAL record: %w", err)
+	}
+	if _, err := w.file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write WAL: %w", err)
+	}
+	return nil
+}
+
+// rewrite atomically replaces the log file with one holding only the pending records
+func (w *WAL) rewrite() error {
+	tmpPath := w.path + ".tmp"
+	tmp, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to compact WAL: %w", err)
+	}
+
+	writer := bufio.NewWriter(tmp)
+	encoder := json.NewEncoder(writer)
+	for _, record := range w.sortedPending() {
+		if err := encoder.Encode(walEntry{Op: walOpAppend, Record: &record}); err != nil {
+			_ = tmp.Close()
+			return fmt.Errorf("failed to compact WAL: %w", err)
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to compact WAL: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to compact WAL: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to compact WAL: %w", err)
+	}
+
+	if w.file != nil {
+		_ = w.file.Close()
+		w.file = nil
+	}
+	if err := os.Rename(tmpPath, w.path); err != nil {
+		return fmt.Errorf("failed to compact WAL: %w", err)
+	}
+
+	file, err := os.OpenFile(w.path, os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open WAL: %w", err)
+	}
+	w.file = file
+	w.acked = 0
+	return nil
+}
+
+// sortedPending returns the pending records in the order they were accepted
+func (w *WAL) sortedPending() []Record {
+	records := make([]Record, 0, len(w.pending))
+	for _, record := range w.pending {
+		records = append(records, record)
+	}
+	slices.SortFunc(records, func(a, b Record) int {
+		return a.AcceptedAt.Compare(b.AcceptedAt)
+	})
+	return records
+}
+
+// readWAL returns the unacknowledged records of an existing log file
+func readWAL(path string) (map[string]Record, error) {
+	pending := make(map[string]Record)
+
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return pending, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open WAL: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxWALLineSize)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var entry walEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			// A crash can leave a partially written last line behind
+			log.Warn("Ignoring corrupt WAL record", "path", path, "line", line, "error", err)
+			continue
+		}
+		switch entry.Op {
+		case walOpAppend:
+			if entry.Record != nil {
+				pending[entry.Record.ID] = *entry.Record
+			}
+		case walOpAck:
+			delete(pending, entry.ID)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read WAL: %w", err)
+	}
+	return pending, nil
+}
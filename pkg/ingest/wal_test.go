@@ -0,0 +1,225 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/OpenFero/openfero/pkg/models"
+)
+
+func TestWAL_PendingSurvivesReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "wal", "webhooks.log")
+
+	wal, err := OpenWAL(path)
+	require.NoError(t, err)
+	assert.Empty(t, wal.Pending())
+
+	start := time.Now()
+	for i, id := range []string{"a", "b", "c"} {
+		require.NoError(t, wal.Append(Record{
+			ID:          id,
+			AcceptedAt:  start.Add(time.Duration(i) * time.Second),
+			HookMessage: models.HookMessage{GroupKey: "group-" + id, Status: "firing"},
+		}))
+	}
+	require.NoError(t, wal.Ack("b"))
+	require.NoError(t, wal.Close())
+
+	// Simulate a crash in the middle of writing a record
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	require.NoError(t, err)
+	_, err = file.WriteString(`{"op":"append","record":{"id":"d"`)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	wal, err = OpenWAL(path)
+	require.NoError(t, err)
+	defer func() { _ = wal.Close() }()
+
+	pending := wal.Pending()
+	require.Len(t, pending, 2)
+	assert.Equal(t, "a", pending[0].ID)
+	assert.Equal(t, "group-a", pending[0].HookMessage.GroupKey)
+	assert.Equal(t, "c", pending[1].ID)
+}
+
+func TestWAL_CompactsWhenDrained(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "webhooks.log")
+	wal, err := OpenWAL(path)
+	require.NoError(t, err)
+	defer func() { _ = wal.Close() }()
+
+	require.NoError(t, wal.Append(Record{ID: "a", AcceptedAt: time.Now()}))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.NotZero(t, info.Size())
+
+	require.NoError(t, wal.Ack("a"))
+	info, err = os.Stat(path)
+	require.NoError(t, err)
+	assert.Zero(t, info.Size())
+
+	// Appending after compaction still works
+	require.NoError(t, wal.Append(Record{ID: "b", AcceptedAt: time.Now()}))
+	assert.Len(t, wal.Pending(), 1)
+}
+
+func TestQueue_ReplaysUnprocessedMessages(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "webhooks.log")
+
+	// The first run accepts messages but is stopped before processing them
+	wal, err := OpenWAL(path)
+	require.NoError(t, err)
+	queue := NewQueue(10, 1, func(context.Context, models.HookMessage) error {
+		t.Error("the first run must not process messages")
+		return nil
+	})
+	queue.SetWAL(wal)
+	_, err = queue.Enqueue(models.HookMessage{GroupKey: "a"})
+	require.NoError(t, err)
+	_, err = queue.Enqueue(models.HookMessage{GroupKey: "b", DeliveryID: "given-id"})
+	require.NoError(t, err)
+	require.NoError(t, wal.Close())
+
+	// The second run replays them with their original delivery
+	wal, err = OpenWAL(path)
+	require.NoError(t, err)
+	defer func() { _ = wal.Close() }()
+
+	var mu sync.Mutex
+	processed := make(map[string]models.HookMessage)
+	queue = NewQueue(1, 1, func(_ context.Context, hookMessage models.HookMessage) error {
+		mu.Lock()
+		defer mu.Unlock()
+		processed[hookMessage.GroupKey] = hookMessage
+		return nil
+	})
+	queue.SetWAL(wal)
+	queue.Start(context.Background())
+	require.NoError(t, queue.Replay(context.Background()))
+	require.NoError(t, queue.Shutdown(context.Background()))
+
+	require.Len(t, processed, 2)
+	assert.NotEmpty(t, processed["a"].DeliveryID)
+	assert.False(t, processed["a"].ReceivedAt.IsZero())
+	assert.Equal(t, "given-id", processed["b"].DeliveryID)
+	assert.Empty(t, wal.Pending(), "processed messages must be acknowledged")
+}
+
+func TestQueue_ReplayDoesNotBlockEnqueueOrShutdown(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "webhooks.log")
+	wal, err := OpenWAL(path)
+	require.NoError(t, err)
+	backlog := NewQueue(10, 1, func(context.Context, models.HookMessage) error { return nil })
+	backlog.SetWAL(wal)
+	for _, groupKey := range []string{"a", "b", "c", "d"} {
+		_, err = backlog.Enqueue(models.HookMessage{GroupKey: groupKey})
+		require.NoError(t, err)
+	}
+	require.NoError(t, wal.Close())
+
+	wal, err = OpenWAL(path)
+	require.NoError(t, err)
+	defer func() { _ = wal.Close() }()
+	release := make(chan struct{})
+	queue := NewQueue(1, 1, func(context.Context, models.HookMessage) error { <-release; return nil })
+	queue.SetWAL(wal)
+	queue.Start(context.Background())
+
+	// The backlog is larger than the queue, so the replay waits for room
+	replayed := make(chan error, 1)
+	go func() { replayed <- queue.Replay(context.Background()) }()
+	require.Eventually(t, func() bool { return queue.Len() == 1 }, time.Second, time.Millisecond)
+
+	enqueued := make(chan error, 1)
+	go func() {
+		_, err := queue.Enqueue(models.HookMessage{GroupKey: "new"})
+		enqueued <- err
+	}()
+	select {
+	case err := <-enqueued:
+		assert.ErrorIs(t, err, ErrQueueFull)
+	case <-time.After(time.Second):
+		t.Fatal("Enqueue blocked while the backlog was replayed")
+	}
+
+	shutdown := make(chan error, 1)
+	go func() { shutdown <- queue.Shutdown(context.Background()) }()
+	select {
+	case err := <-replayed:
+		assert.ErrorIs(t, err, ErrQueueClosed)
+	case <-time.After(time.Second):
+		t.Fatal("Replay did not stop when the queue was shut down")
+	}
+
+	close(release)
+	require.NoError(t, <-shutdown)
+	assert.NotEmpty(t, wal.Pending(), "messages that weren't replayed stay in the log")
+}
+
+func TestQueue_KeepsFailedMessages(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "webhooks.log")
+	wal, err := OpenWAL(path)
+	require.NoError(t, err)
+	defer func() { _ = wal.Close() }()
+
+	queue := NewQueue(10, 1, func(_ context.Context, hookMessage models.HookMessage) error {
+		if hookMessage.GroupKey == "failing" {
+			return errors.New("failed to create job")
+		}
+		return nil
+	})
+	queue.SetWAL(wal)
+	queue.Start(context.Background())
+	_, err = queue.Enqueue(models.HookMessage{GroupKey: "failing"})
+	require.NoError(t, err)
+	_, err = queue.Enqueue(models.HookMessage{GroupKey: "processed"})
+	require.NoError(t, err)
+	require.NoError(t, queue.Shutdown(context.Background()))
+
+	pending := wal.Pending()
+	require.Len(t, pending, 1, "a message that failed must be replayed on the next start")
+	assert.Equal(t, "failing", pending[0].HookMessage.GroupKey)
+}
+
+func TestQueue_ConcurrentEnqueueWithWAL(t *testing.T) {
+	wal, err := OpenWAL(filepath.Join(t.TempDir(), "webhooks.log"))
+	require.NoError(t, err)
+	defer func() { _ = wal.Close() }()
+
+	// Nothing is processed, so exactly the capacity is accepted
+	queue := NewQueue(5, 1, func(context.Context, models.HookMessage) error { return nil })
+	queue.SetWAL(wal)
+
+	var mu sync.Mutex
+	accepted, full := 0, 0
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := queue.Enqueue(models.HookMessage{})
+			mu.Lock()
+			defer mu.Unlock()
+			if errors.Is(err, ErrQueueFull) {
+				full++
+			} else if assert.NoError(t, err) {
+				accepted++
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 5, accepted)
+	assert.Equal(t, 15, full)
+	assert.Equal(t, 5, queue.Len())
+	assert.Len(t, wal.Pending(), 5, "rejected messages must not be logged")
+}
@@ -9,6 +9,7 @@ import (
 	log "github.com/OpenFero/openfero/pkg/logging"
 	"github.com/OpenFero/openfero/pkg/metadata"
 	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
@@ -151,3 +152,95 @@ func InitJobInformer(clientset *kubernetes.Clientset, jobDestinationNamespace st
 
 	return jobInformer.GetStore()
 }
+
+// InitPodInformer initializes a Pod informer for the pods of remediation Jobs.
+// The callback is invoked whenever such a pod is added or its status changes.
+func InitPodInformer(clientset *kubernetes.Clientset, jobDestinationNamespace string, labelSelector *metav1.LabelSelector, updateFunc func(pod *corev1.Pod)) cache.Store {
+	podFactory := informers.NewSharedInformerFactoryWithOptions(
+		clientset,
+		time.Hour*1,
+		informers.WithNamespace(jobDestinationNamespace),
+		informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.LabelSelector = metav1.FormatLabelSelector(labelSelector)
+		}),
+	)
+
+	log.Debug("Initializing Pod informer",
+		"namespace", jobDestinationNamespace,
+		"labelSelector", metav1.FormatLabelSelector(labelSelector))
+
+	podInformer := podFactory.Core().V1().Pods().Informer()
+
+	if _, err := podInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj any) {
+			pod := obj.(*corev1.Pod)
+			if updateFunc != nil {
+				updateFunc(pod)
+			}
+		},
+		UpdateFunc: func(old, new any) {
+			oldPod := old.(*corev1.Pod)
+			newPod := new.(*corev1.Pod)
+			// Skip periodic resyncs, only status changes are of interest
+			if oldPod.ResourceVersion == newPod.ResourceVersion {
+				return
+			}
+			if updateFunc != nil {
+				updateFunc(newPod)
+			}
+		},
+	}); err != nil {
+		log.Fatal("Failed to add Pod event handler", "error", err)
+	}
+
+	go podFactory.Start(context.Background().Done())
+
+	if !cache.WaitForCacheSync(context.Background().Done(), podInformer.HasSynced) {
+		log.Fatal("Failed to sync Pod cache", "namespace", jobDestinationNamespace)
+	}
+	log.Info("Pod cache synced", "namespace", jobDestinationNamespace)
+
+	return podInformer.GetStore()
+}
+
+// LegacyJobNameLabel is the label naming the Job of a pod on clusters before
+// Kubernetes 1.27, which don't set batchv1.JobNameLabel
+const LegacyJobNameLabel = "job-name"
+
+// JobNameForPod returns the name of the Job that owns the given pod, or an
+// empty string if the pod was not created by a Job
+func JobNameForPod(pod *corev1.Pod) string {
+	if name, ok := pod.Labels[batchv1.JobNameLabel]; ok {
+		return name
+	}
+	// Clusters before Kubernetes 1.27 only set the legacy label
+	if name, ok := pod.Labels[LegacyJobNameLabel]; ok {
+		return name
+	}
+	for _, ref := range pod.OwnerReferences {
+		if ref.Kind == "Job" {
+			return ref.Name
+		}
+	}
+	return ""
+}
+
+// IsJobFinished reports whether a Job has completed or failed for good
+func IsJobFinished(job *batchv1.Job) bool {
+	for _, condition := range job.Status.Conditions {
+		if (condition.Type == batchv1.JobComplete || condition.Type == batchv1.JobFailed) && condition.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// IsJobFailed reports whether a Job has failed for good
+func IsJobFailed(job *batchv1.Job) bool {
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == batchv1.JobFailed && condition.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
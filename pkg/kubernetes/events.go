@@ -0,0 +1,403 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+
+	log "github.com/OpenFero/openfero/pkg/logging"
+	"github.com/OpenFero/openfero/pkg/metadata"
+	"github.com/OpenFero/openfero/pkg/models"
+	"github.com/OpenFero/openfero/pkg/utils"
+)
+
+// NodeNotReadyReason is the reason of the alerts raised for nodes that aren't ready
+const NodeNotReadyReason = "NodeNotReady"
+
+// managedByLabel marks the Jobs and pods OpenFero created. No alerts are
+// raised for them, a failing remediation must not trigger another one.
+const managedByLabel = "openfero.io/managed-by"
+
+// maxTrackedAlerts is the number of alerts remembered for the cooldown
+// before expired ones are pruned
+const maxTrackedAlerts = 1024
+
+// DefaultEventReasons are the reasons the event watcher raises alerts for by default
+var DefaultEventReasons = []string{"FailedScheduling", "CrashLoopBackOff", "ImagePullBackOff", "Evicted", NodeNotReadyReason}
+
+// EventWatcherConfig configures the EventWatcher
+type EventWatcherConfig struct {
+	// Namespace to watch, all namespaces if empty. Nodes are only watched
+	// cluster wide.
+	Namespace string
+	// Reasons to raise alerts for. They are matched against the reason of
+	// Warning events, the waiting reason of pod containers and NodeNotReady.
+	Reasons []string
+	// Cooldown is the minimum time between two firing alerts for the same
+	// object and reason
+	Cooldown time.Duration
+	// RatePerMinute limits the alerts raised in total, Burst allows short spikes
+	RatePerMinute float64
+	Burst         int
+}
+
+// EventWatcher turns Kubernetes Events and pod and node conditions into
+// synthetic alerts, so remediation can start before a Prometheus alert fires.
+//
+// Events are point-in-time, so only firing alerts are raised for them. Pod
+// and node conditions are state; their alerts resolve once the condition clears.
+type EventWatcher struct {
+	client  kubernetes.Interface
+	config  EventWatcherConfig
+	handler func(ctx context.Context, hookMessage models.HookMessage)
+	reasons map[string]bool
+	limiter *rate.Limiter
+	started time.Time
+	pods    cache.Indexer // pods watched, to find the pod of an event
+
+	mu     sync.Mutex
+	last   map[string]time.Time // last firing alert by key
+	active map[string]models.Alert
+}
+
+// NewEventWatcher creates a watcher passing the synthetic alerts to handler
+func NewEventWatcher(client kubernetes.Interface, config EventWatcherConfig, handler func(ctx context.Context, hookMessage models.HookMessage)) *EventWatcher {
+	if len(config.Reasons) == 0 {
+		config.Reasons = DefaultEventReasons
+	}
+	if config.Burst < 1 {
+		config.Burst = 1
+	}
+	limit := rate.Inf
+	if config.RatePerMinute > 0 {
+		limit = rate.Limit(config.RatePerMinute / 60)
+	}
+
+	reasons := make(map[string]bool, len(config.Reasons))
+	for _, reason := range config.Reasons {
+		reasons[reason] = true
+	}
+	return &EventWatcher{
+		client:  client,
+		config:  config,
+		handler: handler,
+		reasons: reasons,
+		limiter: rate.NewLimiter(limit, config.Burst),
+		last:    make(map[string]time.Time),
+		active:  make(map[string]models.Alert),
+	}
+}
+
+// Start starts the informers and waits for their caches to sync. Events
+// that happened before the watcher started are ignored.
+func (w *EventWatcher) Start(ctx context.Context) error {
+	w.started = time.Now()
+
+	options := []informers.SharedInformerOption{}
+	if w.config.Namespace != "" {
+		options = append(options, informers.WithNamespace(w.config.Namespace))
+	}
+	factory := informers.NewSharedInformerFactoryWithOptions(w.client, time.Hour, options...)
+
+	synced := []cache.InformerSynced{}
+	add := func(informer cache.SharedIndexInformer, handle func(obj any), remove func(obj any)) error {
+		if _, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc:    handle,
+			UpdateFunc: func(_, obj any) { handle(obj) },
+			DeleteFunc: func(obj any) {
+				if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+					obj = tombstone.Obj
+				}
+				if remove != nil {
+					remove(obj)
+				}
+			},
+		}); err != nil {
+			return err
+		}
+		synced = append(synced, informer.HasSynced)
+		return nil
+	}
+
+	if err := add(factory.Core().V1().Events().Informer(), func(obj any) {
+		if event, ok := obj.(*corev1.Event); ok {
+			w.onEvent(ctx, event)
+		}
+	}, nil); err != nil {
+		return fmt.Errorf("failed to watch events: %w", err)
+	}
+	podInformer := factory.Core().V1().Pods().Informer()
+	w.pods = podInformer.GetIndexer()
+	if err := add(podInformer, func(obj any) {
+		if pod, ok := obj.(*corev1.Pod); ok {
+			w.onPod(ctx, pod)
+		}
+	}, func(obj any) {
+		if pod, ok := obj.(*corev1.Pod); ok {
+			w.resolveAll(ctx, "Pod", pod.Namespace, pod.Name, pod.UID)
+		}
+	}); err != nil {
+		return fmt.Errorf("failed to watch pods: %w", err)
+	}
+	if w.config.Namespace == "" && w.reasons[NodeNotReadyReason] {
+		if err := add(factory.Core().V1().Nodes().Informer(), func(obj any) {
+			if node, ok := obj.(*corev1.Node); ok {
+				w.onNode(ctx, node)
+			}
+		}, func(obj any) {
+			if node, ok := obj.(*corev1.Node); ok {
+				w.resolveAll(ctx, "Node", "", node.Name, node.UID)
+			}
+		}); err != nil {
+			return fmt.Errorf("failed to watch nodes: %w", err)
+		}
+	}
+
+	factory.Start(ctx.Done())
+	if !cache.WaitForCacheSync(ctx.Done(), synced...) {
+		return errors.New("failed to sync event watcher caches")
+	}
+	log.Info("Kubernetes event watcher started",
+		"namespace", w.config.Namespace,
+		"reasons", w.config.Reasons)
+	return nil
+}
+
+// onEvent raises an alert for a Warning event with a watched reason
+func (w *EventWatcher) onEvent(ctx context.Context, event *corev1.Event) {
+	if event.Type != corev1.EventTypeWarning || !w.reasons[event.Reason] {
+		return
+	}
+	if eventTime(event).Before(w.started) {
+		return
+	}
+	object := event.InvolvedObject
+	if w.managed(ctx, object) {
+		return
+	}
+	alert := syntheticAlert(event.Reason, object.Kind, object.Namespace, object.Name, event.Message)
+	w.fire(ctx, alertKey(alert), object.UID, alert, false)
+}
+
+// onPod raises alerts for containers waiting with a watched reason and
+// resolves them once the container stopped waiting
+func (w *EventWatcher) onPod(ctx context.Context, pod *corev1.Pod) {
+	if pod.Labels[managedByLabel] == "openfero" {
+		return
+	}
+	waiting := make(map[string]string)
+	for _, status := range slices.Concat(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses) {
+		if status.State.Waiting != nil && w.reasons[status.State.Waiting.Reason] {
+			waiting[status.State.Waiting.Reason] = status.State.Waiting.Message
+		}
+	}
+	// Evicted pods are failed with the eviction as reason
+	if pod.Status.Phase == corev1.PodFailed && w.reasons[pod.Status.Reason] {
+		waiting[pod.Status.Reason] = pod.Status.Message
+	}
+
+	for reason := range w.reasons {
+		alert := syntheticAlert(reason, "Pod", pod.Namespace, pod.Name, waiting[reason])
+		if _, ok := waiting[reason]; ok {
+			w.fire(ctx, alertKey(alert), pod.UID, alert, true)
+		} else {
+			w.resolve(ctx, alertKey(alert), pod.UID)
+		}
+	}
+}
+
+// onNode raises an alert for a node that isn't ready and resolves it once
+// the node is ready again
+func (w *EventWatcher) onNode(ctx context.Context, node *corev1.Node) {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type != corev1.NodeReady {
+			continue
+		}
+		alert := syntheticAlert(NodeNotReadyReason, "Node", "", node.Name, condition.Message)
+		alert.Labels["condition"] = condition.Reason
+		if condition.Status == corev1.ConditionTrue {
+			w.resolve(ctx, alertKey(alert), node.UID)
+		} else {
+			w.fire(ctx, alertKey(alert), node.UID, alert, true)
+		}
+	}
+}
+
+// managed reports whether the object of an event is a Job or pod OpenFero
+// created. Pods are looked up in the informer cache, Jobs aren't watched and
+// are read from the API server.
+func (w *EventWatcher) managed(ctx context.Context, object corev1.ObjectReference) bool {
+	switch object.Kind {
+	case "Pod":
+		if w.pods == nil {
+			return false
+		}
+		obj, exists, err := w.pods.GetByKey(object.Namespace + "/" + object.Name)
+		if err != nil || !exists {
+			return false
+		}
+		pod, ok := obj.(*corev1.Pod)
+		return ok && pod.Labels[managedByLabel] == "openfero"
+	case "Job":
+		job, err := w.client.BatchV1().Jobs(object.Namespace).Get(ctx, object.Name, metav1.GetOptions{})
+		if err != nil {
+			return false
+		}
+		return job.Labels[managedByLabel] == "openfero"
+	}
+	return false
+}
+
+// resolveAll resolves the alerts of a deleted object
+func (w *EventWatcher) resolveAll(ctx context.Context, kind, namespace, name string, uid types.UID) {
+	for reason := range w.reasons {
+		w.resolve(ctx, alertKey(syntheticAlert(reason, kind, namespace, name, "")), uid)
+	}
+}
+
+// fire raises a firing alert unless it is in its cooldown or the rate limit
+// is exceeded. Alerts for conditions are remembered until they are resolved.
+func (w *EventWatcher) fire(ctx context.Context, key string, uid types.UID, alert models.Alert, condition bool) {
+	w.mu.Lock()
+	if last, ok := w.last[key]; ok && time.Since(last) < w.config.Cooldown {
+		w.mu.Unlock()
+		return
+	}
+	if !w.limiter.Allow() {
+		w.mu.Unlock()
+		log.Debug("Rate limited Kubernetes event alert", "key", key)
+		metadata.KubernetesEventAlertsTotal.WithLabelValues(alert.Labels["reason"], "rate_limited").Inc()
+		return
+	}
+	if len(w.last) >= maxTrackedAlerts {
+		w.pruneLocked()
+	}
+	w.last[key] = time.Now()
+	if condition {
+		w.active[key] = alert
+	}
+	w.mu.Unlock()
+
+	alert.Status = "firing"
+	alert.StartsAt = time.Now().UTC().Format(time.RFC3339)
+	w.emit(ctx, key, uid, alert)
+}
+
+// pruneLocked forgets firing alerts of events whose cooldown has passed
+func (w *EventWatcher) pruneLocked() {
+	for key, last := range w.last {
+		if _, active := w.active[key]; !active && time.Since(last) >= w.config.Cooldown {
+			delete(w.last, key)
+		}
+	}
+}
+
+// resolve raises a resolved alert if a firing alert was raised for key
+func (w *EventWatcher) resolve(ctx context.Context, key string, uid types.UID) {
+	w.mu.Lock()
+	alert, ok := w.active[key]
+	if !ok {
+		w.mu.Unlock()
+		return
+	}
+	delete(w.active, key)
+	delete(w.last, key)
+	w.mu.Unlock()
+
+	alert.Status = "resolved"
+	alert.EndsAt = time.Now().UTC().Format(time.RFC3339)
+	w.emit(ctx, key, uid, alert)
+}
+
+// emit passes an alert to the handler
+func (w *EventWatcher) emit(ctx context.Context, key string, uid types.UID, alert models.Alert) {
+	log.Info("Raising alert for Kubernetes event",
+		"alertname", alert.Labels["alertname"],
+		"status", alert.Status,
+		"object", key)
+	metadata.KubernetesEventAlertsTotal.WithLabelValues(alert.Labels["reason"], alert.Status).Inc()
+	w.handler(ctx, models.HookMessage{
+		Version:           "kubernetes",
+		Receiver:          "kubernetes",
+		Status:            alert.Status,
+		GroupKey:          "kubernetes:" + key,
+		CommonLabels:      alert.Labels,
+		CommonAnnotations: alert.Annotations,
+		Alerts:            []models.Alert{alert},
+		DeliveryID:        w.deliveryID(key, uid, alert.Status, time.Now()),
+	})
+}
+
+// deliveryID identifies the delivery of an alert. Every replica watches the
+// same objects; within a cooldown window they raise an alert with the same
+// ID, so only one of them creates a Job for it.
+func (w *EventWatcher) deliveryID(key string, uid types.UID, status string, now time.Time) string {
+	window := now.Unix()
+	if w.config.Cooldown > 0 {
+		window = now.UnixNano() / int64(w.config.Cooldown)
+	}
+	return utils.HashGroupKey(key + "\n" + string(uid) + "\n" + status + "\n" + strconv.FormatInt(window, 10))
+}
+
+// syntheticAlert describes a condition of an object as alert named after the reason
+func syntheticAlert(reason, kind, namespace, name, message string) models.Alert {
+	labels := map[string]string{
+		"alertname":      reason,
+		"reason":         reason,
+		"source":         "kubernetes",
+		"kind":           kind,
+		"involvedObject": name,
+	}
+	if namespace != "" {
+		labels["namespace"] = namespace
+	}
+	// Match the labels Prometheus alerts use, so Operarii work for both
+	switch kind {
+	case "Pod":
+		labels["pod"] = name
+	case "Node":
+		labels["node"] = name
+	}
+
+	annotations := map[string]string{
+		"summary": fmt.Sprintf("%s %s: %s", kind, name, reason),
+	}
+	if namespace != "" {
+		annotations["summary"] = fmt.Sprintf("%s %s/%s: %s", kind, namespace, name, reason)
+	}
+	if message != "" {
+		annotations["description"] = message
+	}
+	return models.Alert{Labels: labels, Annotations: annotations}
+}
+
+// alertKey identifies the object and reason of an alert
+func alertKey(alert models.Alert) string {
+	return fmt.Sprintf("%s/%s/%s/%s", alert.Labels["reason"], alert.Labels["kind"], alert.Labels["namespace"], alert.Labels["involvedObject"])
+}
+
+// eventTime returns the time an event last occurred
+func eventTime(event *corev1.Event) time.Time {
+	switch {
+	case event.Series != nil && !event.Series.LastObservedTime.IsZero():
+		return event.Series.LastObservedTime.Time
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp.Time
+	case !event.EventTime.IsZero():
+		return event.EventTime.Time
+	default:
+		return event.CreationTimestamp.Time
+	}
+}
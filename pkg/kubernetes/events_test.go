@@ -0,0 +1,201 @@
+package kubernetes
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/OpenFero/openfero/pkg/models"
+)
+
+// recorder collects the hook messages raised by an EventWatcher
+type recorder struct {
+	mu       sync.Mutex
+	messages []models.HookMessage
+}
+
+func (r *recorder) handle(_ context.Context, hookMessage models.HookMessage) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.messages = append(r.messages, hookMessage)
+}
+
+func (r *recorder) get() []models.HookMessage {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]models.HookMessage(nil), r.messages...)
+}
+
+func crashLoopingPod(reason string) *corev1.Pod {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-0", Namespace: "shop"}}
+	if reason != "" {
+		pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+			Name:  "web",
+			State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: reason, Message: "back-off 5m0s"}},
+		}}
+	}
+	return pod
+}
+
+func TestEventWatcher(t *testing.T) {
+	client := fake.NewSimpleClientset(crashLoopingPod("CrashLoopBackOff"))
+	var rec recorder
+	watcher := NewEventWatcher(client, EventWatcherConfig{Cooldown: time.Hour}, rec.handle)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, watcher.Start(ctx))
+
+	// A pod that is already crash looping raises an alert
+	require.Eventually(t, func() bool { return len(rec.get()) == 1 }, 5*time.Second, 10*time.Millisecond)
+	message := rec.get()[0]
+	assert.Equal(t, "firing", message.Status)
+	assert.Equal(t, map[string]string{
+		"alertname":      "CrashLoopBackOff",
+		"reason":         "CrashLoopBackOff",
+		"source":         "kubernetes",
+		"kind":           "Pod",
+		"involvedObject": "web-0",
+		"namespace":      "shop",
+		"pod":            "web-0",
+	}, message.Alerts[0].Labels)
+	assert.Equal(t, "back-off 5m0s", message.Alerts[0].Annotations["description"])
+
+	_, err := client.CoreV1().Events("shop").Create(ctx, &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: "web-1.1", Namespace: "shop"},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "shop", Name: "web-1"},
+		Reason:         "FailedScheduling",
+		Message:        "0/3 nodes are available",
+		Type:           corev1.EventTypeWarning,
+		LastTimestamp:  metav1.Now(),
+	}, metav1.CreateOptions{})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return len(rec.get()) == 2 }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, "FailedScheduling", rec.get()[1].Alerts[0].Labels["alertname"])
+	assert.Equal(t, "web-1", rec.get()[1].Alerts[0].Labels["involvedObject"])
+
+	// The alert resolves once the pod recovered
+	_, err = client.CoreV1().Pods("shop").UpdateStatus(ctx, crashLoopingPod(""), metav1.UpdateOptions{})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return len(rec.get()) == 3 }, 5*time.Second, 10*time.Millisecond)
+	resolved := rec.get()[2]
+	assert.Equal(t, "resolved", resolved.Status)
+	assert.Equal(t, message.GroupKey, resolved.GroupKey)
+	assert.NotEmpty(t, message.DeliveryID)
+	assert.NotEqual(t, message.DeliveryID, resolved.DeliveryID)
+}
+
+func TestEventWatcher_DeliveryID(t *testing.T) {
+	// Two replicas raising the same alert agree on its delivery
+	replica := NewEventWatcher(nil, EventWatcherConfig{Cooldown: 10 * time.Minute}, nil)
+	other := NewEventWatcher(nil, EventWatcherConfig{Cooldown: 10 * time.Minute}, nil)
+	key := "CrashLoopBackOff/Pod/shop/web-0"
+	at := time.Date(2024, 5, 1, 11, 2, 0, 0, time.UTC)
+
+	id := replica.deliveryID(key, "uid-1", "firing", at)
+	assert.NotEmpty(t, id)
+	assert.Equal(t, id, other.deliveryID(key, "uid-1", "firing", at.Add(5*time.Minute)))
+
+	assert.NotEqual(t, id, replica.deliveryID(key, "uid-1", "firing", at.Add(10*time.Minute)), "next cooldown window")
+	assert.NotEqual(t, id, replica.deliveryID(key, "uid-2", "firing", at), "recreated object")
+	assert.NotEqual(t, id, replica.deliveryID(key, "uid-1", "resolved", at))
+	assert.NotEqual(t, id, replica.deliveryID("FailedScheduling/Pod/shop/web-0", "uid-1", "firing", at))
+}
+
+func TestEventWatcher_IgnoresManagedObjects(t *testing.T) {
+	managed := map[string]string{managedByLabel: "openfero"}
+	pod := crashLoopingPod("CrashLoopBackOff")
+	pod.Name = "remediation-abcde"
+	pod.Labels = managed
+	job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "remediation", Namespace: "shop", Labels: managed}}
+	client := fake.NewSimpleClientset(pod, job)
+	var rec recorder
+	watcher := NewEventWatcher(client, EventWatcherConfig{Reasons: []string{"CrashLoopBackOff", "BackoffLimitExceeded"}}, rec.handle)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, watcher.Start(ctx))
+
+	warning := func(kind, name, reason string) {
+		t.Helper()
+		_, err := client.CoreV1().Events("shop").Create(ctx, &corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: name + "." + reason, Namespace: "shop"},
+			InvolvedObject: corev1.ObjectReference{Kind: kind, Namespace: "shop", Name: name},
+			Reason:         reason,
+			Type:           corev1.EventTypeWarning,
+			LastTimestamp:  metav1.Now(),
+		}, metav1.CreateOptions{})
+		require.NoError(t, err)
+	}
+	warning("Pod", pod.Name, "CrashLoopBackOff")
+	warning("Job", job.Name, "BackoffLimitExceeded")
+	// Events are handled in order, once this one raised an alert the others were ignored
+	warning("Pod", "web-0", "CrashLoopBackOff")
+
+	require.Eventually(t, func() bool { return len(rec.get()) > 0 }, 5*time.Second, 10*time.Millisecond)
+	messages := rec.get()
+	require.Len(t, messages, 1, "a failing remediation must not raise alerts")
+	assert.Equal(t, "web-0", messages[0].Alerts[0].Labels["involvedObject"])
+}
+
+func TestEventWatcher_IgnoresOldAndNormalEvents(t *testing.T) {
+	var rec recorder
+	watcher := NewEventWatcher(fake.NewSimpleClientset(), EventWatcherConfig{}, rec.handle)
+	watcher.started = time.Now()
+
+	event := &corev1.Event{
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "shop", Name: "web-0"},
+		Reason:         "FailedScheduling",
+		Type:           corev1.EventTypeWarning,
+		LastTimestamp:  metav1.NewTime(time.Now().Add(-time.Minute)),
+	}
+	watcher.onEvent(context.Background(), event)
+
+	event.LastTimestamp = metav1.Now()
+	event.Type = corev1.EventTypeNormal
+	watcher.onEvent(context.Background(), event)
+
+	event.Type = corev1.EventTypeWarning
+	event.Reason = "Unwatched"
+	watcher.onEvent(context.Background(), event)
+
+	assert.Empty(t, rec.get())
+}
+
+func TestEventWatcher_RateLimits(t *testing.T) {
+	var rec recorder
+	watcher := NewEventWatcher(fake.NewSimpleClientset(), EventWatcherConfig{
+		Cooldown:      time.Hour,
+		RatePerMinute: 1,
+		Burst:         2,
+	}, rec.handle)
+	ctx := context.Background()
+
+	// The cooldown suppresses repeated alerts for the same pod
+	watcher.onPod(ctx, crashLoopingPod("CrashLoopBackOff"))
+	watcher.onPod(ctx, crashLoopingPod("CrashLoopBackOff"))
+	assert.Len(t, rec.get(), 1)
+
+	// The rate limit applies across objects
+	for _, name := range []string{"a", "b", "c"} {
+		watcher.onNode(ctx, &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeReady, Status: corev1.ConditionUnknown, Reason: "NodeStatusUnknown"},
+			}},
+		})
+	}
+	messages := rec.get()
+	require.Len(t, messages, 2)
+	assert.Equal(t, "NodeNotReady", messages[1].Alerts[0].Labels["alertname"])
+	assert.Equal(t, "a", messages[1].Alerts[0].Labels["node"])
+	assert.Equal(t, "NodeStatusUnknown", messages[1].Alerts[0].Labels["condition"])
+}
@@ -48,6 +48,111 @@ var (
 		Name: "openfero_operarius_items_loaded",
 		Help: "Current number of Operarius CRDs loaded in the informer cache",
 	})
+
+	JobActionsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "openfero_job_actions_total",
+		Help: "Total number of operator actions on remediation jobs by action and outcome",
+	}, []string{"action", "outcome"})
+
+	WebhookQueueDepth = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "openfero_webhook_queue_depth",
+		Help: "Current number of webhook messages waiting to be processed",
+	})
+
+	WebhookQueueCapacity = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "openfero_webhook_queue_capacity",
+		Help: "Maximum number of webhook messages that can wait to be processed",
+	})
+
+	WebhookQueueWaitSeconds = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "openfero_webhook_queue_wait_seconds",
+		Help:    "Time webhook messages spent in the queue before processing started",
+		Buckets: prometheus.ExponentialBuckets(0.001, 4, 9),
+	})
+
+	WebhookProcessingSeconds = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "openfero_webhook_processing_seconds",
+		Help:    "Time spent processing a webhook message",
+		Buckets: prometheus.ExponentialBuckets(0.005, 2, 12),
+	})
+
+	WebhookReplayedTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "openfero_webhook_replayed_total",
+		Help: "Total number of webhook messages replayed from the write-ahead log at startup",
+	})
+
+	WebhookDuplicatesTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "openfero_webhook_duplicates_total",
+		Help: "Total number of re-delivered webhook messages acknowledged without processing them again",
+	})
+
+	WebhookRejectedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "openfero_webhook_rejected_total",
+		Help: "Total number of webhook requests rejected by reason",
+	}, []string{"reason"})
+
+	CloudEventsEmittedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "openfero_cloudevents_emitted_total",
+		Help: "Total number of lifecycle CloudEvents by type and result (sent, failed, dropped)",
+	}, []string{"type", "result"})
+
+	KubernetesEventAlertsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "openfero_kubernetes_event_alerts_total",
+		Help: "Total number of alerts raised for Kubernetes events and conditions by reason and result (firing, resolved, rate_limited)",
+	}, []string{"reason", "result"})
+
+	CronTriggersTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "openfero_cron_triggers_total",
+		Help: "Total number of due Operarius cron schedules by result (run, missed, invalid)",
+	}, []string{"result"})
+
+	AlertPollsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "openfero_alert_polls_total",
+		Help: "Total number of polls of the Alertmanager or Prometheus alerts API by api and result (success, error)",
+	}, []string{"api", "result"})
+
+	WebhookMessagesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "openfero_webhook_messages_total",
+		Help: "Total number of accepted webhook messages by the label of the credential they were sent with",
+	}, []string{"credential"})
+
+	AuthCredentialReloadsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "openfero_auth_credential_reloads_total",
+		Help: "Total number of reloads of a changed credentials file by result (success, error)",
+	}, []string{"result"})
+
+	APIAuthRejectedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "openfero_api_auth_rejected_total",
+		Help: "Total number of API and UI requests rejected by OIDC authentication or authorization by reason (missing_token, invalid_token, forbidden)",
+	}, []string{"reason"})
+
+	TLSReloadsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "openfero_tls_reloads_total",
+		Help: "Total number of reloads of changed TLS certificate files by result (success, error)",
+	}, []string{"result"})
+
+	TLSCertificateExpiryTimestamp = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "openfero_tls_certificate_expiry_timestamp_seconds",
+		Help: "Time the serving TLS certificate expires, in Unix seconds",
+	})
+
+	AlertTimeToRemediationSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "openfero_alert_time_to_remediation_seconds",
+		Help:    "Time from an alert starting to fire until the remediation Job was created, by Operarius",
+		Buckets: prometheus.ExponentialBuckets(1, 2, 14),
+	}, []string{"operarius"})
+
+	RemediationJobDurationSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "openfero_remediation_job_duration_seconds",
+		Help:    "Time from a remediation Job starting until it finished, by Operarius and status",
+		Buckets: prometheus.ExponentialBuckets(1, 2, 14),
+	}, []string{"operarius", "status"})
+
+	AlertTimeToResolveSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "openfero_alert_time_to_resolve_seconds",
+		Help:    "Time from an alert starting to fire until it resolved, by Operarius and whether its Job succeeded",
+		Buckets: prometheus.ExponentialBuckets(10, 2, 14),
+	}, []string{"operarius", "remediated"})
 )
 
 // Function to get metrics values from runtime/metrics package as float64
@@ -107,6 +212,26 @@ func AddMetricsToPrometheusRegistry() {
 	prometheus.MustRegister(JobsFailedTotal)
 	prometheus.MustRegister(OperariusSyncErrorsTotal)
 	prometheus.MustRegister(OperariusItemsLoaded)
+	prometheus.MustRegister(JobActionsTotal)
+	prometheus.MustRegister(WebhookQueueDepth)
+	prometheus.MustRegister(WebhookQueueCapacity)
+	prometheus.MustRegister(WebhookQueueWaitSeconds)
+	prometheus.MustRegister(WebhookProcessingSeconds)
+	prometheus.MustRegister(WebhookReplayedTotal)
+	prometheus.MustRegister(WebhookRejectedTotal)
+	prometheus.MustRegister(WebhookDuplicatesTotal)
+	prometheus.MustRegister(CloudEventsEmittedTotal)
+	prometheus.MustRegister(KubernetesEventAlertsTotal)
+	prometheus.MustRegister(CronTriggersTotal)
+	prometheus.MustRegister(AlertPollsTotal)
+	prometheus.MustRegister(WebhookMessagesTotal)
+	prometheus.MustRegister(AuthCredentialReloadsTotal)
+	prometheus.MustRegister(APIAuthRejectedTotal)
+	prometheus.MustRegister(TLSReloadsTotal)
+	prometheus.MustRegister(TLSCertificateExpiryTimestamp)
+	prometheus.MustRegister(AlertTimeToRemediationSeconds)
+	prometheus.MustRegister(RemediationJobDurationSeconds)
+	prometheus.MustRegister(AlertTimeToResolveSeconds)
 	// Get descriptions for all supported metrics.
 	metricsMeta := metrics.All()
 	// Register metrics and retrieve the values in prometheus client
@@ -26,6 +26,15 @@ type HookMessage struct {
 	ExternalURL string `json:"externalURL"`
 	// List of alerts in the group
 	Alerts []Alert `json:"alerts"`
+	// Number of alerts Alertmanager left out because of the receiver's max_alerts
+	TruncatedAlerts int `json:"truncatedAlerts"`
+	// DeliveryID identifies an accepted webhook delivery. Jobs created for a
+	// delivery are named after it, so processing it again is idempotent.
+	DeliveryID string `json:"-"`
+	// ReceivedAt is the time the webhook delivery was accepted
+	ReceivedAt time.Time `json:"-"`
+	// Credential is the label of the credential the webhook was sent with
+	Credential string `json:"-"`
 }
 
 // Alert information from Alertmanager
@@ -38,6 +47,14 @@ type Alert struct {
 	StartsAt string `json:"startsAt,omitempty"`
 	// Time when the alert ended
 	EndsAt string `json:"endsAt,omitempty"`
+	// Status of this alert (firing/resolved), may differ from the group status
+	Status string `json:"status,omitempty" enum:"firing,resolved"`
+	// Link to the rule that generated the alert
+	GeneratorURL string `json:"generatorURL,omitempty"`
+	// Alertmanager's identity of the alert, stable across firing and resolved
+	Fingerprint string `json:"fingerprint,omitempty"`
+	// Label of the credential the alert was received with, set by OpenFero
+	Credential string `json:"-"`
 }
 
 // AlertStoreEntry represents a stored alert with status and timestamp
@@ -66,14 +83,38 @@ type JobInfo struct {
 	LastExecutedJobName string `json:"lastExecutedJobName,omitempty"`
 	// Status of the last execution
 	LastExecutionStatus string `json:"status,omitempty"`
+	// Why the pod of the last execution failed, if it did
+	LastFailure *alertstore.JobFailure `json:"failure,omitempty"`
+}
+
+// StableFingerprint returns the fingerprint sent by Alertmanager, or computes
+// it from the labels if the alert was received without one
+func (a *Alert) StableFingerprint() string {
+	if a.Fingerprint != "" {
+		return a.Fingerprint
+	}
+	return alertstore.Fingerprint(a.Labels)
+}
+
+// StatusOr returns the status of the alert, or the status of its group if
+// the sender didn't set one per alert
+func (a *Alert) StatusOr(groupStatus string) string {
+	if a.Status != "" {
+		return a.Status
+	}
+	return groupStatus
 }
 
 // ToAlertStoreAlert converts an Alert to alertstore.Alert
 func (a *Alert) ToAlertStoreAlert() alertstore.Alert {
 	return alertstore.Alert{
-		Labels:      a.Labels,
-		Annotations: a.Annotations,
-		StartsAt:    a.StartsAt,
-		EndsAt:      a.EndsAt,
+		Labels:       a.Labels,
+		Annotations:  a.Annotations,
+		StartsAt:     a.StartsAt,
+		EndsAt:       a.EndsAt,
+		Status:       a.Status,
+		GeneratorURL: a.GeneratorURL,
+		Fingerprint:  a.StableFingerprint(),
+		Credential:   a.Credential,
 	}
 }
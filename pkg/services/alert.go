@@ -1,6 +1,8 @@
 package services
 
 import (
+	"reflect"
+
 	"github.com/OpenFero/openfero/pkg/alertstore"
 	log "github.com/OpenFero/openfero/pkg/logging"
 	"github.com/OpenFero/openfero/pkg/models"
@@ -82,3 +84,20 @@ func SaveAlertWithJobInfo(alertStore alertstore.Store, alert models.Alert, statu
 	// Broadcast alert update to SSE clients
 	broadcastAlert(alert, status, jobInfo)
 }
+
+// UpdateAlertJobFailure attaches pod failure diagnostics to all stored alerts that triggered the given job
+func UpdateAlertJobFailure(alertStore alertstore.Store, namespace, jobName string, failure *alertstore.JobFailure) {
+	err := alertStore.UpdateJobInfo(namespace, jobName, func(jobInfo *alertstore.JobInfo) bool {
+		if reflect.DeepEqual(jobInfo.Failure, failure) {
+			return false
+		}
+		jobInfo.Failure = failure
+		return true
+	})
+	if err != nil {
+		log.Error("Failed to update job failure in alert store",
+			"job", jobName,
+			"namespace", namespace,
+			"error", err)
+	}
+}
@@ -0,0 +1,225 @@
+package services
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	operariusv1alpha1 "github.com/OpenFero/openfero/api/v1alpha1"
+	"github.com/OpenFero/openfero/pkg/alertstore"
+	"github.com/OpenFero/openfero/pkg/models"
+)
+
+// BacktestOptions adjusts how alert history is replayed
+type BacktestOptions struct {
+	// IncludeDisabled treats disabled Operarii as enabled, so new Operarii can
+	// be evaluated before they are switched on
+	IncludeDisabled bool
+}
+
+// BacktestOperariusResult counts what a single Operarius would have done
+type BacktestOperariusResult struct {
+	Operarius string `json:"operarius"`
+	Namespace string `json:"namespace"`
+	Enabled   bool   `json:"enabled"`
+	// WouldRun counts the Jobs the Operarius would have created
+	WouldRun int `json:"wouldRun"`
+	// DedupSuppressed counts deliveries it would have handled but deduplication skipped
+	DedupSuppressed int `json:"dedupSuppressed"`
+	// Shadowed counts deliveries its selector matched but a higher priority Operarius handled
+	Shadowed int `json:"shadowed"`
+}
+
+// BacktestUnmatched counts replayed alerts no Operarius would have handled
+type BacktestUnmatched struct {
+	Alertname string `json:"alertname"`
+	Status    string `json:"status"`
+	Count     int    `json:"count"`
+}
+
+// BacktestResult summarizes a replay of alert history
+type BacktestResult struct {
+	// Alerts is the number of replayed alerts
+	Alerts int `json:"alerts"`
+	// Deliveries is the number of webhook deliveries the alerts were rebuilt into
+	Deliveries int `json:"deliveries"`
+	// Skipped counts entries that did not come from Alertmanager, e.g. manual triggers
+	Skipped         int                       `json:"skipped"`
+	From            *time.Time                `json:"from,omitempty"`
+	To              *time.Time                `json:"to,omitempty"`
+	Operarii        []BacktestOperariusResult `json:"operarii"`
+	Unmatched       int                       `json:"unmatched"`
+	UnmatchedAlerts []BacktestUnmatched       `json:"unmatchedAlerts"`
+}
+
+// backtestDeliveryGap is how far apart the alerts of one webhook delivery
+// can have been stored
+const backtestDeliveryGap = time.Second
+
+// Backtest replays stored alerts in chronological order through the current
+// matching and deduplication logic. Like webhooks, the alerts stored for one
+// delivery are replayed together and create at most one Job. Deduplication
+// is evaluated against the replayed history instead of the Jobs in the
+// cluster; since the original group key is not stored, the labels the alerts
+// of a delivery have in common stand in for it.
+func (s *OperariusService) Backtest(operarii []operariusv1alpha1.Operarius, entries []alertstore.AlertEntry, opts BacktestOptions) *BacktestResult {
+	candidates := make([]operariusv1alpha1.Operarius, len(operarii))
+	results := make(map[string]*BacktestOperariusResult, len(operarii))
+	for i := range operarii {
+		candidates[i] = *operarii[i].DeepCopy()
+		enabled := operarii[i].Spec.Enabled == nil || *operarii[i].Spec.Enabled
+		if opts.IncludeDisabled {
+			candidates[i].Spec.Enabled = nil
+		}
+		results[operariusKey(&operarii[i])] = &BacktestOperariusResult{
+			Operarius: operarii[i].Name,
+			Namespace: operarii[i].Namespace,
+			Enabled:   enabled,
+		}
+	}
+
+	sorted := slices.Clone(entries)
+	slices.SortStableFunc(sorted, func(a, b alertstore.AlertEntry) int {
+		return a.Timestamp.Compare(b.Timestamp)
+	})
+
+	result := &BacktestResult{}
+	unmatched := make(map[BacktestUnmatched]int)
+	lastRun := make(map[string]time.Time)
+
+	replayed := make([]alertstore.AlertEntry, 0, len(sorted))
+	for _, entry := range sorted {
+		if _, triggered := entry.Alert.Labels[TriggerLabel]; triggered {
+			result.Skipped++
+			continue
+		}
+		replayed = append(replayed, entry)
+	}
+	if len(replayed) > 0 {
+		from, to := replayed[0].Timestamp, replayed[len(replayed)-1].Timestamp
+		result.From, result.To = &from, &to
+	}
+
+	for _, delivery := range backtestDeliveries(replayed) {
+		result.Alerts += len(delivery)
+		result.Deliveries++
+		hookMessage := backtestHookMessage(delivery)
+
+		selected, err := s.FindMatchingOperarius(hookMessage, candidates)
+		if err != nil {
+			for _, entry := range delivery {
+				result.Unmatched++
+				unmatched[BacktestUnmatched{Alertname: entry.Alert.Labels["alertname"], Status: entry.Status}]++
+			}
+			continue
+		}
+
+		for i := range candidates {
+			if operariusKey(&candidates[i]) == operariusKey(selected) {
+				continue
+			}
+			if matched, _ := explainMatch(candidates[i], hookMessage); matched {
+				results[operariusKey(&candidates[i])].Shadowed++
+			}
+		}
+
+		counts := results[operariusKey(selected)]
+		key := operariusKey(selected) + "/" + dedupKey(hookMessage)
+		at := delivery[0].Timestamp
+		if dedup := selected.Spec.Deduplication; dedup != nil && dedup.Enabled && dedup.TTL > 0 {
+			if last, ok := lastRun[key]; ok && at.Before(last.Add(time.Duration(dedup.TTL)*time.Second)) {
+				counts.DedupSuppressed++
+				continue
+			}
+		}
+		lastRun[key] = at
+		counts.WouldRun++
+	}
+
+	result.Operarii = make([]BacktestOperariusResult, 0, len(results))
+	for _, counts := range results {
+		result.Operarii = append(result.Operarii, *counts)
+	}
+	slices.SortFunc(result.Operarii, func(a, b BacktestOperariusResult) int {
+		return cmp.Or(cmp.Compare(a.Namespace, b.Namespace), cmp.Compare(a.Operarius, b.Operarius))
+	})
+
+	result.UnmatchedAlerts = make([]BacktestUnmatched, 0, len(unmatched))
+	for alert, count := range unmatched {
+		alert.Count = count
+		result.UnmatchedAlerts = append(result.UnmatchedAlerts, alert)
+	}
+	slices.SortFunc(result.UnmatchedAlerts, func(a, b BacktestUnmatched) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), cmp.Compare(a.Alertname, b.Alertname), cmp.Compare(a.Status, b.Status))
+	})
+
+	return result
+}
+
+// backtestDeliveries rebuilds the webhook deliveries of chronologically
+// sorted entries. The alerts of a delivery were stored one after the other
+// with the same Job, or with the same alertname and status if none was
+// created.
+func backtestDeliveries(entries []alertstore.AlertEntry) [][]alertstore.AlertEntry {
+	var deliveries [][]alertstore.AlertEntry
+	open := make(map[string]int) // delivery key -> index of its latest delivery
+	for _, entry := range entries {
+		key := backtestDeliveryKey(entry)
+		if i, ok := open[key]; ok && entry.Timestamp.Sub(deliveries[i][0].Timestamp) <= backtestDeliveryGap {
+			deliveries[i] = append(deliveries[i], entry)
+			continue
+		}
+		open[key] = len(deliveries)
+		deliveries = append(deliveries, []alertstore.AlertEntry{entry})
+	}
+	return deliveries
+}
+
+// backtestDeliveryKey identifies the delivery an entry was stored for: its
+// Job, or its alertname, status and the Operarius that skipped it
+func backtestDeliveryKey(entry alertstore.AlertEntry) string {
+	alert := entry.Alert.Labels["alertname"] + "/" + entry.Status
+	switch entry.JobOutcome() {
+	case alertstore.JobOutcomeNone:
+		return "alert:" + alert
+	case alertstore.JobOutcomeSkipped:
+		return "skipped:" + alert + "/" + entry.JobInfo.Namespace + "/" + entry.JobInfo.OperariusName
+	}
+	return "job:" + entry.JobInfo.Namespace + "/" + entry.JobInfo.JobName
+}
+
+// backtestHookMessage rebuilds the hook message of a delivery. The group is
+// firing if any of its alerts is, and its labels are the ones all alerts share.
+func backtestHookMessage(delivery []alertstore.AlertEntry) models.HookMessage {
+	hookMessage := HookMessageFromAlertEntry(delivery[0])
+	common := maps.Clone(delivery[0].Alert.Labels)
+	for _, entry := range delivery[1:] {
+		hookMessage.Alerts = append(hookMessage.Alerts, HookMessageFromAlertEntry(entry).Alerts...)
+		if entry.Status == "firing" {
+			hookMessage.Status = "firing"
+		}
+		maps.DeleteFunc(common, func(name, value string) bool { return entry.Alert.Labels[name] != value })
+	}
+	hookMessage.CommonLabels = common
+	hookMessage.GroupKey = labelSetKey(common)
+	return hookMessage
+}
+
+// operariusKey identifies an Operarius across namespaces
+func operariusKey(operarius *operariusv1alpha1.Operarius) string {
+	return operarius.Namespace + "/" + operarius.Name
+}
+
+// labelSetKey derives a stable key from an alert's labels
+func labelSetKey(labels map[string]string) string {
+	var b strings.Builder
+	for _, key := range slices.Sorted(maps.Keys(labels)) {
+		b.WriteString(key)
+		b.WriteByte('=')
+		b.WriteString(labels[key])
+		b.WriteByte(',')
+	}
+	return b.String()
+}
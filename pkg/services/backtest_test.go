@@ -0,0 +1,125 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	operariusv1alpha1 "github.com/OpenFero/openfero/api/v1alpha1"
+	"github.com/OpenFero/openfero/pkg/alertstore"
+)
+
+func backtestEntry(at time.Time, status string, labels map[string]string) alertstore.AlertEntry {
+	return alertstore.AlertEntry{
+		Alert:     alertstore.Alert{Labels: labels},
+		Status:    status,
+		Timestamp: at,
+	}
+}
+
+func TestBacktest(t *testing.T) {
+	restart := simulationOperarius("restart-pod", 10, map[string]string{"namespace": "shop"})
+	restart.Spec.Deduplication = &operariusv1alpha1.DeduplicationConfig{Enabled: true, TTL: 300}
+	notify := simulationOperarius("notify", 1, nil)
+	disabled := simulationOperarius("disabled", 30, nil)
+	enabled := false
+	disabled.Spec.Enabled = &enabled
+	operarii := []operariusv1alpha1.Operarius{restart, notify, disabled}
+
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	shop := map[string]string{"alertname": "HighMemory", "namespace": "shop", "pod": "web-0"}
+	billing := map[string]string{"alertname": "HighMemory", "namespace": "billing"}
+	entries := []alertstore.AlertEntry{
+		// Stored newest first, the backtest has to replay chronologically
+		backtestEntry(start.Add(10*time.Minute), "firing", shop),
+		backtestEntry(start.Add(time.Minute), "firing", shop),
+		backtestEntry(start, "firing", shop),
+		backtestEntry(start.Add(2*time.Minute), "firing", billing),
+		backtestEntry(start.Add(3*time.Minute), "firing", map[string]string{"alertname": "DiskFull"}),
+		backtestEntry(start.Add(4*time.Minute), "firing", map[string]string{"alertname": "DiskFull"}),
+		backtestEntry(start.Add(5*time.Minute), "firing", map[string]string{"alertname": "HighMemory", TriggerLabel: TriggerManual}),
+	}
+
+	service := NewOperariusService(nil)
+
+	t.Run("current configuration", func(t *testing.T) {
+		result := service.Backtest(operarii, entries, BacktestOptions{})
+
+		assert.Equal(t, 6, result.Alerts)
+		assert.Equal(t, 1, result.Skipped)
+		require.NotNil(t, result.From)
+		require.NotNil(t, result.To)
+		assert.Equal(t, start, *result.From)
+		assert.Equal(t, start.Add(10*time.Minute), *result.To)
+
+		require.Len(t, result.Operarii, 3)
+		counts := make(map[string]BacktestOperariusResult)
+		for _, operarius := range result.Operarii {
+			counts[operarius.Operarius] = operarius
+		}
+
+		// Within the TTL the second shop alert is suppressed, after it the third runs again
+		assert.Equal(t, 2, counts["restart-pod"].WouldRun)
+		assert.Equal(t, 1, counts["restart-pod"].DedupSuppressed)
+		assert.Equal(t, 1, counts["notify"].WouldRun)
+		assert.Equal(t, 3, counts["notify"].Shadowed)
+		assert.False(t, counts["disabled"].Enabled)
+		assert.Zero(t, counts["disabled"].WouldRun)
+
+		assert.Equal(t, 2, result.Unmatched)
+		assert.Equal(t, []BacktestUnmatched{{Alertname: "DiskFull", Status: "firing", Count: 2}}, result.UnmatchedAlerts)
+	})
+
+	t.Run("include disabled", func(t *testing.T) {
+		result := service.Backtest(operarii, entries, BacktestOptions{IncludeDisabled: true})
+
+		counts := make(map[string]BacktestOperariusResult)
+		for _, operarius := range result.Operarii {
+			counts[operarius.Operarius] = operarius
+		}
+		assert.False(t, counts["disabled"].Enabled)
+		assert.Equal(t, 4, counts["disabled"].WouldRun)
+		assert.Zero(t, counts["restart-pod"].WouldRun)
+		assert.Equal(t, 3, counts["restart-pod"].Shadowed)
+		assert.False(t, *operarii[2].Spec.Enabled, "backtest must not modify the given Operarii")
+	})
+}
+
+// TestBacktest_Deliveries verifies the alerts stored for one webhook are
+// replayed as one delivery, deduplicated by their fingerprints like webhooks
+func TestBacktest_Deliveries(t *testing.T) {
+	restart := simulationOperarius("restart-pod", 10, map[string]string{"namespace": "shop"})
+	restart.Spec.Deduplication = &operariusv1alpha1.DeduplicationConfig{Enabled: true, TTL: 300}
+
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	entry := func(at time.Time, fingerprint string, jobInfo *alertstore.JobInfo) alertstore.AlertEntry {
+		e := backtestEntry(at, "firing", map[string]string{"alertname": "HighMemory", "namespace": "shop", "pod": "web-" + fingerprint})
+		e.Alert.Fingerprint = fingerprint
+		e.JobInfo = jobInfo
+		return e
+	}
+	job := &alertstore.JobInfo{OperariusName: "restart-pod", Namespace: "openfero", JobName: "restart-pod-1"}
+	skipped := &alertstore.JobInfo{OperariusName: "restart-pod", Namespace: "openfero", JobName: "N/A (Deduplicated)", LastExecutionStatus: "Skipped: Deduplication"}
+	entries := []alertstore.AlertEntry{
+		// One delivery of three alerts that created a single Job
+		entry(start, "a", job),
+		entry(start.Add(time.Millisecond), "b", job),
+		entry(start.Add(2*time.Millisecond), "c", job),
+		// The same alerts listed in another order are deduplicated
+		entry(start.Add(time.Minute), "c", skipped),
+		entry(start.Add(time.Minute+time.Millisecond), "a", skipped),
+		entry(start.Add(time.Minute+2*time.Millisecond), "b", skipped),
+		// Another set of alerts of the group gets its own Job
+		entry(start.Add(2*time.Minute), "a", nil),
+		entry(start.Add(2*time.Minute+time.Millisecond), "b", nil),
+	}
+
+	result := NewOperariusService(nil).Backtest([]operariusv1alpha1.Operarius{restart}, entries, BacktestOptions{})
+	assert.Equal(t, 8, result.Alerts)
+	assert.Equal(t, 3, result.Deliveries)
+	require.Len(t, result.Operarii, 1)
+	assert.Equal(t, 2, result.Operarii[0].WouldRun)
+	assert.Equal(t, 1, result.Operarii[0].DedupSuppressed)
+}
@@ -0,0 +1,211 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strconv"
+	"time"
+
+	operariusv1alpha1 "github.com/OpenFero/openfero/api/v1alpha1"
+	"github.com/OpenFero/openfero/pkg/cron"
+	log "github.com/OpenFero/openfero/pkg/logging"
+	"github.com/OpenFero/openfero/pkg/metadata"
+	"github.com/OpenFero/openfero/pkg/models"
+	"github.com/OpenFero/openfero/pkg/utils"
+)
+
+// CronCheckInterval is how often the CronScheduler checks for due schedules
+const CronCheckInterval = 10 * time.Second
+
+// CronRunner runs an Operarius for the hook message synthesized for a due schedule
+type CronRunner func(ctx context.Context, operarius *operariusv1alpha1.Operarius, hookMessage models.HookMessage)
+
+// CronScheduler runs Operarii with a cron trigger on their schedule.
+//
+// The time a schedule was last due is recorded in the Operarius status, so
+// schedules missed while OpenFero was down are caught up like a CronJob does:
+// only the latest missed run is started, and not at all if it is later than
+// the starting deadline. Jobs of a run are named after the Operarius and the
+// scheduled time, so replicas racing for the same run create it only once.
+type CronScheduler struct {
+	service *OperariusService
+	run     CronRunner
+	now     func() time.Time
+	// invalid holds the schedules reported as invalid, by Operarius
+	invalid map[string]string
+}
+
+// NewCronScheduler creates a CronScheduler
+func NewCronScheduler(service *OperariusService, run CronRunner) *CronScheduler {
+	return &CronScheduler{
+		service: service,
+		run:     run,
+		now:     time.Now,
+		invalid: make(map[string]string),
+	}
+}
+
+// Start checks for due schedules every CronCheckInterval until the context is canceled
+func (c *CronScheduler) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(CronCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				c.Tick(ctx)
+			}
+		}
+	}()
+}
+
+// Tick runs all Operarii whose schedule is due
+func (c *CronScheduler) Tick(ctx context.Context) {
+	operarii, err := c.service.GetOperariiForNamespace(ctx, "")
+	if err != nil {
+		log.Error("Failed to get Operarii for cron triggers", "error", err)
+		return
+	}
+
+	now := c.now()
+	for i := range operarii {
+		operarius := &operarii[i]
+		if operarius.Spec.CronTrigger == nil || (operarius.Spec.Enabled != nil && !*operarius.Spec.Enabled) {
+			continue
+		}
+		// The list may be backed by the informer cache, which must not be modified
+		c.check(ctx, operarius.DeepCopy(), now)
+	}
+}
+
+// check runs an Operarius if its schedule is due
+func (c *CronScheduler) check(ctx context.Context, operarius *operariusv1alpha1.Operarius, now time.Time) {
+	trigger := operarius.Spec.CronTrigger
+	key := operarius.Namespace + "/" + operarius.Name
+	schedule, err := parseCronTrigger(trigger)
+	if err != nil {
+		// Checked on every tick, report each invalid schedule once
+		if c.invalid[key] != trigger.Schedule {
+			c.invalid[key] = trigger.Schedule
+			log.Error("Invalid cron trigger", "operarius", operarius.Name, "namespace", operarius.Namespace, "error", err)
+			metadata.CronTriggersTotal.WithLabelValues("invalid").Inc()
+		}
+		return
+	}
+	delete(c.invalid, key)
+
+	after := operarius.CreationTimestamp.Time
+	if operarius.Status.LastScheduleTime != nil {
+		after = operarius.Status.LastScheduleTime.Time
+	}
+	if after.IsZero() {
+		return
+	}
+
+	scheduled := lastActivation(schedule, after, now)
+	if scheduled.IsZero() {
+		return
+	}
+	if first := schedule.Next(after); first.Before(scheduled) {
+		log.Warn("Missed cron schedules of Operarius, running the latest",
+			"operarius", operarius.Name,
+			"namespace", operarius.Namespace,
+			"firstMissed", first,
+			"scheduled", scheduled)
+	}
+
+	// Record the schedule first, a run that fails isn't retried on the next tick
+	if err := c.service.UpdateOperariusScheduleTime(ctx, operarius, scheduled); err != nil {
+		log.Warn("Failed to record cron schedule time", "operarius", operarius.Name, "error", err)
+	}
+
+	if deadline := trigger.StartingDeadlineSeconds; deadline != nil && now.Sub(scheduled) > time.Duration(*deadline)*time.Second {
+		log.Warn("Skipping cron run of Operarius, its starting deadline passed",
+			"operarius", operarius.Name,
+			"namespace", operarius.Namespace,
+			"scheduled", scheduled,
+			"startingDeadlineSeconds", *deadline)
+		metadata.CronTriggersTotal.WithLabelValues("missed").Inc()
+		return
+	}
+
+	log.Info("Running Operarius on schedule",
+		"operarius", operarius.Name,
+		"namespace", operarius.Namespace,
+		"scheduled", scheduled)
+	metadata.CronTriggersTotal.WithLabelValues("run").Inc()
+	c.run(ctx, operarius, CronHookMessage(operarius, scheduled))
+}
+
+// parseCronTrigger parses the schedule of a cron trigger in its time zone
+func parseCronTrigger(trigger *operariusv1alpha1.CronTrigger) (*cron.Schedule, error) {
+	location := time.UTC
+	if trigger.TimeZone != nil && *trigger.TimeZone != "" {
+		var err error
+		if location, err = time.LoadLocation(*trigger.TimeZone); err != nil {
+			return nil, fmt.Errorf("invalid time zone %q: %w", *trigger.TimeZone, err)
+		}
+	}
+	return cron.Parse(trigger.Schedule, location)
+}
+
+// lastActivation returns the latest activation of a schedule in (after, now],
+// or the zero time if there is none. The window searched grows backwards from
+// now, so a long downtime doesn't iterate over every missed activation.
+func lastActivation(schedule *cron.Schedule, after, now time.Time) time.Time {
+	for window := time.Minute; ; window *= 2 {
+		from := now.Add(-window)
+		if !from.After(after) {
+			from = after
+		}
+		var latest time.Time
+		for t := schedule.Next(from); !t.IsZero() && !t.After(now); t = schedule.Next(t) {
+			latest = t
+		}
+		if !latest.IsZero() || from.Equal(after) {
+			return latest
+		}
+	}
+}
+
+// CronHookMessage synthesizes the hook message an Operarius runs with when
+// its schedule is due. The alert matches the selector of the Operarius, and
+// carries the labels of the cron trigger.
+func CronHookMessage(operarius *operariusv1alpha1.Operarius, scheduled time.Time) models.HookMessage {
+	selector := operarius.Spec.AlertSelector
+	labels := make(map[string]string, len(selector.Labels)+len(operarius.Spec.CronTrigger.Labels)+1)
+	maps.Copy(labels, selector.Labels)
+	maps.Copy(labels, operarius.Spec.CronTrigger.Labels)
+	labels["alertname"] = selector.AlertName
+
+	status := selector.Status
+	if status == "" {
+		status = "firing"
+	}
+	annotations := map[string]string{
+		"schedule":      operarius.Spec.CronTrigger.Schedule,
+		"scheduledTime": scheduled.UTC().Format(time.RFC3339),
+	}
+
+	groupKey := "cron:" + operarius.Namespace + "/" + operarius.Name
+	return models.HookMessage{
+		Version:           "4",
+		GroupKey:          groupKey,
+		Status:            status,
+		Receiver:          "openfero-cron",
+		CommonLabels:      labels,
+		CommonAnnotations: annotations,
+		Alerts: []models.Alert{{
+			Labels:      labels,
+			Annotations: annotations,
+			Status:      status,
+			StartsAt:    scheduled.UTC().Format(time.RFC3339),
+		}},
+		// Each scheduled time is one delivery, a run is only created once
+		DeliveryID: utils.HashGroupKey(groupKey + "\n" + strconv.FormatInt(scheduled.Unix(), 10)),
+		ReceivedAt: scheduled,
+	}
+}
@@ -0,0 +1,173 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	operariusv1alpha1 "github.com/OpenFero/openfero/api/v1alpha1"
+	"github.com/OpenFero/openfero/pkg/models"
+)
+
+// cronRun is a run started by a CronScheduler
+type cronRun struct {
+	operarius   string
+	hookMessage models.HookMessage
+}
+
+// newCronSchedulerFixture returns a scheduler for the Operarius whose status
+// updates are kept by the mock client, and the runs it starts
+func newCronSchedulerFixture(operarius *operariusv1alpha1.Operarius) (*CronScheduler, *MockOperariusClient, *[]cronRun) {
+	client := &MockOperariusClient{operarii: []operariusv1alpha1.Operarius{*operarius}}
+	client.updateStatusFn = func(_ context.Context, updated *operariusv1alpha1.Operarius) error {
+		for i := range client.operarii {
+			if client.operarii[i].Name == updated.Name {
+				client.operarii[i].Status = updated.Status
+			}
+		}
+		return nil
+	}
+
+	var runs []cronRun
+	scheduler := NewCronScheduler(NewOperariusServiceWithClient(fake.NewSimpleClientset(), client),
+		func(_ context.Context, operarius *operariusv1alpha1.Operarius, hookMessage models.HookMessage) {
+			runs = append(runs, cronRun{operarius: operarius.Name, hookMessage: hookMessage})
+		})
+	return scheduler, client, &runs
+}
+
+func cronOperariusFixture(trigger *operariusv1alpha1.CronTrigger, created time.Time) *operariusv1alpha1.Operarius {
+	operarius := dedupOperariusFixture(nil)
+	operarius.Name = "nightly-cleanup"
+	operarius.CreationTimestamp = metav1.NewTime(created)
+	operarius.Spec.AlertSelector.Labels = map[string]string{"team": "ops"}
+	operarius.Spec.CronTrigger = trigger
+	return operarius
+}
+
+func TestCronScheduler_Tick(t *testing.T) {
+	created := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	scheduler, client, runs := newCronSchedulerFixture(cronOperariusFixture(&operariusv1alpha1.CronTrigger{
+		Schedule: "0 * * * *",
+		Labels:   map[string]string{"severity": "info"},
+	}, created))
+	ctx := context.Background()
+
+	scheduler.now = func() time.Time { return created.Add(30 * time.Minute) }
+	scheduler.Tick(ctx)
+	assert.Empty(t, *runs, "not due before the first activation")
+
+	scheduler.now = func() time.Time { return created.Add(time.Hour + 5*time.Second) }
+	scheduler.Tick(ctx)
+	scheduler.Tick(ctx)
+	require.Len(t, *runs, 1, "runs once per activation")
+
+	run := (*runs)[0]
+	scheduled := created.Add(time.Hour)
+	assert.Equal(t, "nightly-cleanup", run.operarius)
+	assert.Equal(t, scheduled, client.operarii[0].Status.LastScheduleTime.Time)
+	assert.Equal(t, "firing", run.hookMessage.Status)
+	assert.Equal(t, "cron:openfero/nightly-cleanup", run.hookMessage.GroupKey)
+	assert.Equal(t, map[string]string{"alertname": "TestAlert", "team": "ops", "severity": "info"}, run.hookMessage.Alerts[0].Labels)
+	assert.Equal(t, "2024-05-01T11:00:00Z", run.hookMessage.Alerts[0].Annotations["scheduledTime"])
+	assert.NotEmpty(t, run.hookMessage.DeliveryID)
+
+	// The synthesized alert is matched by the Operarius it was created for
+	service := NewOperariusService(fake.NewSimpleClientset())
+	operarius, err := service.FindMatchingOperarius(run.hookMessage, client.operarii)
+	require.NoError(t, err)
+	assert.Equal(t, "nightly-cleanup", operarius.Name)
+}
+
+func TestCronScheduler_MissedSchedules(t *testing.T) {
+	created := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	lastSchedule := metav1.NewTime(created.Add(time.Hour))
+	now := created.Add(4*time.Hour + 20*time.Minute)
+
+	t.Run("latest missed run is started", func(t *testing.T) {
+		operarius := cronOperariusFixture(&operariusv1alpha1.CronTrigger{Schedule: "0 * * * *"}, created)
+		operarius.Status.LastScheduleTime = &lastSchedule
+		scheduler, client, runs := newCronSchedulerFixture(operarius)
+		scheduler.now = func() time.Time { return now }
+
+		scheduler.Tick(context.Background())
+		require.Len(t, *runs, 1)
+		assert.Equal(t, "2024-05-01T14:00:00Z", (*runs)[0].hookMessage.Alerts[0].Annotations["scheduledTime"])
+		assert.Equal(t, created.Add(4*time.Hour), client.operarii[0].Status.LastScheduleTime.Time)
+	})
+
+	t.Run("run later than the starting deadline is skipped", func(t *testing.T) {
+		deadline := int64(300)
+		operarius := cronOperariusFixture(&operariusv1alpha1.CronTrigger{Schedule: "0 * * * *", StartingDeadlineSeconds: &deadline}, created)
+		operarius.Status.LastScheduleTime = &lastSchedule
+		scheduler, client, runs := newCronSchedulerFixture(operarius)
+		scheduler.now = func() time.Time { return now }
+
+		scheduler.Tick(context.Background())
+		assert.Empty(t, *runs)
+		// The skipped run is recorded so it isn't reconsidered
+		assert.Equal(t, created.Add(4*time.Hour), client.operarii[0].Status.LastScheduleTime.Time)
+
+		scheduler.now = func() time.Time { return created.Add(5*time.Hour + time.Minute) }
+		scheduler.Tick(context.Background())
+		assert.Len(t, *runs, 1, "the next run within the deadline is started")
+	})
+
+	t.Run("long downtime", func(t *testing.T) {
+		operarius := cronOperariusFixture(&operariusv1alpha1.CronTrigger{Schedule: "* * * * *"}, created)
+		scheduler, _, runs := newCronSchedulerFixture(operarius)
+		scheduler.now = func() time.Time { return created.AddDate(1, 0, 0).Add(30 * time.Second) }
+
+		scheduler.Tick(context.Background())
+		require.Len(t, *runs, 1)
+		assert.Equal(t, "2025-05-01T10:00:00Z", (*runs)[0].hookMessage.Alerts[0].Annotations["scheduledTime"])
+	})
+}
+
+func TestCronScheduler_Skips(t *testing.T) {
+	created := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	invalidZone := "Mars/Olympus_Mons"
+	disabled := false
+
+	for name, operarius := range map[string]*operariusv1alpha1.Operarius{
+		"invalid schedule":  cronOperariusFixture(&operariusv1alpha1.CronTrigger{Schedule: "every hour"}, created),
+		"invalid time zone": cronOperariusFixture(&operariusv1alpha1.CronTrigger{Schedule: "0 * * * *", TimeZone: &invalidZone}, created),
+		"no cron trigger":   cronOperariusFixture(nil, created),
+		"disabled": func() *operariusv1alpha1.Operarius {
+			operarius := cronOperariusFixture(&operariusv1alpha1.CronTrigger{Schedule: "0 * * * *"}, created)
+			operarius.Spec.Enabled = &disabled
+			return operarius
+		}(),
+	} {
+		t.Run(name, func(t *testing.T) {
+			scheduler, _, runs := newCronSchedulerFixture(operarius)
+			scheduler.now = func() time.Time { return created.Add(2 * time.Hour) }
+			scheduler.Tick(context.Background())
+			assert.Empty(t, *runs)
+		})
+	}
+}
+
+func TestCronHookMessage_JobName(t *testing.T) {
+	operarius := cronOperariusFixture(&operariusv1alpha1.CronTrigger{Schedule: "0 * * * *"}, time.Now())
+	service := NewOperariusService(fake.NewSimpleClientset())
+	scheduled := time.Date(2024, 5, 1, 11, 0, 0, 0, time.UTC)
+
+	// Replicas running the same schedule create the same Job
+	first, err := service.BuildJobFromOperarius(operarius, CronHookMessage(operarius, scheduled), JobOptions{Trigger: TriggerCron})
+	require.NoError(t, err)
+	second, err := service.BuildJobFromOperarius(operarius, CronHookMessage(operarius, scheduled), JobOptions{Trigger: TriggerCron})
+	require.NoError(t, err)
+	assert.NotEmpty(t, first.Name)
+	assert.Equal(t, first.Name, second.Name)
+	assert.Equal(t, TriggerCron, first.Labels[TriggerLabel])
+
+	next, err := service.BuildJobFromOperarius(operarius, CronHookMessage(operarius, scheduled.Add(time.Hour)), JobOptions{Trigger: TriggerCron})
+	require.NoError(t, err)
+	assert.NotEqual(t, first.Name, next.Name)
+}
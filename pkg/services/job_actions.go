@@ -0,0 +1,180 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	operariusv1alpha1 "github.com/OpenFero/openfero/api/v1alpha1"
+	"github.com/OpenFero/openfero/pkg/alertstore"
+	k8sclient "github.com/OpenFero/openfero/pkg/kubernetes"
+	log "github.com/OpenFero/openfero/pkg/logging"
+	"github.com/OpenFero/openfero/pkg/models"
+)
+
+// ErrJobAlreadyFinished is returned when cancelling a Job that has already completed or failed
+var ErrJobAlreadyFinished = errors.New("job has already finished")
+
+// ErrJobNotFailed is returned when retrying a Job that has not failed
+var ErrJobNotFailed = errors.New("only failed jobs can be retried")
+
+// ExecutionStatusCancelled is the LastExecutionStatus of an Operarius whose last Job was cancelled
+const ExecutionStatusCancelled = "Cancelled"
+
+// Annotations linking a Job created by an operator action to its origin
+const (
+	RetryOfAnnotation = "openfero.io/retry-of"
+	RerunOfAnnotation = "openfero.io/rerun-of-alert"
+)
+
+// CancelJob stops a running Job created by OpenFero by deleting it together with its pods
+func (s *OperariusService) CancelJob(ctx context.Context, namespace, name string) (*batchv1.Job, error) {
+	job, err := s.GetManagedJob(ctx, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	if k8sclient.IsJobFinished(job) {
+		return nil, ErrJobAlreadyFinished
+	}
+
+	propagation := metav1.DeletePropagationBackground
+	if err := s.kubeClient.BatchV1().Jobs(namespace).Delete(ctx, name, metav1.DeleteOptions{
+		PropagationPolicy: &propagation,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to delete job: %w", err)
+	}
+
+	return job, nil
+}
+
+// RetryJob creates a new Job with the same rendered spec as a failed Job created by OpenFero
+func (s *OperariusService) RetryJob(ctx context.Context, namespace, name string) (*batchv1.Job, error) {
+	job, err := s.GetManagedJob(ctx, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	if !k8sclient.IsJobFailed(job) {
+		return nil, ErrJobNotFailed
+	}
+
+	createdJob, err := s.kubeClient.BatchV1().Jobs(namespace).Create(ctx, retryJobFrom(job), metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create job: %w", err)
+	}
+
+	return createdJob, nil
+}
+
+// retryJobFrom copies a Job's rendered spec into a new Job, dropping everything
+// the API server generated for the original
+func retryJobFrom(job *batchv1.Job) *batchv1.Job {
+	generateName := job.Name + "-"
+	if operariusName := job.Labels["openfero.io/operarius"]; operariusName != "" {
+		generateName = operariusName + "-"
+	}
+
+	retry := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: generateName,
+			Namespace:    job.Namespace,
+			Labels:       withoutControllerLabels(job.Labels),
+			Annotations:  maps.Clone(job.Annotations),
+		},
+		Spec: *job.Spec.DeepCopy(),
+	}
+	if retry.Annotations == nil {
+		retry.Annotations = make(map[string]string)
+	}
+	retry.Labels[TriggerLabel] = TriggerRetry
+	retry.Annotations[RetryOfAnnotation] = job.Name
+
+	// The selector and its labels are generated per Job
+	retry.Spec.Selector = nil
+	retry.Spec.ManualSelector = nil
+	retry.Spec.Template.Labels = withoutControllerLabels(retry.Spec.Template.Labels)
+
+	return retry
+}
+
+// withoutControllerLabels copies labels without those the Job controller sets
+func withoutControllerLabels(labels map[string]string) map[string]string {
+	result := make(map[string]string, len(labels))
+	for key, value := range labels {
+		switch key {
+		case batchv1.ControllerUidLabel, batchv1.JobNameLabel, "controller-uid", "job-name":
+			continue
+		}
+		result[key] = value
+	}
+	return result
+}
+
+// UpdateOperariusCancelledStatus marks the last execution of an Operarius as
+// cancelled if jobName is its last executed Job
+func (s *OperariusService) UpdateOperariusCancelledStatus(ctx context.Context, operarius *operariusv1alpha1.Operarius, jobName string) error {
+	if s.operariusClient == nil {
+		log.Debug("No Operarius client configured, skipping cancelled status update")
+		return nil
+	}
+	if operarius.Status.LastExecutedJobName != jobName || operarius.Status.LastExecutionStatus == ExecutionStatusCancelled {
+		return nil
+	}
+
+	updated := operarius.DeepCopy()
+	updated.Status.LastExecutionStatus = ExecutionStatusCancelled
+
+	if err := s.operariusClient.UpdateStatus(ctx, updated); err != nil {
+		return fmt.Errorf("failed to update Operarius cancelled status: %w", err)
+	}
+
+	log.Info("Updated Operarius status after cancelling job",
+		"operarius", operarius.Name,
+		"job", jobName)
+
+	if s.broadcaster != nil {
+		s.broadcaster(*updated)
+	}
+
+	return nil
+}
+
+// HookMessageFromAlertEntry rebuilds the webhook message for a single stored alert
+func HookMessageFromAlertEntry(entry alertstore.AlertEntry) models.HookMessage {
+	alert := models.Alert{
+		Labels:       entry.Alert.Labels,
+		Annotations:  entry.Alert.Annotations,
+		StartsAt:     entry.Alert.StartsAt,
+		EndsAt:       entry.Alert.EndsAt,
+		Status:       entry.Alert.Status,
+		GeneratorURL: entry.Alert.GeneratorURL,
+		Fingerprint:  entry.Alert.Fingerprint,
+	}
+	return models.HookMessage{
+		GroupKey:          "alertstore:" + entry.ID,
+		Status:            entry.Status,
+		CommonLabels:      entry.Alert.Labels,
+		CommonAnnotations: entry.Alert.Annotations,
+		Alerts:            []models.Alert{alert},
+	}
+}
+
+// UpdateAlertJobStatus sets the execution status of a job on all stored alerts that triggered it
+func UpdateAlertJobStatus(alertStore alertstore.Store, namespace, jobName, status string) {
+	err := alertStore.UpdateJobInfo(namespace, jobName, func(jobInfo *alertstore.JobInfo) bool {
+		if jobInfo.LastExecutionStatus == status {
+			return false
+		}
+		jobInfo.LastExecutionStatus = status
+		return true
+	})
+	if err != nil {
+		log.Error("Failed to update job status in alert store",
+			"job", jobName,
+			"namespace", namespace,
+			"error", err)
+	}
+}
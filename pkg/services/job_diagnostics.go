@@ -0,0 +1,156 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	corev1 "k8s.io/api/core/v1"
+
+	operariusv1alpha1 "github.com/OpenFero/openfero/api/v1alpha1"
+	"github.com/OpenFero/openfero/pkg/alertstore"
+	log "github.com/OpenFero/openfero/pkg/logging"
+)
+
+// failingWaitingReasons are container waiting reasons that mean the container
+// cannot start without outside intervention, as opposed to the transient
+// ContainerCreating/PodInitializing states every container passes through.
+var failingWaitingReasons = map[string]bool{
+	"CrashLoopBackOff":           true,
+	"ImagePullBackOff":           true,
+	"ErrImagePull":               true,
+	"InvalidImageName":           true,
+	"CreateContainerConfigError": true,
+	"CreateContainerError":       true,
+	"RunContainerError":          true,
+}
+
+// DiagnosePodFailure inspects a pod of a remediation Job and returns why it
+// failed, or nil if the pod shows no sign of failure. Terminated containers
+// are reported first since their exit code and termination message are the
+// most specific information available, followed by containers stuck waiting
+// and finally pod-level failures such as evictions or scheduling problems.
+func DiagnosePodFailure(pod *corev1.Pod) *operariusv1alpha1.ExecutionFailure {
+	statuses := make([]corev1.ContainerStatus, 0, len(pod.Status.InitContainerStatuses)+len(pod.Status.ContainerStatuses))
+	statuses = append(statuses, pod.Status.InitContainerStatuses...)
+	statuses = append(statuses, pod.Status.ContainerStatuses...)
+
+	// Containers that terminated with a non-zero exit code
+	for _, status := range statuses {
+		if terminated := status.State.Terminated; terminated != nil && terminated.ExitCode != 0 {
+			return terminationFailure(pod.Name, status.Name, terminated, terminated.Reason)
+		}
+	}
+
+	// Containers that cannot (re)start
+	for _, status := range statuses {
+		waiting := status.State.Waiting
+		if waiting == nil || !failingWaitingReasons[waiting.Reason] {
+			continue
+		}
+		// A crash-looping container's useful information is in its last run
+		if last := status.LastTerminationState.Terminated; last != nil && last.ExitCode != 0 {
+			return terminationFailure(pod.Name, status.Name, last, waiting.Reason)
+		}
+		return &operariusv1alpha1.ExecutionFailure{
+			PodName:       pod.Name,
+			ContainerName: status.Name,
+			Reason:        waiting.Reason,
+			Message:       waiting.Message,
+		}
+	}
+
+	// Pod-level failures, e.g. Evicted or DeadlineExceeded
+	if pod.Status.Phase == corev1.PodFailed {
+		reason := pod.Status.Reason
+		if reason == "" {
+			reason = "Failed"
+		}
+		return &operariusv1alpha1.ExecutionFailure{
+			PodName: pod.Name,
+			Reason:  reason,
+			Message: pod.Status.Message,
+		}
+	}
+
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodScheduled && condition.Status == corev1.ConditionFalse && condition.Reason == corev1.PodReasonUnschedulable {
+			return &operariusv1alpha1.ExecutionFailure{
+				PodName: pod.Name,
+				Reason:  condition.Reason,
+				Message: condition.Message,
+			}
+		}
+	}
+
+	return nil
+}
+
+// terminationFailure builds an ExecutionFailure from a container's terminated state
+func terminationFailure(podName, containerName string, terminated *corev1.ContainerStateTerminated, reason string) *operariusv1alpha1.ExecutionFailure {
+	if reason == "" {
+		reason = "Error"
+	}
+	exitCode := terminated.ExitCode
+	return &operariusv1alpha1.ExecutionFailure{
+		PodName:       podName,
+		ContainerName: containerName,
+		Reason:        reason,
+		ExitCode:      &exitCode,
+		Message:       terminated.Message,
+	}
+}
+
+// ToAlertStoreJobFailure converts an ExecutionFailure to the alert store representation
+func ToAlertStoreJobFailure(failure *operariusv1alpha1.ExecutionFailure) *alertstore.JobFailure {
+	if failure == nil {
+		return nil
+	}
+	return &alertstore.JobFailure{
+		PodName:       failure.PodName,
+		ContainerName: failure.ContainerName,
+		Reason:        failure.Reason,
+		ExitCode:      failure.ExitCode,
+		Message:       failure.Message,
+	}
+}
+
+// RecordJobFailure records failure diagnostics observed on a pod of the given
+// job in the Operarius status. Only the last execution is tracked, so failures
+// of older jobs are ignored, as are repeated observations of the same failure.
+func (s *OperariusService) RecordJobFailure(ctx context.Context, operarius *operariusv1alpha1.Operarius, jobName string, failure *operariusv1alpha1.ExecutionFailure) error {
+	if s.operariusClient == nil || failure == nil {
+		return nil
+	}
+
+	if operarius.Status.LastExecutedJobName != jobName {
+		log.Debug("Ignoring failure of job that is not the last execution",
+			"operarius", operarius.Name,
+			"job", jobName,
+			"lastExecutedJob", operarius.Status.LastExecutedJobName)
+		return nil
+	}
+
+	if reflect.DeepEqual(operarius.Status.LastFailure, failure) {
+		return nil
+	}
+
+	updated := operarius.DeepCopy()
+	updated.Status.LastFailure = failure
+
+	if err := s.operariusClient.UpdateStatus(ctx, updated); err != nil {
+		return fmt.Errorf("failed to update Operarius failure status: %w", err)
+	}
+
+	log.Info("Recorded remediation job failure",
+		"operarius", operarius.Name,
+		"job", jobName,
+		"pod", failure.PodName,
+		"reason", failure.Reason)
+
+	if s.broadcaster != nil {
+		s.broadcaster(*updated)
+	}
+
+	return nil
+}
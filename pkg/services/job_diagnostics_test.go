@@ -0,0 +1,269 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	operariusv1alpha1 "github.com/OpenFero/openfero/api/v1alpha1"
+)
+
+func int32Ptr(i int32) *int32 {
+	return &i
+}
+
+func TestDiagnosePodFailure(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   corev1.PodStatus
+		expected *operariusv1alpha1.ExecutionFailure
+	}{
+		{
+			name: "running pod is not a failure",
+			status: corev1.PodStatus{
+				Phase: corev1.PodRunning,
+				ContainerStatuses: []corev1.ContainerStatus{
+					{Name: "remediation", State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}},
+				},
+			},
+			expected: nil,
+		},
+		{
+			name: "container creating is not a failure",
+			status: corev1.PodStatus{
+				Phase: corev1.PodPending,
+				ContainerStatuses: []corev1.ContainerStatus{
+					{Name: "remediation", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ContainerCreating"}}},
+				},
+			},
+			expected: nil,
+		},
+		{
+			name: "successful container is not a failure",
+			status: corev1.PodStatus{
+				Phase: corev1.PodSucceeded,
+				ContainerStatuses: []corev1.ContainerStatus{
+					{Name: "remediation", State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 0, Reason: "Completed"}}},
+				},
+			},
+			expected: nil,
+		},
+		{
+			name: "image pull back-off",
+			status: corev1.PodStatus{
+				Phase: corev1.PodPending,
+				ContainerStatuses: []corev1.ContainerStatus{
+					{Name: "remediation", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{
+						Reason:  "ImagePullBackOff",
+						Message: `Back-off pulling image "does-not-exist"`,
+					}}},
+				},
+			},
+			expected: &operariusv1alpha1.ExecutionFailure{
+				PodName:       "job-pod",
+				ContainerName: "remediation",
+				Reason:        "ImagePullBackOff",
+				Message:       `Back-off pulling image "does-not-exist"`,
+			},
+		},
+		{
+			name: "OOM killed container",
+			status: corev1.PodStatus{
+				Phase: corev1.PodFailed,
+				ContainerStatuses: []corev1.ContainerStatus{
+					{Name: "remediation", State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 137, Reason: "OOMKilled"}}},
+				},
+			},
+			expected: &operariusv1alpha1.ExecutionFailure{
+				PodName:       "job-pod",
+				ContainerName: "remediation",
+				Reason:        "OOMKilled",
+				ExitCode:      int32Ptr(137),
+			},
+		},
+		{
+			name: "non-zero exit code with termination message",
+			status: corev1.PodStatus{
+				Phase: corev1.PodFailed,
+				ContainerStatuses: []corev1.ContainerStatus{
+					{Name: "remediation", State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+						ExitCode: 2,
+						Message:  "quota not found",
+					}}},
+				},
+			},
+			expected: &operariusv1alpha1.ExecutionFailure{
+				PodName:       "job-pod",
+				ContainerName: "remediation",
+				Reason:        "Error",
+				ExitCode:      int32Ptr(2),
+				Message:       "quota not found",
+			},
+		},
+		{
+			name: "failing init container",
+			status: corev1.PodStatus{
+				Phase: corev1.PodPending,
+				InitContainerStatuses: []corev1.ContainerStatus{
+					{Name: "init", State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 1, Reason: "Error"}}},
+				},
+				ContainerStatuses: []corev1.ContainerStatus{
+					{Name: "remediation", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "PodInitializing"}}},
+				},
+			},
+			expected: &operariusv1alpha1.ExecutionFailure{
+				PodName:       "job-pod",
+				ContainerName: "init",
+				Reason:        "Error",
+				ExitCode:      int32Ptr(1),
+			},
+		},
+		{
+			name: "crash loop reports last termination",
+			status: corev1.PodStatus{
+				Phase: corev1.PodRunning,
+				ContainerStatuses: []corev1.ContainerStatus{
+					{
+						Name:  "remediation",
+						State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+						LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+							ExitCode: 3,
+							Message:  "connection refused",
+						}},
+					},
+				},
+			},
+			expected: &operariusv1alpha1.ExecutionFailure{
+				PodName:       "job-pod",
+				ContainerName: "remediation",
+				Reason:        "CrashLoopBackOff",
+				ExitCode:      int32Ptr(3),
+				Message:       "connection refused",
+			},
+		},
+		{
+			name: "evicted pod",
+			status: corev1.PodStatus{
+				Phase:   corev1.PodFailed,
+				Reason:  "Evicted",
+				Message: "The node was low on resource: memory.",
+			},
+			expected: &operariusv1alpha1.ExecutionFailure{
+				PodName: "job-pod",
+				Reason:  "Evicted",
+				Message: "The node was low on resource: memory.",
+			},
+		},
+		{
+			name: "unschedulable pod",
+			status: corev1.PodStatus{
+				Phase: corev1.PodPending,
+				Conditions: []corev1.PodCondition{
+					{
+						Type:    corev1.PodScheduled,
+						Status:  corev1.ConditionFalse,
+						Reason:  corev1.PodReasonUnschedulable,
+						Message: "0/3 nodes are available: 3 Insufficient cpu.",
+					},
+				},
+			},
+			expected: &operariusv1alpha1.ExecutionFailure{
+				PodName: "job-pod",
+				Reason:  "Unschedulable",
+				Message: "0/3 nodes are available: 3 Insufficient cpu.",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "job-pod", Namespace: "openfero"},
+				Status:     tt.status,
+			}
+			assert.Equal(t, tt.expected, DiagnosePodFailure(pod))
+		})
+	}
+}
+
+func TestRecordJobFailure(t *testing.T) {
+	failure := &operariusv1alpha1.ExecutionFailure{
+		PodName:       "remediation-abc-xyz",
+		ContainerName: "remediation",
+		Reason:        "OOMKilled",
+		ExitCode:      int32Ptr(137),
+	}
+
+	t.Run("records failure of last execution", func(t *testing.T) {
+		var updated *operariusv1alpha1.Operarius
+		var broadcast *operariusv1alpha1.Operarius
+		mockClient := &MockOperariusClient{
+			updateStatusFn: func(ctx context.Context, op *operariusv1alpha1.Operarius) error {
+				updated = op
+				return nil
+			},
+		}
+		service := NewOperariusServiceWithClient(fake.NewSimpleClientset(), mockClient)
+		service.SetBroadcaster(func(op operariusv1alpha1.Operarius) {
+			broadcast = &op
+		})
+
+		operarius := &operariusv1alpha1.Operarius{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-operarius", Namespace: "openfero"},
+			Status:     operariusv1alpha1.OperariusStatus{LastExecutedJobName: "remediation-abc"},
+		}
+
+		require.NoError(t, service.RecordJobFailure(context.Background(), operarius, "remediation-abc", failure))
+		require.NotNil(t, updated)
+		assert.Equal(t, failure, updated.Status.LastFailure)
+		require.NotNil(t, broadcast)
+		assert.Equal(t, "OOMKilled", broadcast.Status.LastFailure.Reason)
+		assert.Nil(t, operarius.Status.LastFailure, "the cached Operarius must not be modified")
+	})
+
+	t.Run("ignores failures of older jobs", func(t *testing.T) {
+		calls := 0
+		mockClient := &MockOperariusClient{
+			updateStatusFn: func(ctx context.Context, op *operariusv1alpha1.Operarius) error {
+				calls++
+				return nil
+			},
+		}
+		service := NewOperariusServiceWithClient(fake.NewSimpleClientset(), mockClient)
+
+		operarius := &operariusv1alpha1.Operarius{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-operarius", Namespace: "openfero"},
+			Status:     operariusv1alpha1.OperariusStatus{LastExecutedJobName: "remediation-new"},
+		}
+
+		require.NoError(t, service.RecordJobFailure(context.Background(), operarius, "remediation-old", failure))
+		assert.Equal(t, 0, calls)
+	})
+
+	t.Run("skips unchanged failure", func(t *testing.T) {
+		calls := 0
+		mockClient := &MockOperariusClient{
+			updateStatusFn: func(ctx context.Context, op *operariusv1alpha1.Operarius) error {
+				calls++
+				return nil
+			},
+		}
+		service := NewOperariusServiceWithClient(fake.NewSimpleClientset(), mockClient)
+
+		operarius := &operariusv1alpha1.Operarius{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-operarius", Namespace: "openfero"},
+			Status: operariusv1alpha1.OperariusStatus{
+				LastExecutedJobName: "remediation-abc",
+				LastFailure:         failure.DeepCopy(),
+			},
+		}
+
+		require.NoError(t, service.RecordJobFailure(context.Background(), operarius, "remediation-abc", failure))
+		assert.Equal(t, 0, calls)
+	})
+}
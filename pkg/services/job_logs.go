@@ -0,0 +1,68 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ErrJobNotManaged is returned when a Job exists but was not created by OpenFero.
+// OpenFero only exposes Jobs labeled openfero.io/managed-by=openfero so that
+// its API cannot be used to read arbitrary workloads in the namespace.
+var ErrJobNotManaged = errors.New("job is not managed by OpenFero")
+
+// ErrJobHasNoPods is returned when the logs of a Job are requested before any
+// of its pods has been created.
+var ErrJobHasNoPods = errors.New("job has no pods yet")
+
+// GetManagedJob retrieves a Job created by OpenFero. Kubernetes errors are
+// wrapped, so callers can still detect them via k8s.io/apimachinery/pkg/api/errors.
+func (s *OperariusService) GetManagedJob(ctx context.Context, namespace, name string) (*batchv1.Job, error) {
+	job, err := s.kubeClient.BatchV1().Jobs(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job: %w", err)
+	}
+
+	if job.Labels["openfero.io/managed-by"] != "openfero" {
+		return nil, ErrJobNotManaged
+	}
+
+	return job, nil
+}
+
+// StreamJobLogs opens a log stream for the latest pod of a Job created by
+// OpenFero. An empty container selects the pod's first container. With follow
+// set, the stream stays open until the container exits or ctx is cancelled.
+func (s *OperariusService) StreamJobLogs(ctx context.Context, namespace, name, container string, follow bool) (io.ReadCloser, error) {
+	job, err := s.GetManagedJob(ctx, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+
+	pod, err := s.lastJobPod(ctx, job)
+	if err != nil {
+		return nil, err
+	}
+	if pod == nil {
+		return nil, ErrJobHasNoPods
+	}
+
+	if container == "" && len(pod.Spec.Containers) > 0 {
+		container = pod.Spec.Containers[0].Name
+	}
+
+	stream, err := s.kubeClient.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+		Container: container,
+		Follow:    follow,
+	}).Stream(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stream logs of pod %s: %w", pod.Name, err)
+	}
+
+	return stream, nil
+}
@@ -0,0 +1,205 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/OpenFero/openfero/pkg/alertstore"
+	k8sclient "github.com/OpenFero/openfero/pkg/kubernetes"
+	log "github.com/OpenFero/openfero/pkg/logging"
+)
+
+// maxTerminationMessageBytes bounds the captured termination messages. The
+// kubelet already limits a single container's message to 4096 bytes; this
+// keeps pods with several containers within the same budget.
+const maxTerminationMessageBytes = 4096
+
+// captureJobOutputTimeout bounds reading the pods and logs of a finished
+// job, so a stuck log stream doesn't keep the capture running forever
+const captureJobOutputTimeout = 30 * time.Second
+
+// maxLogTailLines bounds the lines of a container's log requested for the
+// tail. Every line has at least one byte, so requesting as many lines as
+// bytes are kept never misses any of them; the cap only matters for large
+// tails of short lines.
+const maxLogTailLines = 10000
+
+// logReadFactor bounds the bytes of a container's log read for the tail as
+// a multiple of the tail, in case the requested lines are very long
+const logReadFactor = 8
+
+// CaptureJobOutput collects what a finished job reported: the termination
+// messages of its last pod and, if logTailBytes is greater than zero, the
+// last logTailBytes bytes of that pod's logs.
+func (s *OperariusService) CaptureJobOutput(ctx context.Context, job *batchv1.Job, logTailBytes int64) (*alertstore.JobOutput, error) {
+	ctx, cancel := context.WithTimeout(ctx, captureJobOutputTimeout)
+	defer cancel()
+
+	pod, err := s.lastJobPod(ctx, job)
+	if err != nil {
+		return nil, err
+	}
+	if pod == nil {
+		return nil, fmt.Errorf("no pods found for job %s", job.Name)
+	}
+
+	output := &alertstore.JobOutput{
+		PodName:            pod.Name,
+		TerminationMessage: terminationMessages(pod),
+		CapturedAt:         time.Now(),
+	}
+
+	if logTailBytes > 0 {
+		tail := newTailBuffer(logTailBytes)
+		for _, container := range pod.Spec.Containers {
+			if err := s.copyContainerLogs(ctx, pod, container.Name, logTailBytes, len(pod.Spec.Containers) > 1, tail); err != nil {
+				// Logs are best-effort, the termination message is still useful on its own
+				log.Warn("Failed to capture job logs",
+					"job", job.Name,
+					"pod", pod.Name,
+					"container", container.Name,
+					"error", err)
+			}
+		}
+		output.Logs = tail.String()
+		output.LogsTruncated = tail.Truncated()
+	}
+
+	return output, nil
+}
+
+// lastJobPod returns the most recently created pod of a job, which belongs to
+// the job's final attempt. Pods are found by the legacy job-name label if no
+// pod has the current one, like JobNameForPod does.
+func (s *OperariusService) lastJobPod(ctx context.Context, job *batchv1.Job) (*corev1.Pod, error) {
+	for _, label := range []string{batchv1.JobNameLabel, k8sclient.LegacyJobNameLabel} {
+		selector := labels.Set{label: job.Name}.AsSelector()
+		pods, err := s.kubeClient.CoreV1().Pods(job.Namespace).List(ctx, metav1.ListOptions{
+			LabelSelector: selector.String(),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list pods of job %s: %w", job.Name, err)
+		}
+		if len(pods.Items) == 0 {
+			continue
+		}
+
+		sort.Slice(pods.Items, func(i, j int) bool {
+			return pods.Items[i].CreationTimestamp.After(pods.Items[j].CreationTimestamp.Time)
+		})
+		return &pods.Items[0], nil
+	}
+	return nil, nil
+}
+
+// copyContainerLogs streams the end of a container's logs into w, enough to
+// fill a tail of tailBytes, optionally preceded by a header naming the container
+func (s *OperariusService) copyContainerLogs(ctx context.Context, pod *corev1.Pod, container string, tailBytes int64, prefix bool, w io.Writer) error {
+	tailLines := min(tailBytes, maxLogTailLines)
+	limitBytes := tailBytes * logReadFactor
+	stream, err := s.kubeClient.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+		Container:  container,
+		TailLines:  &tailLines,
+		LimitBytes: &limitBytes,
+	}).Stream(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := stream.Close(); err != nil {
+			log.Debug("Failed to close log stream", "pod", pod.Name, "error", err)
+		}
+	}()
+
+	if prefix {
+		if _, err := fmt.Fprintf(w, "==> %s <==\n", container); err != nil {
+			return err
+		}
+	}
+	_, err = io.Copy(w, stream)
+	return err
+}
+
+// terminationMessages joins the termination messages of a pod's containers
+func terminationMessages(pod *corev1.Pod) string {
+	var messages []string
+	for _, status := range pod.Status.ContainerStatuses {
+		terminated := status.State.Terminated
+		if terminated == nil || terminated.Message == "" {
+			continue
+		}
+		if len(pod.Status.ContainerStatuses) > 1 {
+			messages = append(messages, fmt.Sprintf("%s: %s", status.Name, terminated.Message))
+		} else {
+			messages = append(messages, terminated.Message)
+		}
+	}
+
+	message := strings.Join(messages, "\n")
+	if len(message) > maxTerminationMessageBytes {
+		// Cut before the rune at the limit, not within it
+		end := maxTerminationMessageBytes
+		for end > 0 && !utf8.RuneStart(message[end]) {
+			end--
+		}
+		message = message[:end]
+	}
+	return message
+}
+
+// tailBuffer is an io.Writer that keeps only the last max bytes written to it
+type tailBuffer struct {
+	buf       []byte
+	max       int64
+	truncated bool
+}
+
+func newTailBuffer(max int64) *tailBuffer {
+	return &tailBuffer{max: max}
+}
+
+func (t *tailBuffer) Write(p []byte) (int, error) {
+	t.buf = append(t.buf, p...)
+	if overflow := int64(len(t.buf)) - t.max; overflow > 0 {
+		t.truncated = true
+		t.buf = append(t.buf[:0], t.buf[overflow:]...)
+	}
+	return len(p), nil
+}
+
+func (t *tailBuffer) String() string {
+	return string(t.buf)
+}
+
+// Truncated reports whether any bytes were dropped
+func (t *tailBuffer) Truncated() bool {
+	return t.truncated
+}
+
+// UpdateAlertJobOutput attaches the captured output of a job to all stored alerts that triggered it
+func UpdateAlertJobOutput(alertStore alertstore.Store, namespace, jobName string, output *alertstore.JobOutput) {
+	err := alertStore.UpdateJobInfo(namespace, jobName, func(jobInfo *alertstore.JobInfo) bool {
+		if reflect.DeepEqual(jobInfo.Output, output) {
+			return false
+		}
+		jobInfo.Output = output
+		return true
+	})
+	if err != nil {
+		log.Error("Failed to update job output in alert store",
+			"job", jobName,
+			"namespace", namespace,
+			"error", err)
+	}
+}
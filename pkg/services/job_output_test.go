@@ -0,0 +1,150 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/OpenFero/openfero/pkg/alertstore"
+	"github.com/OpenFero/openfero/pkg/alertstore/memory"
+	k8sclient "github.com/OpenFero/openfero/pkg/kubernetes"
+)
+
+func jobPodFixture(name, jobName string, created time.Time, message string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "openfero",
+			Labels:            map[string]string{batchv1.JobNameLabel: jobName},
+			CreationTimestamp: metav1.NewTime(created),
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "remediation", Image: "busybox"}},
+		},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{
+				{
+					Name: "remediation",
+					State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+						ExitCode: 0,
+						Message:  message,
+					}},
+				},
+			},
+		},
+	}
+}
+
+func TestCaptureJobOutput(t *testing.T) {
+	now := time.Now()
+	kubeClient := fake.NewSimpleClientset(
+		jobPodFixture("remediation-abc-1", "remediation-abc", now.Add(-time.Minute), "first attempt"),
+		jobPodFixture("remediation-abc-2", "remediation-abc", now, "scaled deployment to 3 replicas"),
+		jobPodFixture("other-job-1", "other-job", now.Add(time.Minute), "unrelated"),
+	)
+	service := NewOperariusService(kubeClient)
+	job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "remediation-abc", Namespace: "openfero"}}
+
+	t.Run("termination message only", func(t *testing.T) {
+		output, err := service.CaptureJobOutput(context.Background(), job, 0)
+		require.NoError(t, err)
+		assert.Equal(t, "remediation-abc-2", output.PodName, "the last attempt's pod should be used")
+		assert.Equal(t, "scaled deployment to 3 replicas", output.TerminationMessage)
+		assert.Empty(t, output.Logs)
+	})
+
+	t.Run("with log tail", func(t *testing.T) {
+		output, err := service.CaptureJobOutput(context.Background(), job, 1024)
+		require.NoError(t, err)
+		// The fake clientset serves "fake logs" for every container
+		assert.Equal(t, "fake logs", output.Logs)
+		assert.False(t, output.LogsTruncated)
+
+		// Only the end of the logs is requested
+		var options *corev1.PodLogOptions
+		for _, action := range kubeClient.Actions() {
+			if action.GetSubresource() == "log" {
+				options = action.(k8stesting.GenericAction).GetValue().(*corev1.PodLogOptions)
+			}
+		}
+		require.NotNil(t, options)
+		require.NotNil(t, options.TailLines)
+		assert.Equal(t, int64(1024), *options.TailLines)
+		require.NotNil(t, options.LimitBytes)
+		assert.Equal(t, int64(1024*logReadFactor), *options.LimitBytes)
+	})
+
+	t.Run("log tail is bounded", func(t *testing.T) {
+		output, err := service.CaptureJobOutput(context.Background(), job, 4)
+		require.NoError(t, err)
+		assert.Equal(t, "logs", output.Logs)
+		assert.True(t, output.LogsTruncated)
+	})
+
+	t.Run("legacy job-name label", func(t *testing.T) {
+		legacyPod := jobPodFixture("legacy-job-1", "legacy-job", now, "done")
+		legacyPod.Labels = map[string]string{k8sclient.LegacyJobNameLabel: "legacy-job"}
+		service := NewOperariusService(fake.NewSimpleClientset(legacyPod))
+		legacy := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "legacy-job", Namespace: "openfero"}}
+
+		output, err := service.CaptureJobOutput(context.Background(), legacy, 0)
+		require.NoError(t, err)
+		assert.Equal(t, "legacy-job-1", output.PodName)
+	})
+
+	t.Run("job without pods", func(t *testing.T) {
+		missing := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "missing", Namespace: "openfero"}}
+		_, err := service.CaptureJobOutput(context.Background(), missing, 0)
+		assert.Error(t, err)
+	})
+}
+
+func TestTerminationMessages_Bounded(t *testing.T) {
+	pod := jobPodFixture("pod", "job", time.Now(), strings.Repeat("x", maxTerminationMessageBytes+100))
+	assert.Len(t, terminationMessages(pod), maxTerminationMessageBytes)
+
+	// A rune crossing the limit is dropped as a whole
+	pod = jobPodFixture("pod", "job", time.Now(), strings.Repeat("x", maxTerminationMessageBytes-1)+"€")
+	message := terminationMessages(pod)
+	assert.Len(t, message, maxTerminationMessageBytes-1)
+	assert.True(t, utf8.ValidString(message))
+}
+
+func TestTailBuffer(t *testing.T) {
+	tail := newTailBuffer(10)
+	_, _ = tail.Write([]byte("0123456789"))
+	assert.Equal(t, "0123456789", tail.String())
+	assert.False(t, tail.Truncated())
+
+	_, _ = tail.Write([]byte("abc"))
+	assert.Equal(t, "3456789abc", tail.String())
+	assert.True(t, tail.Truncated())
+
+	_, _ = tail.Write([]byte(strings.Repeat("z", 25)))
+	assert.Equal(t, strings.Repeat("z", 10), tail.String())
+}
+
+func TestUpdateAlertJobOutput(t *testing.T) {
+	store := memory.NewMemoryStore(10)
+	alert := alertstore.Alert{Labels: map[string]string{"alertname": "TestAlert"}}
+	require.NoError(t, store.SaveAlertWithJobInfo(alert, "firing", &alertstore.JobInfo{JobName: "remediation-abc", Namespace: "openfero"}))
+
+	output := &alertstore.JobOutput{TerminationMessage: "done", CapturedAt: time.Now()}
+	UpdateAlertJobOutput(store, "openfero", "remediation-abc", output)
+
+	entries, err := store.GetAlerts("", 0)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.NotNil(t, entries[0].JobInfo.Output)
+	assert.Equal(t, "done", entries[0].JobInfo.Output.TerminationMessage)
+}
@@ -0,0 +1,190 @@
+package services
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/OpenFero/openfero/pkg/alertstore"
+	k8sclient "github.com/OpenFero/openfero/pkg/kubernetes"
+	log "github.com/OpenFero/openfero/pkg/logging"
+	"github.com/OpenFero/openfero/pkg/metadata"
+	"github.com/OpenFero/openfero/pkg/models"
+)
+
+// maxTrackedLifecycles bounds the open lifecycles a LifecycleTracker keeps
+const maxTrackedLifecycles = 10000
+
+// maxTrackedJobs bounds the running Jobs a LifecycleTracker remembers
+const maxTrackedJobs = 10000
+
+// LifecycleTracker correlates the alerts received by this replica into
+// lifecycles and records their timings as metrics per Operarius. The
+// lifecycles served by the API are correlated from the store instead, so
+// they include alerts received by other replicas.
+//
+// The Jobs this replica created are remembered apart from the lifecycles,
+// their duration is observed even if their alert resolved before they
+// finished.
+type LifecycleTracker struct {
+	mu         sync.Mutex
+	correlator *alertstore.Correlator
+	jobs       map[string]time.Time // created Jobs by namespace/name
+}
+
+// NewLifecycleTracker creates a tracker without open lifecycles
+func NewLifecycleTracker() *LifecycleTracker {
+	return &LifecycleTracker{
+		correlator: alertstore.NewCorrelator(maxTrackedLifecycles),
+		jobs:       make(map[string]time.Time),
+	}
+}
+
+// Restore replays the stored alerts without recording metrics, so alerts
+// that fired before a restart are measured when they resolve
+func (t *LifecycleTracker) Restore(alertStore alertstore.Store) error {
+	result, err := alertStore.QueryAlerts(alertstore.Query{Order: alertstore.SortOldestFirst})
+	if err != nil {
+		return err
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, entry := range result.Entries {
+		t.correlator.Add(entry)
+	}
+	return nil
+}
+
+// AlertSaved adds a stored alert to its lifecycle and observes the time to
+// remediation once its first Job was created and the time to resolve once
+// it resolved
+func (t *LifecycleTracker) AlertSaved(alert models.Alert, status string, jobInfo *alertstore.JobInfo) {
+	if t == nil {
+		return
+	}
+	entry := alertstore.AlertEntry{
+		Alert:     alert.ToAlertStoreAlert(),
+		Status:    status,
+		Timestamp: time.Now(),
+		JobInfo:   jobInfo,
+	}
+	t.mu.Lock()
+	lifecycle, change := t.correlator.Add(entry)
+	t.mu.Unlock()
+
+	if change&alertstore.LifecycleRemediationStarted != 0 {
+		metadata.AlertTimeToRemediationSeconds.WithLabelValues(lifecycle.Operarius).
+			Observe(*lifecycle.TimeToRemediationStartSeconds)
+	}
+	if change&alertstore.LifecycleResolved != 0 {
+		metadata.AlertTimeToResolveSeconds.WithLabelValues(lifecycle.Operarius, strconv.FormatBool(lifecycle.Remediated)).
+			Observe(*lifecycle.TimeToResolveSeconds)
+		log.Debug("Alert resolved",
+			"fingerprint", lifecycle.Fingerprint,
+			"operarius", lifecycle.Operarius,
+			"timeToResolveSeconds", *lifecycle.TimeToResolveSeconds,
+			"remediated", lifecycle.Remediated)
+	}
+}
+
+// JobCreated remembers a Job this replica created, so its duration is
+// observed once it finished
+func (t *LifecycleTracker) JobCreated(namespace, jobName string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.jobs) >= maxTrackedJobs {
+		// Forget the oldest Job, it most likely finished unnoticed
+		oldestKey, oldest := "", time.Time{}
+		for key, created := range t.jobs {
+			if oldestKey == "" || created.Before(oldest) {
+				oldestKey, oldest = key, created
+			}
+		}
+		delete(t.jobs, oldestKey)
+	}
+	t.jobs[namespace+"/"+jobName] = time.Now()
+}
+
+// JobFinished records when a finished Job ran on its open lifecycles and
+// reports whether this replica created it. A Job is reported once.
+func (t *LifecycleTracker) JobFinished(namespace, jobName, status string, startedAt, finishedAt time.Time) bool {
+	if t == nil {
+		return false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.correlator.JobFinished(namespace, jobName, status, startedAt, finishedAt)
+
+	key := namespace + "/" + jobName
+	if _, created := t.jobs[key]; !created {
+		return false
+	}
+	delete(t.jobs, key)
+	return true
+}
+
+// RecordJobCompletion stores when a finished Job ran on the alerts that
+// triggered it and completes their lifecycles. Every replica sees the Job
+// finish, its duration is only observed by the replica that created it, so
+// it is counted once.
+func RecordJobCompletion(alertStore alertstore.Store, lifecycles *LifecycleTracker, job *batchv1.Job) {
+	status, startedAt, finishedAt := jobCompletion(job)
+
+	err := alertStore.UpdateJobInfo(job.Namespace, job.Name, func(jobInfo *alertstore.JobInfo) bool {
+		if jobInfo.FinishedAt != nil && jobInfo.LastExecutionStatus == status {
+			return false
+		}
+		jobInfo.LastExecutionStatus = status
+		jobInfo.StartedAt = &startedAt
+		jobInfo.FinishedAt = &finishedAt
+		return true
+	})
+	if err != nil {
+		log.Error("Failed to update job completion in alert store",
+			"job", job.Name,
+			"namespace", job.Namespace,
+			"error", err)
+	}
+
+	if lifecycles.JobFinished(job.Namespace, job.Name, status, startedAt, finishedAt) {
+		metadata.RemediationJobDurationSeconds.WithLabelValues(job.Labels["openfero.io/operarius"], strings.ToLower(status)).
+			Observe(finishedAt.Sub(startedAt).Seconds())
+	}
+}
+
+// jobCompletion returns the status of a finished Job and when it started
+// and finished. Failed Jobs have no completion time, the time of their
+// failure condition is used instead.
+func jobCompletion(job *batchv1.Job) (status string, startedAt, finishedAt time.Time) {
+	status = "Successful"
+	if k8sclient.IsJobFailed(job) {
+		status = "Failed"
+	}
+
+	startedAt = job.CreationTimestamp.Time
+	if job.Status.StartTime != nil {
+		startedAt = job.Status.StartTime.Time
+	}
+
+	finishedAt = time.Now()
+	if job.Status.CompletionTime != nil {
+		finishedAt = job.Status.CompletionTime.Time
+	} else {
+		for _, condition := range job.Status.Conditions {
+			if condition.Type == batchv1.JobFailed && condition.Status == corev1.ConditionTrue && !condition.LastTransitionTime.IsZero() {
+				finishedAt = condition.LastTransitionTime.Time
+			}
+		}
+	}
+	if finishedAt.Before(startedAt) {
+		finishedAt = startedAt
+	}
+	return status, startedAt, finishedAt
+}
@@ -0,0 +1,95 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/OpenFero/openfero/pkg/alertstore"
+	"github.com/OpenFero/openfero/pkg/alertstore/memory"
+	"github.com/OpenFero/openfero/pkg/metadata"
+	"github.com/OpenFero/openfero/pkg/models"
+)
+
+func TestLifecycleTracker(t *testing.T) {
+	store := memory.NewMemoryStore(10)
+	tracker := NewLifecycleTracker()
+	alert := models.Alert{
+		Labels:   map[string]string{"alertname": "LifecycleTest"},
+		StartsAt: time.Now().Add(-time.Minute).Format(time.RFC3339),
+	}
+	jobInfo := &alertstore.JobInfo{OperariusName: "lifecycle-operarius", Namespace: "openfero", JobName: "lifecycle-1"}
+
+	SaveAlertWithJobInfo(store, alert, "firing", jobInfo)
+	tracker.JobCreated("openfero", "lifecycle-1")
+	tracker.AlertSaved(alert, "firing", jobInfo)
+	assert.True(t, metadata.AlertTimeToRemediationSeconds.DeleteLabelValues("lifecycle-operarius"))
+
+	started := metav1.NewTime(time.Now().Add(-30 * time.Second))
+	completed := metav1.NewTime(time.Now())
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: "lifecycle-1", Namespace: "openfero", Labels: map[string]string{"openfero.io/operarius": "lifecycle-operarius"}},
+		Status: batchv1.JobStatus{
+			StartTime:      &started,
+			CompletionTime: &completed,
+			Conditions:     []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}},
+		},
+	}
+	RecordJobCompletion(store, tracker, job)
+	assert.True(t, metadata.RemediationJobDurationSeconds.DeleteLabelValues("lifecycle-operarius", "successful"))
+
+	entries, err := store.GetAlerts("", 0)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "Successful", entries[0].JobInfo.LastExecutionStatus)
+	require.NotNil(t, entries[0].JobInfo.FinishedAt)
+	assert.Equal(t, completed.Time, *entries[0].JobInfo.FinishedAt)
+
+	tracker.AlertSaved(alert, "resolved", nil)
+	// The alert resolved after its Job succeeded
+	assert.True(t, metadata.AlertTimeToResolveSeconds.DeleteLabelValues("lifecycle-operarius", "true"))
+
+	// Jobs created by other replicas are observed there
+	job.Name = "lifecycle-2"
+	RecordJobCompletion(store, tracker, job)
+	assert.False(t, metadata.RemediationJobDurationSeconds.DeleteLabelValues("lifecycle-operarius", "successful"))
+}
+
+func TestLifecycleTracker_ResolvedBeforeJobFinished(t *testing.T) {
+	store := memory.NewMemoryStore(10)
+	tracker := NewLifecycleTracker()
+	alert := models.Alert{
+		Labels:   map[string]string{"alertname": "SlowRemediation"},
+		StartsAt: time.Now().Add(-time.Minute).Format(time.RFC3339),
+	}
+	jobInfo := &alertstore.JobInfo{OperariusName: "slow-operarius", Namespace: "openfero", JobName: "slow-1"}
+
+	SaveAlertWithJobInfo(store, alert, "firing", jobInfo)
+	tracker.JobCreated("openfero", "slow-1")
+	tracker.AlertSaved(alert, "firing", jobInfo)
+	metadata.AlertTimeToRemediationSeconds.DeleteLabelValues("slow-operarius")
+
+	// The alert resolves while its Job is still running
+	tracker.AlertSaved(alert, "resolved", nil)
+	metadata.AlertTimeToResolveSeconds.DeleteLabelValues("slow-operarius", "false")
+
+	started := metav1.NewTime(time.Now().Add(-30 * time.Second))
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: "slow-1", Namespace: "openfero", Labels: map[string]string{"openfero.io/operarius": "slow-operarius"}},
+		Status: batchv1.JobStatus{
+			StartTime:  &started,
+			Conditions: []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, LastTransitionTime: metav1.Now()}},
+		},
+	}
+	RecordJobCompletion(store, tracker, job)
+	assert.True(t, metadata.RemediationJobDurationSeconds.DeleteLabelValues("slow-operarius", "failed"))
+
+	// Further updates of the finished Job aren't observed again
+	RecordJobCompletion(store, tracker, job)
+	assert.False(t, metadata.RemediationJobDurationSeconds.DeleteLabelValues("slow-operarius", "failed"))
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"maps"
+	"slices"
 	"strings"
 	"text/template"
 	"time"
@@ -15,9 +16,11 @@ import (
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/client-go/kubernetes"
 
 	operariusv1alpha1 "github.com/OpenFero/openfero/api/v1alpha1"
+	"github.com/OpenFero/openfero/pkg/alertstore"
 	k8sclient "github.com/OpenFero/openfero/pkg/kubernetes"
 	log "github.com/OpenFero/openfero/pkg/logging"
 	"github.com/OpenFero/openfero/pkg/models"
@@ -117,16 +120,23 @@ func (s *OperariusService) FindMatchingOperarius(hookMessage models.HookMessage,
 
 // matchesHookMessage checks if an Operarius matches the given hook message
 func (s *OperariusService) matchesHookMessage(operarius operariusv1alpha1.Operarius, hookMessage models.HookMessage) bool {
+	matched, _ := explainMatch(operarius, hookMessage)
+	return matched
+}
+
+// explainMatch checks if an Operarius matches the given hook message and
+// describes why it does or doesn't
+func explainMatch(operarius operariusv1alpha1.Operarius, hookMessage models.HookMessage) (bool, string) {
 	selector := operarius.Spec.AlertSelector
 
 	// Check if enabled
 	if operarius.Spec.Enabled != nil && !*operarius.Spec.Enabled {
-		return false
+		return false, "operarius is disabled"
 	}
 
 	// Check status
 	if selector.Status != hookMessage.Status {
-		return false
+		return false, fmt.Sprintf("status %q does not match selector status %q", hookMessage.Status, selector.Status)
 	}
 
 	// Check alert name - it can be in individual alerts or common labels
@@ -143,7 +153,7 @@ func (s *OperariusService) matchesHookMessage(operarius operariusv1alpha1.Operar
 	}
 
 	if selector.AlertName != alertName {
-		return false
+		return false, fmt.Sprintf("alertname %q does not match selector alertname %q", alertName, selector.AlertName)
 	}
 
 	// Check additional labels against common labels and first alert labels
@@ -155,18 +165,80 @@ func (s *OperariusService) matchesHookMessage(operarius operariusv1alpha1.Operar
 		maps.Copy(labelsToCheck, hookMessage.Alerts[0].Labels)
 	}
 
-	for key, value := range selector.Labels {
+	// Sorted, so the reported mismatch is stable
+	for _, key := range slices.Sorted(maps.Keys(selector.Labels)) {
+		value := selector.Labels[key]
 		alertValue, exists := labelsToCheck[key]
-		if !exists || alertValue != value {
-			return false
+		if !exists {
+			return false, fmt.Sprintf("label %q is missing, selector requires %q", key, value)
+		}
+		if alertValue != value {
+			return false, fmt.Sprintf("label %q is %q, selector requires %q", key, alertValue, value)
 		}
 	}
 
-	return true
+	if len(selector.Labels) > 0 {
+		return true, fmt.Sprintf("status, alertname and %d selector labels match", len(selector.Labels))
+	}
+	return true, "status and alertname match"
+}
+
+// TriggerLabel records on a Job what caused OpenFero to create it. Jobs
+// created for incoming alerts don't carry it.
+const TriggerLabel = "openfero.io/trigger"
+
+// DeliveryIDLabel records on a Job the webhook delivery it was created for
+const DeliveryIDLabel = "openfero.io/delivery-id"
+
+// FingerprintLabel records on a Job the fingerprint of the alert it was rendered for
+const FingerprintLabel = "openfero.io/fingerprint"
+
+// Values of TriggerLabel
+const (
+	TriggerRetry  = "retry"
+	TriggerRerun  = "rerun"
+	TriggerManual = "manual"
+	TriggerCron   = "cron"
+)
+
+// JobOptions adjusts how CreateJobFromOperariusWithOptions creates a Job
+type JobOptions struct {
+	// Trigger is recorded in the TriggerLabel of the Job if set
+	Trigger string
+	// IgnoreDeduplication always generates a fresh Job name, even if the
+	// Operarius uses time-based deduplication. Used for explicit operator actions.
+	IgnoreDeduplication bool
+	// Annotations are added to the Job
+	Annotations map[string]string
 }
 
 // CreateJobFromOperarius creates a Kubernetes Job from an Operarius CRD
 func (s *OperariusService) CreateJobFromOperarius(ctx context.Context, operarius *operariusv1alpha1.Operarius, hookMessage models.HookMessage) (*batchv1.Job, error) {
+	return s.CreateJobFromOperariusWithOptions(ctx, operarius, hookMessage, JobOptions{})
+}
+
+// CreateJobFromOperariusWithOptions creates a Kubernetes Job from an Operarius CRD
+func (s *OperariusService) CreateJobFromOperariusWithOptions(ctx context.Context, operarius *operariusv1alpha1.Operarius, hookMessage models.HookMessage, opts JobOptions) (*batchv1.Job, error) {
+	job, err := s.BuildJobFromOperarius(operarius, hookMessage, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create the job in Kubernetes
+	createdJob, err := s.kubeClient.BatchV1().Jobs(job.Namespace).Create(ctx, job, metav1.CreateOptions{})
+	if err != nil {
+		if job.Name != "" && k8serrors.IsAlreadyExists(err) {
+			return nil, ErrJobDeduplicated
+		}
+		return nil, fmt.Errorf("failed to create job: %w", err)
+	}
+
+	return createdJob, nil
+}
+
+// BuildJobFromOperarius renders the Job an Operarius would create for a hook
+// message, without creating it
+func (s *OperariusService) BuildJobFromOperarius(operarius *operariusv1alpha1.Operarius, hookMessage models.HookMessage, opts JobOptions) (*batchv1.Job, error) {
 	// Deep copy the job template to avoid modifying the original
 	jobTemplate := operarius.Spec.JobTemplate.DeepCopy()
 
@@ -195,8 +267,17 @@ func (s *OperariusService) CreateJobFromOperarius(ctx context.Context, operarius
 	// list-based check will compute the same name; the API server allows only
 	// one of the resulting Create calls to succeed, so the race is closed
 	// atomically instead of relying on the advisory pre-check alone.
-	if dedup := operarius.Spec.Deduplication; dedup != nil && dedup.Enabled && dedup.TTL > 0 {
-		job.Name = dedupJobName(operarius.Name, hookMessage.GroupKey, dedup.TTL)
+	//
+	// Accepted webhook deliveries carry an ID and may be processed again after
+	// a restart. Naming their Job after the delivery makes that idempotent.
+	if dedup := operarius.Spec.Deduplication; dedup != nil && dedup.Enabled && dedup.TTL > 0 && !opts.IgnoreDeduplication {
+		receivedAt := hookMessage.ReceivedAt
+		if receivedAt.IsZero() {
+			receivedAt = time.Now()
+		}
+		job.Name = dedupJobNameAt(operarius.Name, dedupKey(hookMessage), dedup.TTL, receivedAt)
+	} else if hookMessage.DeliveryID != "" {
+		job.Name = deliveryJobName(operarius.Name, hookMessage.DeliveryID)
 	} else {
 		job.GenerateName = fmt.Sprintf("%s-", operarius.Name)
 	}
@@ -211,6 +292,25 @@ func (s *OperariusService) CreateJobFromOperarius(ctx context.Context, operarius
 	job.Labels["openfero.io/group-key"] = utils.HashGroupKey(hookMessage.GroupKey)
 	job.Labels["openfero.io/managed-by"] = "openfero"
 	job.Labels["openfero.io/status"] = hookMessage.Status
+	if hookMessage.DeliveryID != "" {
+		job.Labels[DeliveryIDLabel] = hookMessage.DeliveryID
+	}
+	if fingerprint := dedupFingerprint(hookMessage); fingerprint != "" {
+		job.Labels[FingerprintLabel] = fingerprintLabelValue(fingerprint)
+	} else if len(hookMessage.Alerts) > 0 {
+		job.Labels[FingerprintLabel] = fingerprintLabelValue(hookMessage.Alerts[0].StableFingerprint())
+	}
+	if opts.Trigger != "" {
+		job.Labels[TriggerLabel] = opts.Trigger
+	}
+	maps.Copy(job.Annotations, opts.Annotations)
+
+	// Label the pods as well so their failures can be diagnosed via a Pod informer
+	if job.Spec.Template.Labels == nil {
+		job.Spec.Template.Labels = make(map[string]string)
+	}
+	job.Spec.Template.Labels["openfero.io/operarius"] = operarius.Name
+	job.Spec.Template.Labels["openfero.io/managed-by"] = "openfero"
 
 	// Add alert labels as environment variables (OPENFERO_* prefix)
 	// Use first alert if available, otherwise use common labels
@@ -235,16 +335,7 @@ func (s *OperariusService) CreateJobFromOperarius(ctx context.Context, operarius
 		return nil, fmt.Errorf("failed to apply template variables: %w", err)
 	}
 
-	// Create the job in Kubernetes
-	createdJob, err := s.kubeClient.BatchV1().Jobs(job.Namespace).Create(ctx, job, metav1.CreateOptions{})
-	if err != nil {
-		if job.Name != "" && k8serrors.IsAlreadyExists(err) {
-			return nil, ErrJobDeduplicated
-		}
-		return nil, fmt.Errorf("failed to create job: %w", err)
-	}
-
-	return createdJob, nil
+	return job, nil
 }
 
 // dedupJobName derives a deterministic Job name for a given Operarius and
@@ -255,7 +346,14 @@ func (s *OperariusService) CreateJobFromOperarius(ctx context.Context, operarius
 // name can be computed independently by concurrent callers and Kubernetes'
 // name-uniqueness check becomes the actual deduplication guard.
 func dedupJobName(operariusName, groupKey string, ttlSeconds int32) string {
-	window := time.Now().Unix() / int64(ttlSeconds)
+	return dedupJobNameAt(operariusName, groupKey, ttlSeconds, time.Now())
+}
+
+// dedupJobNameAt is dedupJobName for the window containing the given time.
+// Using the time a delivery was received maps a replayed delivery to the
+// same name.
+func dedupJobNameAt(operariusName, groupKey string, ttlSeconds int32, at time.Time) string {
+	window := at.Unix() / int64(ttlSeconds)
 	name := strings.ToLower(fmt.Sprintf("%s-%s-%d", operariusName, utils.HashGroupKey(groupKey), window))
 	if len(name) > 63 {
 		name = name[:63]
@@ -263,6 +361,57 @@ func dedupJobName(operariusName, groupKey string, ttlSeconds int32) string {
 	return strings.TrimRight(name, "-")
 }
 
+// dedupFingerprint returns the fingerprint deduplication is scoped to: the
+// fingerprint Alertmanager sent for the alert of a single-alert payload, or a
+// hash of the sorted fingerprints of all alerts, so the order Alertmanager
+// lists them in doesn't matter. Jobs are then deduplicated per set of alerts
+// within a group. Payloads without fingerprints are deduplicated per group,
+// as before.
+func dedupFingerprint(hookMessage models.HookMessage) string {
+	fingerprints := make([]string, 0, len(hookMessage.Alerts))
+	for _, alert := range hookMessage.Alerts {
+		if alert.Fingerprint != "" {
+			fingerprints = append(fingerprints, alert.Fingerprint)
+		}
+	}
+	switch len(fingerprints) {
+	case 0:
+		return ""
+	case 1:
+		return fingerprints[0]
+	}
+	slices.Sort(fingerprints)
+	return utils.HashGroupKey(strings.Join(slices.Compact(fingerprints), ","))
+}
+
+// fingerprintLabelValue returns a fingerprint usable as label value. Alertmanager
+// fingerprints are hex strings, anything else received from other senders is hashed.
+func fingerprintLabelValue(fingerprint string) string {
+	if len(validation.IsValidLabelValue(fingerprint)) == 0 {
+		return fingerprint
+	}
+	return utils.HashGroupKey(fingerprint)
+}
+
+// dedupKey identifies the alerts a deterministic deduplication Job name covers
+func dedupKey(hookMessage models.HookMessage) string {
+	if fingerprint := dedupFingerprint(hookMessage); fingerprint != "" {
+		return hookMessage.GroupKey + "/" + fingerprint
+	}
+	return hookMessage.GroupKey
+}
+
+// deliveryJobName derives the Job name for a webhook delivery. The Operarius
+// name is shortened if needed so the delivery ID is always kept.
+func deliveryJobName(operariusName, deliveryID string) string {
+	suffix := "-" + strings.ToLower(deliveryID)
+	prefix := strings.ToLower(operariusName)
+	if len(prefix)+len(suffix) > 63 {
+		prefix = strings.TrimRight(prefix[:63-len(suffix)], "-")
+	}
+	return prefix + suffix
+}
+
 // applyTemplateVariables applies Go template variables to the job
 func (s *OperariusService) applyTemplateVariables(job *batchv1.Job, hookMessage models.HookMessage) error {
 	// Template data structure - provide both individual alert and hook message data
@@ -285,6 +434,7 @@ func (s *OperariusService) applyTemplateVariables(job *batchv1.Job, hookMessage
 	// Use first alert if available, otherwise create a synthetic one from common data
 	if len(hookMessage.Alerts) > 0 {
 		templateData.Alert = hookMessage.Alerts[0]
+		templateData.Alert.Fingerprint = hookMessage.Alerts[0].StableFingerprint()
 		templateData.Labels = hookMessage.Alerts[0].Labels
 		templateData.Annotations = hookMessage.Alerts[0].Annotations
 	} else {
@@ -292,6 +442,7 @@ func (s *OperariusService) applyTemplateVariables(job *batchv1.Job, hookMessage
 		templateData.Alert = models.Alert{
 			Labels:      hookMessage.CommonLabels,
 			Annotations: hookMessage.CommonAnnotations,
+			Fingerprint: alertstore.Fingerprint(hookMessage.CommonLabels),
 		}
 		templateData.Labels = hookMessage.CommonLabels
 		templateData.Annotations = hookMessage.CommonAnnotations
@@ -364,7 +515,9 @@ func (s *OperariusService) CheckDeduplication(ctx context.Context, operarius *op
 		return true, nil // No deduplication, always create
 	}
 
-	// Create label selector for finding existing jobs
+	// Create label selector for finding existing jobs. The fingerprint is
+	// checked below, Jobs created before Jobs were labelled with it still
+	// deduplicate the whole group.
 	labelSelector := labels.Set{
 		"openfero.io/operarius": operarius.Name,
 		"openfero.io/group-key": utils.HashGroupKey(hookMessage.GroupKey),
@@ -381,7 +534,11 @@ func (s *OperariusService) CheckDeduplication(ctx context.Context, operarius *op
 	// Time-based check: block if a job was created within the TTL window.
 	// TTL <= 0 means time-based deduplication is disabled.
 	if operarius.Spec.Deduplication.TTL > 0 {
+		fingerprint := dedupFingerprint(hookMessage)
 		for _, job := range jobs.Items {
+			if jobFingerprint, ok := job.Labels[FingerprintLabel]; ok && fingerprint != "" && jobFingerprint != fingerprintLabelValue(fingerprint) {
+				continue // Job for other alerts of the group
+			}
 			if job.CreationTimestamp.Time.Add(time.Duration(operarius.Spec.Deduplication.TTL) * time.Second).After(time.Now()) {
 				return false, nil // Don't create, still within deduplication window
 			}
@@ -391,6 +548,29 @@ func (s *OperariusService) CheckDeduplication(ctx context.Context, operarius *op
 	return true, nil // OK to create
 }
 
+// DeliveryJob returns the Job an Operarius created for a webhook delivery
+// before, or nil if it has none. A delivery is processed again if it was
+// replayed from the queue after a restart.
+func (s *OperariusService) DeliveryJob(ctx context.Context, operarius *operariusv1alpha1.Operarius, deliveryID string) (*batchv1.Job, error) {
+	labelSelector := labels.Set{
+		"openfero.io/operarius": operarius.Name,
+		DeliveryIDLabel:         deliveryID,
+	}.AsSelector()
+
+	jobs, err := s.kubeClient.BatchV1().Jobs(operarius.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: labelSelector.String(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+	for i := range jobs.Items {
+		if jobs.Items[i].Labels[DeliveryIDLabel] == deliveryID {
+			return &jobs.Items[i], nil
+		}
+	}
+	return nil, nil
+}
+
 // GetOperariiForNamespace retrieves all Operarii in a namespace
 func (s *OperariusService) GetOperariiForNamespace(ctx context.Context, namespace string) ([]operariusv1alpha1.Operarius, error) {
 	// Check if we have an Operarius client configured
@@ -445,6 +625,22 @@ func (s *OperariusService) UpdateOperariusDedupStatus(ctx context.Context, opera
 	return nil
 }
 
+// UpdateOperariusScheduleTime records the time the cron trigger of an Operarius was last due
+func (s *OperariusService) UpdateOperariusScheduleTime(ctx context.Context, operarius *operariusv1alpha1.Operarius, scheduled time.Time) error {
+	if s.operariusClient == nil {
+		log.Debug("No Operarius client configured, skipping schedule time update")
+		return nil
+	}
+
+	scheduleTime := metav1.NewTime(scheduled)
+	operarius.Status.LastScheduleTime = &scheduleTime
+
+	if err := s.operariusClient.UpdateStatus(ctx, operarius); err != nil {
+		return fmt.Errorf("failed to update Operarius schedule time: %w", err)
+	}
+	return nil
+}
+
 // UpdateOperariusStatus updates the status of an Operarius after job creation
 func (s *OperariusService) UpdateOperariusStatus(ctx context.Context, operarius *operariusv1alpha1.Operarius, jobName string) error {
 	if s.operariusClient == nil {
@@ -458,6 +654,7 @@ func (s *OperariusService) UpdateOperariusStatus(ctx context.Context, operarius
 	operarius.Status.LastExecutionTime = &now
 	operarius.Status.LastExecutedJobName = jobName
 	operarius.Status.LastExecutionStatus = "Pending"
+	operarius.Status.LastFailure = nil
 
 	// Update via API
 	if err := s.operariusClient.UpdateStatus(ctx, operarius); err != nil {
@@ -566,5 +763,6 @@ func (s *OperariusService) ToJobInfo(op operariusv1alpha1.Operarius) models.JobI
 		LastExecutionTime:   lastExecutionTime,
 		LastExecutedJobName: op.Status.LastExecutedJobName,
 		LastExecutionStatus: op.Status.LastExecutionStatus,
+		LastFailure:         ToAlertStoreJobFailure(op.Status.LastFailure),
 	}
 }
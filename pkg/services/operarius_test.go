@@ -2336,3 +2336,147 @@ func TestCheckDeduplication_JobOutsideTTL(t *testing.T) {
 	assert.NoError(t, err)
 	assert.True(t, shouldCreate, "Should create job when existing job is outside TTL window")
 }
+
+// TestCreateJobFromOperarius_DeliveryIsIdempotent verifies that processing
+// the same webhook delivery again, e.g. when it is replayed from the WAL
+// after a restart, doesn't create a second Job.
+func TestCreateJobFromOperarius_DeliveryIsIdempotent(t *testing.T) {
+	hookMessage := models.HookMessage{
+		Status:     "firing",
+		GroupKey:   "test-group",
+		DeliveryID: "0123456789abcdef",
+		ReceivedAt: time.Now().Add(-time.Hour),
+		Alerts: []models.Alert{
+			{Labels: map[string]string{"alertname": "TestAlert"}},
+		},
+	}
+
+	t.Run("without deduplication", func(t *testing.T) {
+		service := NewOperariusService(fake.NewSimpleClientset())
+		operarius := dedupOperariusFixture(nil)
+
+		job, err := service.CreateJobFromOperarius(context.TODO(), operarius, hookMessage)
+		require.NoError(t, err)
+		assert.Equal(t, "dedup-operarius-0123456789abcdef", job.Name)
+		assert.Equal(t, "0123456789abcdef", job.Labels[DeliveryIDLabel])
+
+		_, err = service.CreateJobFromOperarius(context.TODO(), operarius, hookMessage)
+		assert.ErrorIs(t, err, ErrJobDeduplicated)
+	})
+
+	t.Run("deduplication window of the original delivery", func(t *testing.T) {
+		service := NewOperariusService(fake.NewSimpleClientset())
+		operarius := dedupOperariusFixture(&operariusv1alpha1.DeduplicationConfig{Enabled: true, TTL: 60})
+
+		job, err := service.CreateJobFromOperarius(context.TODO(), operarius, hookMessage)
+		require.NoError(t, err)
+		assert.Equal(t, dedupJobNameAt(operarius.Name, hookMessage.GroupKey, 60, hookMessage.ReceivedAt), job.Name)
+	})
+
+	t.Run("long operarius name keeps the delivery ID", func(t *testing.T) {
+		name := deliveryJobName(strings.Repeat("a", 100), hookMessage.DeliveryID)
+		assert.LessOrEqual(t, len(name), 63)
+		assert.True(t, strings.HasSuffix(name, "-0123456789abcdef"))
+	})
+}
+
+// TestCreateJobFromOperarius_DeduplicatedPerFingerprint verifies that alerts
+// of the same group are deduplicated separately when Alertmanager sends
+// fingerprints, and that the fingerprint is available to templates.
+func TestCreateJobFromOperarius_DeduplicatedPerFingerprint(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset()
+	service := NewOperariusService(kubeClient)
+
+	operarius := dedupOperariusFixture(&operariusv1alpha1.DeduplicationConfig{Enabled: true, TTL: 1_000_000_000})
+	operarius.Spec.JobTemplate.Spec.Template.Spec.Containers[0].Args = []string{"{{ .Alert.Fingerprint }}"}
+	ctx := context.TODO()
+
+	hookMessage := func(fingerprint string) models.HookMessage {
+		return models.HookMessage{
+			Status:   "firing",
+			GroupKey: "test-group",
+			Alerts: []models.Alert{
+				{Labels: map[string]string{"alertname": "TestAlert"}, Fingerprint: fingerprint},
+			},
+		}
+	}
+
+	job, err := service.CreateJobFromOperarius(ctx, operarius, hookMessage("1a2b3c4d5e6f7a8b"))
+	require.NoError(t, err)
+	assert.Equal(t, "1a2b3c4d5e6f7a8b", job.Labels[FingerprintLabel])
+	assert.Equal(t, []string{"1a2b3c4d5e6f7a8b"}, job.Spec.Template.Spec.Containers[0].Args)
+
+	_, err = service.CreateJobFromOperarius(ctx, operarius, hookMessage("1a2b3c4d5e6f7a8b"))
+	assert.ErrorIs(t, err, ErrJobDeduplicated, "the same alert must be deduplicated")
+
+	_, err = service.CreateJobFromOperarius(ctx, operarius, hookMessage("8b7a6f5e4d3c2b1a"))
+	require.NoError(t, err, "another alert of the same group must get its own Job")
+
+	jobs, err := kubeClient.BatchV1().Jobs(operarius.Namespace).List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	assert.Len(t, jobs.Items, 2)
+}
+
+// TestCheckDeduplication_UnlabelledJob verifies that a Job created before Jobs
+// were labelled with the alert fingerprint still deduplicates its group.
+func TestCheckDeduplication_UnlabelledJob(t *testing.T) {
+	operarius := dedupOperariusFixture(&operariusv1alpha1.DeduplicationConfig{Enabled: true, TTL: 300})
+	legacyJob := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "legacy-job",
+			Namespace: operarius.Namespace,
+			Labels: map[string]string{
+				"openfero.io/operarius": operarius.Name,
+				"openfero.io/group-key": utils.HashGroupKey("test-group"),
+			},
+			CreationTimestamp: metav1.NewTime(time.Now()),
+		},
+	}
+	service := NewOperariusService(fake.NewSimpleClientset(legacyJob))
+
+	shouldCreate, err := service.CheckDeduplication(context.TODO(), operarius, models.HookMessage{
+		GroupKey: "test-group",
+		Alerts:   []models.Alert{{Labels: map[string]string{"alertname": "TestAlert"}, Fingerprint: "1a2b3c4d5e6f7a8b"}},
+	})
+	require.NoError(t, err)
+	assert.False(t, shouldCreate, "the unlabelled Job must deduplicate the group")
+}
+
+// TestCreateJobFromOperarius_DeduplicatedRegardlessOfAlertOrder verifies that
+// a group is deduplicated when Alertmanager lists its alerts in another order.
+func TestCreateJobFromOperarius_DeduplicatedRegardlessOfAlertOrder(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset()
+	service := NewOperariusService(kubeClient)
+	operarius := dedupOperariusFixture(&operariusv1alpha1.DeduplicationConfig{Enabled: true, TTL: 300})
+	ctx := context.TODO()
+
+	first := models.Alert{Labels: map[string]string{"alertname": "TestAlert", "instance": "a"}, Fingerprint: "1a2b3c4d5e6f7a8b"}
+	second := models.Alert{Labels: map[string]string{"alertname": "TestAlert", "instance": "b"}, Fingerprint: "8b7a6f5e4d3c2b1a"}
+	hookMessage := func(alerts ...models.Alert) models.HookMessage {
+		return models.HookMessage{Status: "firing", GroupKey: "test-group", Alerts: alerts}
+	}
+
+	_, err := service.CreateJobFromOperarius(ctx, operarius, hookMessage(first, second))
+	require.NoError(t, err)
+
+	_, err = service.CreateJobFromOperarius(ctx, operarius, hookMessage(second, first))
+	assert.ErrorIs(t, err, ErrJobDeduplicated, "the same alerts in another order must be deduplicated")
+
+	_, err = service.CreateJobFromOperarius(ctx, operarius, hookMessage(second))
+	assert.NoError(t, err, "a different set of alerts must get its own Job")
+}
+
+// TestAlert_StableFingerprint verifies the fingerprint computed for alerts
+// received without one only depends on the label set.
+func TestAlert_StableFingerprint(t *testing.T) {
+	a := models.Alert{Labels: map[string]string{"alertname": "TestAlert", "pod": "a"}}
+	b := models.Alert{Labels: map[string]string{"pod": "a", "alertname": "TestAlert"}}
+	c := models.Alert{Labels: map[string]string{"alertname": "TestAlert", "pod": "b"}}
+
+	assert.Len(t, a.StableFingerprint(), 16)
+	assert.Equal(t, a.StableFingerprint(), b.StableFingerprint())
+	assert.NotEqual(t, a.StableFingerprint(), c.StableFingerprint())
+
+	a.Fingerprint = "given"
+	assert.Equal(t, "given", a.StableFingerprint(), "a fingerprint sent by Alertmanager is kept")
+}
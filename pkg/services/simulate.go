@@ -0,0 +1,105 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	batchv1 "k8s.io/api/batch/v1"
+	"sigs.k8s.io/yaml"
+
+	operariusv1alpha1 "github.com/OpenFero/openfero/api/v1alpha1"
+	"github.com/OpenFero/openfero/pkg/models"
+)
+
+// SimulationCandidate describes how a single Operarius would handle a hook message
+type SimulationCandidate struct {
+	Operarius string `json:"operarius"`
+	Namespace string `json:"namespace"`
+	Priority  int32  `json:"priority"`
+	// Matched tells whether the Operarius' alert selector matches, Reason explains why
+	Matched bool   `json:"matched"`
+	Reason  string `json:"reason"`
+	// Selected is set on the highest priority match, the Operarius that would run
+	Selected bool `json:"selected"`
+	// Deduplicated is set if deduplication would currently skip the Job
+	Deduplicated       bool   `json:"deduplicated,omitempty"`
+	DeduplicationError string `json:"deduplicationError,omitempty"`
+	// JobYAML is the fully rendered Job the Operarius would create
+	JobYAML     string `json:"jobYaml,omitempty"`
+	RenderError string `json:"renderError,omitempty"`
+}
+
+// SimulationResult describes how OpenFero would handle a hook message
+type SimulationResult struct {
+	// Selected names the Operarius that would run, if any
+	Selected string `json:"selected,omitempty"`
+	// WouldCreateJob is set if a Job would be created right now
+	WouldCreateJob bool                  `json:"wouldCreateJob"`
+	Candidates     []SimulationCandidate `json:"candidates"`
+}
+
+// Simulate evaluates a hook message against all Operarii the way an incoming
+// webhook would be, without creating Jobs or updating any status
+func (s *OperariusService) Simulate(ctx context.Context, hookMessage models.HookMessage) (*SimulationResult, error) {
+	operarii, err := s.GetOperariiForNamespace(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SimulationResult{Candidates: make([]SimulationCandidate, 0, len(operarii))}
+
+	var selected *operariusv1alpha1.Operarius
+	if best, err := s.FindMatchingOperarius(hookMessage, operarii); err == nil {
+		selected = best
+		result.Selected = best.Name
+	}
+
+	for i := range operarii {
+		operarius := &operarii[i]
+		matched, reason := explainMatch(*operarius, hookMessage)
+		candidate := SimulationCandidate{
+			Operarius: operarius.Name,
+			Namespace: operarius.Namespace,
+			Priority:  operarius.Spec.Priority,
+			Matched:   matched,
+			Reason:    reason,
+			Selected:  selected != nil && selected.Name == operarius.Name && selected.Namespace == operarius.Namespace,
+		}
+
+		if matched {
+			shouldCreate, err := s.CheckDeduplication(ctx, operarius, hookMessage)
+			if err != nil {
+				candidate.DeduplicationError = err.Error()
+			} else {
+				candidate.Deduplicated = !shouldCreate
+			}
+		}
+
+		job, err := s.BuildJobFromOperarius(operarius, hookMessage, JobOptions{})
+		if err != nil {
+			candidate.RenderError = err.Error()
+		} else if candidate.JobYAML, err = jobYAML(job); err != nil {
+			candidate.RenderError = err.Error()
+		}
+
+		if candidate.Selected {
+			result.WouldCreateJob = !candidate.Deduplicated && candidate.DeduplicationError == "" && candidate.RenderError == ""
+		}
+		result.Candidates = append(result.Candidates, candidate)
+	}
+
+	return result, nil
+}
+
+// jobYAML renders a Job as a complete Kubernetes manifest
+func jobYAML(job *batchv1.Job) (string, error) {
+	manifest := job.DeepCopy()
+	manifest.APIVersion = batchv1.SchemeGroupVersion.String()
+	manifest.Kind = "Job"
+
+	data, err := yaml.Marshal(manifest)
+	if err != nil {
+		return "", fmt.Errorf("failed to render job YAML: %w", err)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/yaml"
+
+	operariusv1alpha1 "github.com/OpenFero/openfero/api/v1alpha1"
+	"github.com/OpenFero/openfero/pkg/models"
+	"github.com/OpenFero/openfero/pkg/utils"
+)
+
+func simulationOperarius(name string, priority int32, selectorLabels map[string]string, args ...string) operariusv1alpha1.Operarius {
+	enabled := true
+	return operariusv1alpha1.Operarius{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "openfero"},
+		Spec: operariusv1alpha1.OperariusSpec{
+			AlertSelector: operariusv1alpha1.AlertSelector{
+				AlertName: "HighMemory",
+				Status:    "firing",
+				Labels:    selectorLabels,
+			},
+			Priority: priority,
+			Enabled:  &enabled,
+			JobTemplate: batchv1.JobTemplateSpec{
+				Spec: batchv1.JobSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							RestartPolicy: corev1.RestartPolicyNever,
+							Containers:    []corev1.Container{{Name: "remediation", Image: "busybox", Args: args}},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestSimulate(t *testing.T) {
+	highPriority := simulationOperarius("restart-pod", 10, map[string]string{"namespace": "shop"}, "restart {{ .Labels.pod }}")
+	lowPriority := simulationOperarius("notify", 1, nil, "notify")
+	wrongLabel := simulationOperarius("other-namespace", 20, map[string]string{"namespace": "billing"})
+	brokenTemplate := simulationOperarius("broken", 0, nil, "{{ .Labels.missing }}")
+	disabled := simulationOperarius("disabled", 30, nil)
+	enabled := false
+	disabled.Spec.Enabled = &enabled
+
+	kubeClient := fake.NewSimpleClientset()
+	service := NewOperariusServiceWithClient(kubeClient, &MockOperariusClient{
+		operarii: []operariusv1alpha1.Operarius{highPriority, lowPriority, wrongLabel, brokenTemplate, disabled},
+	})
+
+	hookMessage := models.HookMessage{
+		Status:   "firing",
+		GroupKey: "group-1",
+		Alerts: []models.Alert{{
+			Labels: map[string]string{"alertname": "HighMemory", "namespace": "shop", "pod": "web-0"},
+		}},
+	}
+
+	result, err := service.Simulate(context.Background(), hookMessage)
+	require.NoError(t, err)
+	assert.Equal(t, "restart-pod", result.Selected)
+	assert.True(t, result.WouldCreateJob)
+	require.Len(t, result.Candidates, 5)
+
+	candidates := make(map[string]SimulationCandidate)
+	for _, candidate := range result.Candidates {
+		candidates[candidate.Operarius] = candidate
+	}
+
+	selected := candidates["restart-pod"]
+	assert.True(t, selected.Matched)
+	assert.True(t, selected.Selected)
+	assert.Empty(t, selected.RenderError)
+	var job batchv1.Job
+	require.NoError(t, yaml.Unmarshal([]byte(selected.JobYAML), &job))
+	assert.Equal(t, "Job", job.Kind)
+	assert.Equal(t, "restart-pod-", job.GenerateName)
+	assert.Equal(t, []string{"restart web-0"}, job.Spec.Template.Spec.Containers[0].Args)
+
+	assert.True(t, candidates["notify"].Matched)
+	assert.False(t, candidates["notify"].Selected)
+
+	assert.False(t, candidates["other-namespace"].Matched)
+	assert.Equal(t, `label "namespace" is "shop", selector requires "billing"`, candidates["other-namespace"].Reason)
+
+	assert.True(t, candidates["broken"].Matched)
+	assert.NotEmpty(t, candidates["broken"].RenderError)
+	assert.Empty(t, candidates["broken"].JobYAML)
+
+	assert.False(t, candidates["disabled"].Matched)
+	assert.Equal(t, "operarius is disabled", candidates["disabled"].Reason)
+
+	jobs, err := kubeClient.BatchV1().Jobs("openfero").List(context.Background(), metav1.ListOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, jobs.Items, "simulation must not create jobs")
+}
+
+func TestSimulate_Deduplicated(t *testing.T) {
+	operarius := simulationOperarius("restart-pod", 10, nil)
+	operarius.Spec.Deduplication = &operariusv1alpha1.DeduplicationConfig{Enabled: true, TTL: 300}
+
+	existing := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{
+		Name:              "restart-pod-existing",
+		Namespace:         "openfero",
+		CreationTimestamp: metav1.NewTime(time.Now()),
+		Labels: map[string]string{
+			"openfero.io/operarius": "restart-pod",
+			"openfero.io/group-key": utils.HashGroupKey("group-1"),
+		},
+	}}
+	service := NewOperariusServiceWithClient(fake.NewSimpleClientset(existing), &MockOperariusClient{
+		operarii: []operariusv1alpha1.Operarius{operarius},
+	})
+
+	result, err := service.Simulate(context.Background(), models.HookMessage{
+		Status:   "firing",
+		GroupKey: "group-1",
+		Alerts:   []models.Alert{{Labels: map[string]string{"alertname": "HighMemory"}}},
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Candidates, 1)
+	assert.True(t, result.Candidates[0].Deduplicated)
+	assert.False(t, result.WouldCreateJob)
+}
@@ -0,0 +1,227 @@
+// Package tlsconfig serves TLS with certificates that are reloaded from disk
+// when they change, e.g. when cert-manager renews them.
+package tlsconfig
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/OpenFero/openfero/pkg/logging"
+	"github.com/OpenFero/openfero/pkg/metadata"
+)
+
+// DefaultReloadInterval is how often the certificate files are checked for changes
+const DefaultReloadInterval = 30 * time.Second
+
+// Config configures TLS serving
+type Config struct {
+	// CertFile and KeyFile hold the PEM encoded server certificate chain and key
+	CertFile string
+	KeyFile  string
+	// ClientCAFile holds the CA bundle client certificates are verified
+	// against. Clients may connect without a certificate, routes that require
+	// one check it themselves.
+	ClientCAFile string
+	// MinVersion is the minimum TLS version, "1.2" or "1.3"
+	MinVersion string
+	// CipherSuites restricts the cipher suites of TLS 1.2 by their IANA
+	// names. TLS 1.3 suites are not configurable.
+	CipherSuites []string
+}
+
+// ParseVersion parses a TLS version like "1.2"
+func ParseVersion(version string) (uint16, error) {
+	switch strings.TrimPrefix(strings.ToLower(version), "tls") {
+	case "1.2", "", "12":
+		return tls.VersionTLS12, nil
+	case "1.3", "13":
+		return tls.VersionTLS13, nil
+	}
+	return 0, fmt.Errorf("unsupported TLS version %q, expected 1.2 or 1.3", version)
+}
+
+// ParseCipherSuites parses cipher suite names like
+// TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256. Suites Go considers insecure are rejected.
+func ParseCipherSuites(names []string) ([]uint16, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	secure := make(map[string]uint16)
+	for _, suite := range tls.CipherSuites() {
+		secure[suite.Name] = suite.ID
+	}
+	insecure := make(map[string]bool)
+	for _, suite := range tls.InsecureCipherSuites() {
+		insecure[suite.Name] = true
+	}
+
+	ids := make([]uint16, 0, len(names))
+	for _, name := range names {
+		id, ok := secure[name]
+		switch {
+		case ok:
+			ids = append(ids, id)
+		case insecure[name]:
+			return nil, fmt.Errorf("cipher suite %s is insecure", name)
+		default:
+			return nil, fmt.Errorf("unknown cipher suite %s", name)
+		}
+	}
+	return ids, nil
+}
+
+// Reloader holds the server certificate and the client CAs, reloading them
+// when their files change. Connections in progress keep the certificate
+// they were established with.
+type Reloader struct {
+	config       Config
+	minVersion   uint16
+	cipherSuites []uint16
+
+	mu          sync.RWMutex
+	certificate *tls.Certificate
+	clientCAs   *x509.CertPool
+	loaded      [][]byte // contents of the files the current state was loaded from
+}
+
+// NewReloader validates the configuration and loads the certificates
+func NewReloader(config Config) (*Reloader, error) {
+	if config.CertFile == "" || config.KeyFile == "" {
+		return nil, fmt.Errorf("TLS requires both a certificate and a key file")
+	}
+	minVersion, err := ParseVersion(config.MinVersion)
+	if err != nil {
+		return nil, err
+	}
+	cipherSuites, err := ParseCipherSuites(config.CipherSuites)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Reloader{config: config, minVersion: minVersion, cipherSuites: cipherSuites}
+	if _, err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload reads the files again and reports whether they changed. If they
+// are invalid, e.g. because they are replaced one after the other, the
+// previous certificates stay in use.
+func (r *Reloader) Reload() (bool, error) {
+	files := []string{r.config.CertFile, r.config.KeyFile}
+	if r.config.ClientCAFile != "" {
+		files = append(files, r.config.ClientCAFile)
+	}
+	contents := make([][]byte, len(files))
+	for i, file := range files {
+		data, err := os.ReadFile(file) // #nosec G304 -- path is configured by the operator
+		if err != nil {
+			return false, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		contents[i] = data
+	}
+
+	r.mu.RLock()
+	unchanged := r.loaded != nil && equalContents(r.loaded, contents)
+	r.mu.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	certificate, err := tls.X509KeyPair(contents[0], contents[1])
+	if err != nil {
+		return false, fmt.Errorf("invalid certificate or key: %w", err)
+	}
+	var clientCAs *x509.CertPool
+	if r.config.ClientCAFile != "" {
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(contents[2]) {
+			return false, fmt.Errorf("no certificates found in client CA file %s", r.config.ClientCAFile)
+		}
+	}
+	if certificate.Leaf != nil {
+		metadata.TLSCertificateExpiryTimestamp.Set(float64(certificate.Leaf.NotAfter.Unix()))
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.certificate = &certificate
+	r.clientCAs = clientCAs
+	r.loaded = contents
+	return true, nil
+}
+
+// Watch reloads the files every interval until the context is canceled
+func (r *Reloader) Watch(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultReloadInterval
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			changed, err := r.Reload()
+			if err != nil {
+				metadata.TLSReloadsTotal.WithLabelValues("error").Inc()
+				log.Error("Failed to reload TLS certificates, keeping the previous ones", "error", err)
+				continue
+			}
+			if changed {
+				metadata.TLSReloadsTotal.WithLabelValues("success").Inc()
+				log.Info("Reloaded TLS certificates", "certFile", r.config.CertFile)
+			}
+		}
+	}()
+}
+
+// ServerConfig returns the TLS configuration of the server. Certificates and
+// client CAs are looked up per connection, so reloads apply to new
+// connections right away.
+func (r *Reloader) ServerConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion:   r.minVersion,
+		CipherSuites: r.cipherSuites,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			r.mu.RLock()
+			defer r.mu.RUnlock()
+			config := &tls.Config{
+				MinVersion:   r.minVersion,
+				CipherSuites: r.cipherSuites,
+				Certificates: []tls.Certificate{*r.certificate},
+				NextProtos:   []string{"h2", "http/1.1"},
+			}
+			if r.clientCAs != nil {
+				config.ClientAuth = tls.VerifyClientCertIfGiven
+				config.ClientCAs = r.clientCAs
+			}
+			return config, nil
+		},
+	}
+}
+
+// equalContents reports whether the files were unchanged
+func equalContents(a, b [][]byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !bytes.Equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
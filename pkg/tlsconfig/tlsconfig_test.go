@@ -0,0 +1,219 @@
+package tlsconfig
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testCA issues certificates for tests
+type testCA struct {
+	cert   *x509.Certificate
+	key    *ecdsa.PrivateKey
+	pem    []byte
+	serial int64
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), serial: 1}
+}
+
+// issue returns a PEM certificate and key for a server or client
+func (ca *testCA) issue(t *testing.T, commonName string, usage x509.ExtKeyUsage) ([]byte, []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	ca.serial++
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(ca.serial),
+		Subject:      pkix.Name{CommonName: commonName},
+		DNSNames:     []string{commonName},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func writeFile(t *testing.T, path string, data []byte) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+}
+
+// serve serves the reloader's TLS config and answers with the common name
+// of the verified client certificate, if any
+func serve(t *testing.T, reloader *Reloader) string {
+	t.Helper()
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", reloader.ServerConfig())
+	require.NoError(t, err)
+	server := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if len(r.TLS.VerifiedChains) > 0 {
+				_, _ = fmt.Fprint(w, r.TLS.VerifiedChains[0][0].Subject.CommonName)
+			}
+		}),
+		ReadHeaderTimeout: time.Second,
+	}
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(func() { _ = server.Close() })
+	return "https://" + listener.Addr().String()
+}
+
+// client connects with a fresh connection per request, trusting ca
+func client(ca *testCA, certificates ...tls.Certificate) *http.Client {
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+	return &http.Client{Transport: &http.Transport{
+		DisableKeepAlives: true,
+		TLSClientConfig:   &tls.Config{RootCAs: pool, Certificates: certificates, MinVersion: tls.VersionTLS12},
+	}}
+}
+
+func TestReloader_RotatesCertificate(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t)
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	cert, key := ca.issue(t, "openfero-1", x509.ExtKeyUsageServerAuth)
+	writeFile(t, certFile, cert)
+	writeFile(t, keyFile, key)
+
+	reloader, err := NewReloader(Config{CertFile: certFile, KeyFile: keyFile})
+	require.NoError(t, err)
+	url := serve(t, reloader)
+
+	servedName := func() string {
+		t.Helper()
+		resp, err := client(ca).Get(url)
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+		return resp.TLS.PeerCertificates[0].Subject.CommonName
+	}
+	assert.Equal(t, "openfero-1", servedName())
+
+	changed, err := reloader.Reload()
+	require.NoError(t, err)
+	assert.False(t, changed, "unchanged files are not reloaded")
+
+	cert, key = ca.issue(t, "openfero-2", x509.ExtKeyUsageServerAuth)
+	writeFile(t, certFile, cert)
+	writeFile(t, keyFile, key)
+	changed, err = reloader.Reload()
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, "openfero-2", servedName())
+
+	// A certificate written without its key yet keeps the previous pair
+	cert, _ = ca.issue(t, "openfero-3", x509.ExtKeyUsageServerAuth)
+	writeFile(t, certFile, cert)
+	_, err = reloader.Reload()
+	assert.Error(t, err)
+	assert.Equal(t, "openfero-2", servedName())
+}
+
+func TestReloader_ClientCertificates(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t)
+	certFile, keyFile, caFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key"), filepath.Join(dir, "ca.crt")
+	cert, key := ca.issue(t, "openfero", x509.ExtKeyUsageServerAuth)
+	writeFile(t, certFile, cert)
+	writeFile(t, keyFile, key)
+	writeFile(t, caFile, ca.pem)
+
+	reloader, err := NewReloader(Config{CertFile: certFile, KeyFile: keyFile, ClientCAFile: caFile, MinVersion: "1.3"})
+	require.NoError(t, err)
+	url := serve(t, reloader)
+
+	get := func(c *http.Client) (string, error) {
+		resp, err := c.Get(url)
+		if err != nil {
+			return "", err
+		}
+		defer func() { _ = resp.Body.Close() }()
+		body := make([]byte, 64)
+		n, _ := resp.Body.Read(body)
+		return string(body[:n]), nil
+	}
+
+	clientCert, clientKey := ca.issue(t, "alertmanager", x509.ExtKeyUsageClientAuth)
+	pair, err := tls.X509KeyPair(clientCert, clientKey)
+	require.NoError(t, err)
+	name, err := get(client(ca, pair))
+	require.NoError(t, err)
+	assert.Equal(t, "alertmanager", name)
+
+	name, err = get(client(ca))
+	require.NoError(t, err, "clients without a certificate may connect, routes decide whether they need one")
+	assert.Empty(t, name)
+
+	other := newTestCA(t)
+	otherCert, otherKey := other.issue(t, "intruder", x509.ExtKeyUsageClientAuth)
+	pair, err = tls.X509KeyPair(otherCert, otherKey)
+	require.NoError(t, err)
+	_, err = get(client(ca, pair))
+	assert.Error(t, err, "certificates of other CAs are rejected")
+
+	// TLS 1.2 is below the minimum version
+	old := client(ca)
+	old.Transport.(*http.Transport).TLSClientConfig.MaxVersion = tls.VersionTLS12
+	_, err = get(old)
+	assert.Error(t, err)
+}
+
+func TestParseConfig(t *testing.T) {
+	version, err := ParseVersion("1.3")
+	require.NoError(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS13), version)
+	_, err = ParseVersion("1.1")
+	assert.Error(t, err)
+
+	suites, err := ParseCipherSuites([]string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256", "TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256"})
+	require.NoError(t, err)
+	assert.Equal(t, []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256, tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256}, suites)
+	_, err = ParseCipherSuites([]string{"TLS_RSA_WITH_RC4_128_SHA"})
+	assert.ErrorContains(t, err, "insecure")
+	_, err = ParseCipherSuites([]string{"TLS_MADE_UP"})
+	assert.ErrorContains(t, err, "unknown")
+
+	_, err = NewReloader(Config{CertFile: "/does/not/exist", KeyFile: "/does/not/exist"})
+	assert.Error(t, err)
+	_, err = NewReloader(Config{CertFile: "tls.crt"})
+	assert.Error(t, err)
+}